@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/majiayu000/caude-skill-manager/internal/audit"
+	"github.com/majiayu000/caude-skill-manager/internal/config"
+	"github.com/majiayu000/caude-skill-manager/internal/github"
+	"github.com/majiayu000/caude-skill-manager/internal/skill"
+	"github.com/majiayu000/caude-skill-manager/internal/ui"
+	"github.com/majiayu000/caude-skill-manager/pkg/styles"
+	"github.com/spf13/cobra"
+)
+
+var auditFailOn string // severity that makes the command exit non-zero
+
+var auditCmd = &cobra.Command{
+	Use:   "audit [skill-name|source]",
+	Short: "Scan skills for risky content",
+	Long: `Scan skill files for risky patterns before Claude runs them.
+
+The audit looks for remote scripts piped into a shell, decoded payloads that
+are executed, credential file reads, network exfiltration, obfuscated code,
+bundled executables and SKILL.md allowed-tools that grant unrestricted Bash.
+
+Without arguments every installed skill is audited. A name that is not
+installed is resolved like 'sk install' and downloaded to a temporary
+directory, so a skill can be audited before it is installed.`,
+	Example: `  sk audit
+  sk audit docx
+  sk audit anthropics/skills/pdf --fail-on medium`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		threshold, err := auditThreshold(auditFailOn)
+		if err != nil {
+			fmt.Println(styles.RenderError(err.Error()))
+			os.Exit(1)
+		}
+
+		fmt.Println()
+		fmt.Println(styles.TitleStyle.Render(styles.IconSearch + " Skill Audit"))
+
+		blocked := false
+		if len(args) == 0 {
+			skills, err := skill.List()
+			if err != nil {
+				fmt.Println(styles.RenderError("Failed to list skills: " + err.Error()))
+				os.Exit(1)
+			}
+			if len(skills) == 0 {
+				fmt.Println(styles.MutedStyle.Render("No skills installed."))
+				return
+			}
+			for _, s := range skills {
+				report, err := audit.ScanDir(s.Path)
+				if err != nil {
+					fmt.Println(styles.RenderError(fmt.Sprintf("Failed to audit %s: %s", s.Name, err.Error())))
+					blocked = true
+					continue
+				}
+				printAuditReport(s.Name, report)
+				blocked = blocked || report.Blocks(threshold)
+			}
+		} else {
+			name, report, err := auditTarget(args[0])
+			if err != nil {
+				printSourceError(err)
+				os.Exit(1)
+			}
+			printAuditReport(name, report)
+			blocked = report.Blocks(threshold)
+		}
+
+		fmt.Println()
+		if blocked {
+			fmt.Println(styles.RenderError(fmt.Sprintf("Findings at or above '%s' severity.", threshold)))
+			fmt.Println()
+			os.Exit(1)
+		}
+	},
+}
+
+// auditTarget audits an installed skill, or downloads the source to a
+// temporary directory and audits it there.
+func auditTarget(target string) (string, *audit.Report, error) {
+	s, err := skill.Get(target)
+	if err != nil {
+		return "", nil, err
+	}
+	if s != nil {
+		report, err := audit.ScanDir(s.Path)
+		return s.Name, report, err
+	}
+
+	resolved, err := resolveSource(target)
+	if err != nil {
+		return "", nil, err
+	}
+
+	tmpDir, err := os.MkdirTemp("", "sk-audit-*")
+	if err != nil {
+		return "", nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	err = ui.RunWithSpinner("Downloading for audit...", func() (string, error) {
+		if err := github.DownloadAndExtractTo(resolved.Info, tmpDir); err != nil {
+			return "", err
+		}
+		return styles.RenderSuccess("Downloaded " + resolved.Info.FullURL), nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	report, err := audit.ScanDir(tmpDir)
+	return github.GetSkillName(resolved.Info), report, err
+}
+
+// auditThreshold parses a severity flag, falling back to the configured
+// install block severity.
+func auditThreshold(flag string) (audit.Severity, error) {
+	if flag == "" {
+		flag = config.GetAuditBlockSeverity()
+	}
+	threshold, err := audit.ParseSeverity(flag)
+	if err != nil {
+		return 0, fmt.Errorf("invalid audit severity: %w", err)
+	}
+	return threshold, nil
+}
+
+func printAuditReport(name string, report *audit.Report) {
+	fmt.Println()
+	if len(report.Findings) == 0 {
+		fmt.Printf("  %s %s %s\n",
+			styles.SuccessStyle.Render(styles.IconCheck),
+			styles.SkillNameStyle.Render(name),
+			styles.MutedStyle.Render(fmt.Sprintf("no findings in %d file(s)", report.FilesScanned)),
+		)
+		return
+	}
+
+	var counts []string
+	for sev := audit.SeverityCritical; sev >= audit.SeverityInfo; sev-- {
+		if n := report.Count(sev); n > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", n, sev))
+		}
+	}
+	fmt.Printf("  %s %s %s\n",
+		severityStyle(report.Max()).Render(styles.IconWarning),
+		styles.SkillNameStyle.Render(name),
+		styles.MutedStyle.Render(strings.Join(counts, ", ")),
+	)
+
+	for _, f := range report.Findings {
+		location := f.File
+		if f.Line > 0 {
+			location = fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+		fmt.Printf("    %s %s %s\n",
+			severityStyle(f.Severity).Render(fmt.Sprintf("%-8s", strings.ToUpper(f.Severity.String()))),
+			location,
+			styles.MutedStyle.Render("["+f.Rule+"]"),
+		)
+		fmt.Printf("      %s\n", f.Message)
+		if f.Excerpt != "" {
+			fmt.Printf("      %s\n", styles.MutedStyle.Render(f.Excerpt))
+		}
+	}
+}
+
+func severityStyle(sev audit.Severity) lipgloss.Style {
+	switch {
+	case sev >= audit.SeverityHigh:
+		return styles.ErrorStyle
+	case sev >= audit.SeverityMedium:
+		return styles.WarningStyle
+	default:
+		return styles.MutedStyle
+	}
+}
+
+func init() {
+	auditCmd.Flags().StringVar(&auditFailOn, "fail-on", "", "Exit non-zero on findings at or above this severity (default: audit_block_severity from config)")
+	rootCmd.AddCommand(auditCmd)
+}
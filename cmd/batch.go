@@ -0,0 +1,303 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/majiayu000/caude-skill-manager/internal/audit"
+	"github.com/majiayu000/caude-skill-manager/internal/github"
+	"github.com/majiayu000/caude-skill-manager/internal/skill"
+	"github.com/majiayu000/caude-skill-manager/internal/ui"
+	"github.com/majiayu000/caude-skill-manager/pkg/styles"
+)
+
+// batchItem is one source of a batch install.
+type batchItem struct {
+	Source   string
+	Resolved *resolvedSource
+	Name     string
+	Err      error
+	Skipped  string // why the source was not installed, if it was skipped
+	Detail   string // note shown after a successful install
+}
+
+// readSourcesFile reads install sources from path, or stdin when path is
+// "-": one per line, ignoring blank lines and lines starting with #.
+func readSourcesFile(path string) ([]string, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var sources []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		sources = append(sources, line)
+	}
+	return sources, scanner.Err()
+}
+
+// resolveBatch resolves every source, checks it against the trust policy and
+// picks its skill name, or uses name when it is set. Sources that fail are
+// marked, and so are later sources that would install under a name already
+// taken in the batch.
+func resolveBatch(sources []string, name string) []*batchItem {
+	items := make([]*batchItem, len(sources))
+	names := make(map[string]string)
+	for i, source := range sources {
+		item := &batchItem{Source: source, Name: source}
+		items[i] = item
+
+		resolved, err := resolveSource(source)
+		if err != nil {
+			item.Err = err
+			continue
+		}
+		item.Resolved = resolved
+		item.Name = name
+		if item.Name == "" {
+			item.Name = github.GetSkillName(resolved.Info)
+		}
+		if err := checkPolicy(resolved); err != nil {
+			item.Err = err
+			continue
+		}
+		if first, ok := names[item.Name]; ok {
+			item.Err = fmt.Errorf("installs as '%s', like %s", item.Name, first)
+			continue
+		}
+		names[item.Name] = source
+		// Other conflicts are found once the skill is downloaded; an
+		// existing install needs no download to be skipped.
+		if skill.Exists(item.Name) && !installForce && (installConflict == "" || installConflict == conflictSkip) {
+			item.Skipped = "already installed"
+		}
+	}
+	return items
+}
+
+// archiveKey identifies the repository archive a source downloads.
+func archiveKey(info *github.RepoInfo) string {
+	ref := info.Branch
+	if info.Commit != "" {
+		ref = info.Commit
+	}
+	return downloadKey(info.Owner+"/"+info.Repo, ref)
+}
+
+// downloadKey identifies an archive download as reported by github.
+func downloadKey(repo, ref string) string {
+	return strings.ToLower(repo) + "@" + ref
+}
+
+// groupBatch returns the positions of the items to install, grouped by the
+// repository archive they download so each group shares one download.
+// Groups and their items keep the order of the sources.
+func groupBatch(items []*batchItem) [][]int {
+	var groups [][]int
+	index := make(map[string]int)
+	for i, item := range items {
+		if item.Err != nil || item.Skipped != "" {
+			continue
+		}
+		key := archiveKey(item.Resolved.Info)
+		g, ok := index[key]
+		if !ok {
+			g = len(groups)
+			index[key] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], i)
+	}
+	return groups
+}
+
+// runBatchInstall installs several sources, jobs repositories at a time, and
+// reports each outcome instead of stopping at the first failure. It returns
+// false when any source failed.
+func runBatchInstall(sources []string, jobs int) bool {
+	threshold, err := auditThreshold("")
+	if err != nil {
+		fmt.Println(styles.RenderError(err.Error()))
+		return false
+	}
+
+	fmt.Println()
+	fmt.Printf("%s Installing %d skills\n", styles.SpinnerStyle.Render("⠋"), len(sources))
+	fmt.Println()
+
+	items := resolveBatch(sources, "")
+	labels := make([]string, len(items))
+	for i, item := range items {
+		labels[i] = item.Name
+	}
+	view := ui.NewMultiProgress(os.Stdout, labels)
+	for i, item := range items {
+		switch {
+		case item.Err != nil:
+			view.Set(i, styles.ErrorStyle.Render(styles.IconCross+" "+item.Err.Error()))
+		case item.Skipped != "":
+			view.Set(i, styles.MutedStyle.Render("- skipped: "+item.Skipped))
+		default:
+			view.Set(i, styles.MutedStyle.Render("waiting"))
+		}
+	}
+
+	// Route archive download progress to the line of the skill downloading,
+	// by repository and ref as groups are formed.
+	var downloadsMu sync.Mutex
+	downloading := make(map[string]int)
+	github.SetDownloadProgressHandler(func(p github.DownloadProgress) {
+		downloadsMu.Lock()
+		i, ok := downloading[downloadKey(p.Repo, p.Ref)]
+		downloadsMu.Unlock()
+		if ok && !p.Done {
+			status := "downloading " + ui.FormatBytes(p.Bytes)
+			if p.Total > 0 {
+				status += " / " + ui.FormatBytes(p.Total)
+			}
+			view.Update(i, styles.MutedStyle.Render(status))
+		}
+	})
+	defer github.SetDownloadProgressHandler(archiveProgress())
+
+	groups := groupBatch(items)
+	work := make(chan []int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(max(jobs, 1), len(groups)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for group := range work {
+				for _, i := range group {
+					item := items[i]
+					downloadsMu.Lock()
+					downloading[archiveKey(item.Resolved.Info)] = i
+					downloadsMu.Unlock()
+					view.Set(i, styles.MutedStyle.Render("downloading"))
+
+					item.Err = installBatchItem(item, threshold, func() {
+						view.Set(i, styles.MutedStyle.Render("installing"))
+					})
+					switch {
+					case item.Err != nil:
+						view.Set(i, styles.ErrorStyle.Render(styles.IconCross+" "+item.Err.Error()))
+					case item.Skipped != "":
+						view.Set(i, styles.MutedStyle.Render("- skipped: "+item.Skipped))
+					default:
+						view.Set(i, styles.SuccessStyle.Render(styles.IconCheck+" installed")+styles.MutedStyle.Render(item.Detail))
+					}
+				}
+			}
+		}()
+	}
+	for _, group := range groups {
+		work <- group
+	}
+	close(work)
+	wg.Wait()
+	view.Stop()
+
+	return printBatchSummary(items)
+}
+
+// batchCommitMu serialises the conflict check and commit of batch installs.
+var batchCommitMu sync.Mutex
+
+// installBatchItem downloads and installs one resolved source. installing
+// is called once the download has finished.
+func installBatchItem(item *batchItem, threshold audit.Severity, installing func()) error {
+	stagingDir, err := skill.NewStagingDir()
+	if err != nil {
+		return fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(stagingDir)
+
+	if err := github.DownloadAndExtractTo(item.Resolved.Info, stagingDir); err != nil {
+		return err
+	}
+	installing()
+
+	// Workers would otherwise check for conflicts at the same time and both
+	// install skills sharing a name.
+	batchCommitMu.Lock()
+	defer batchCommitMu.Unlock()
+
+	conflicts, err := resolveConflicts(item.Name, stagedSkillName(stagingDir), item.Resolved.Info, installConflict, false)
+	if err != nil {
+		return err
+	}
+	if conflicts.Skip {
+		item.Skipped = conflicts.Conflicts[0].Describe()
+		return nil
+	}
+	item.Name = conflicts.Name
+
+	staged, err := installStaged(item.Source, item.Resolved, stagingDir, conflicts, threshold)
+	if err != nil {
+		return err
+	}
+	if conflicts.Rename {
+		item.Detail = " as " + item.Name
+	}
+	if staged.Warning != nil {
+		item.Detail += " (digest mismatch)"
+	} else if staged.Report != nil && len(staged.Report.Findings) > 0 {
+		item.Detail += fmt.Sprintf(" (%d audit finding(s); see sk audit %s)", len(staged.Report.Findings), item.Name)
+	}
+	return nil
+}
+
+// printBatchSummary prints the counts of a batch install and each failure,
+// returning false when any source failed.
+func printBatchSummary(items []*batchItem) bool {
+	var installed, skipped int
+	var failed []*batchItem
+	for _, item := range items {
+		switch {
+		case item.Err != nil:
+			failed = append(failed, item)
+		case item.Skipped != "":
+			skipped++
+		default:
+			installed++
+		}
+	}
+
+	fmt.Println()
+	summary := fmt.Sprintf("Installed %d of %d skills", installed, len(items))
+	if skipped > 0 {
+		summary += fmt.Sprintf(", %d skipped", skipped)
+	}
+	if len(failed) > 0 {
+		summary += fmt.Sprintf(", %d failed", len(failed))
+		fmt.Println(styles.RenderWarning(summary))
+	} else {
+		fmt.Println(styles.RenderSuccess(summary))
+	}
+
+	for _, item := range failed {
+		fmt.Printf("  %s %s: %s\n", styles.ErrorStyle.Render(styles.IconCross), item.Source, item.Err.Error())
+	}
+	if skipped > 0 {
+		fmt.Println(styles.MutedStyle.Render("  Use --force to reinstall skills that are already installed, or --on-conflict to resolve name conflicts."))
+	}
+	if len(failed) > 0 {
+		fmt.Println(styles.MutedStyle.Render("  Install a failed source on its own for details, e.g. audit findings."))
+	}
+	fmt.Println()
+	return len(failed) == 0
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/majiayu000/caude-skill-manager/internal/github"
+)
+
+func TestReadSourcesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "skills.txt")
+	content := "# documents\nanthropics/skills/docx\n\n  pdf  \n#xlsx\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sources, err := readSourcesFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"anthropics/skills/docx", "pdf"}; !reflect.DeepEqual(sources, want) {
+		t.Fatalf("got %q, want %q", sources, want)
+	}
+}
+
+func TestResolveBatchGroupsByRepository(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	items := resolveBatch([]string{
+		"anthropics/skills/docx",
+		"obra/superpowers",
+		"Anthropics/skills/pdf",
+		"other/repo/docx",
+		"https://github.com/anthropics/skills/tree/dev/xlsx",
+	}, "")
+	if items[3].Err == nil {
+		t.Fatal("expected a second skill named docx to be rejected")
+	}
+	for _, i := range []int{0, 1, 2, 4} {
+		if items[i].Err != nil {
+			t.Fatalf("%s: unexpected error %v", items[i].Source, items[i].Err)
+		}
+	}
+
+	groups := groupBatch(items)
+	if want := [][]int{{0, 2}, {1}, {4}}; !reflect.DeepEqual(groups, want) {
+		t.Fatalf("got groups %v, want %v", groups, want)
+	}
+}
+
+func TestInstallSourcesFromRequirementsOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "one.txt")
+	if err := os.WriteFile(path, []byte("anthropics/skills/docx\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	installFile = path
+	t.Cleanup(func() { installFile = "" })
+
+	if err := installCmd.Args(installCmd, nil); err != nil {
+		t.Fatalf("expected -r without positional sources to be accepted: %v", err)
+	}
+	sources, err := installSources(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"anthropics/skills/docx"}; !reflect.DeepEqual(sources, want) {
+		t.Fatalf("got %q, want %q", sources, want)
+	}
+}
+
+func TestArchiveKeyMatchesDownloadProgress(t *testing.T) {
+	main := &github.RepoInfo{Owner: "Anthropics", Repo: "skills", Branch: "main"}
+	dev := &github.RepoInfo{Owner: "anthropics", Repo: "skills", Branch: "dev"}
+	if archiveKey(main) == archiveKey(dev) {
+		t.Fatal("expected refs of one repository to have their own progress lines")
+	}
+	if got := downloadKey("Anthropics/skills", "main"); got != archiveKey(main) {
+		t.Fatalf("download progress key %q does not match group key %q", got, archiveKey(main))
+	}
+}
+
+func TestInstallFailsWhenConfigBroken(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	if err := os.WriteFile(filepath.Join(home, ".skrc"), []byte(`{"policy":{"denied_owners":["evil"]},}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	items := resolveBatch([]string{"evil/skills/pdf"}, "")
+	if items[0].Err == nil {
+		t.Fatal("expected an install to fail while ~/.skrc cannot be parsed")
+	}
+}
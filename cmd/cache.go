@@ -0,0 +1,267 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/majiayu000/caude-skill-manager/internal/config"
+	"github.com/majiayu000/caude-skill-manager/internal/github"
+	"github.com/majiayu000/caude-skill-manager/internal/registry"
+	"github.com/majiayu000/caude-skill-manager/internal/ui"
+	"github.com/majiayu000/caude-skill-manager/pkg/styles"
+	"github.com/spf13/cobra"
+)
+
+var cacheRefreshAsync bool
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect, refresh and clear registry and download caches",
+	Long: `Manage the on-disk caches of remote registries (the full registry, search
+index, featured list, categories, downloaded shards and HTTP validators) and
+of downloaded repository archives. Local registries are read from disk and
+never cached.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var cacheListCmd = &cobra.Command{
+	Use:     "list [registry]...",
+	Aliases: []string{"ls"},
+	Short:   "Show cached artifacts with their size, age and freshness",
+	Run: func(cmd *cobra.Command, args []string) {
+		sources := cacheSources(args)
+
+		var total int64
+		for _, src := range sources {
+			fmt.Println()
+			fmt.Println(styles.TitleStyle.Render(styles.IconGear + " Registry " + src.Name))
+			fmt.Println(styles.MutedStyle.Render("  " + src.BaseURL))
+			fmt.Println()
+
+			if src.Local() {
+				fmt.Printf("  %s Local registry: read from disk on every command, not cached\n", styles.MutedStyle.Render(styles.IconArrow))
+				continue
+			}
+			for _, entry := range registry.CacheEntries(src) {
+				printCacheEntry(entry)
+				total += entry.Size
+			}
+		}
+
+		if len(args) == 0 {
+			archives := github.GetArchiveCacheStats()
+			fmt.Println()
+			fmt.Println(styles.TitleStyle.Render(styles.IconGear + " Downloaded archives"))
+			fmt.Println()
+			printCacheEntry(registry.CacheEntry{
+				Label:   "Archives",
+				Path:    archives.Dir,
+				Store:   true,
+				Exists:  archives.Archives > 0,
+				Size:    archives.Size,
+				Files:   archives.Archives,
+				ModTime: archives.ModTime,
+			})
+			total += archives.Size
+		}
+
+		fmt.Println()
+		fmt.Printf("  %s total in %s\n", ui.FormatBytes(total), config.CacheDir())
+		fmt.Println()
+	},
+}
+
+var cacheRefreshCmd = &cobra.Command{
+	Use:   "refresh [registry]...",
+	Short: "Re-fetch the registry and search index, ignoring the cache TTL",
+	Long: `Re-fetch the full registry and search index of every remote registry, or of
+the named ones, and replace their caches. Unchanged shards are reused.
+
+With --async the refresh runs in a background process and the command returns
+immediately; its output is written to refresh.log in the cache directory.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		sources := cacheSources(args)
+
+		if config.IsOffline() {
+			fmt.Println(styles.RenderError("Cannot refresh caches: " + config.ErrOffline.Error()))
+			os.Exit(1)
+		}
+		if cacheRefreshAsync {
+			logPath, err := startBackgroundRefresh(args)
+			if err != nil {
+				fmt.Println(styles.RenderError("Failed to start background refresh: " + err.Error()))
+				os.Exit(1)
+			}
+			fmt.Println(styles.RenderSuccess("Refreshing registry caches in the background"))
+			fmt.Println(styles.MutedStyle.Render("  Log: " + logPath))
+			return
+		}
+
+		failed := false
+		for _, src := range sources {
+			if src.Local() {
+				continue
+			}
+			err := ui.RunWithSpinner("Refreshing "+src.Name+"...", func() (string, error) {
+				if err := registry.Refresh(src); err != nil {
+					return "", err
+				}
+				return styles.RenderSuccess("Refreshed " + src.Name), nil
+			})
+			if err != nil {
+				failed = true
+			}
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear [registry]...",
+	Short: "Remove cached registry data",
+	Long: `Remove every cached artifact of all remote registries and the downloaded
+repository archives, or only the caches of the named registries. The next
+command that needs them fetches them again.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var freed int64
+		for _, src := range cacheSources(args) {
+			n, err := registry.ClearCache(src)
+			freed += n
+			if err != nil {
+				fmt.Println(styles.RenderError(fmt.Sprintf("Failed to clear %s cache: %s", src.Name, err.Error())))
+				os.Exit(1)
+			}
+		}
+		if len(args) == 0 {
+			n, err := github.ClearArchiveCache()
+			freed += n
+			if err != nil {
+				fmt.Println(styles.RenderError("Failed to clear archive cache: " + err.Error()))
+				os.Exit(1)
+			}
+		}
+		fmt.Println(styles.RenderSuccess("Cleared caches, freed " + ui.FormatBytes(freed)))
+	},
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove cache data no configured registry needs",
+	Long: `Remove shards and HTTP validators unused for 30 days, the caches of
+registries no longer configured, and downloaded archives beyond the
+archive_cache age and size bounds. Expired registry and search index caches
+are kept so --offline and unreachable registries still have data to serve.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		freed, err := registry.PruneCaches(cacheSources(nil))
+		if err != nil {
+			fmt.Println(styles.RenderError("Failed to prune caches: " + err.Error()))
+			os.Exit(1)
+		}
+		freed += github.PruneArchiveCache()
+		fmt.Println(styles.RenderSuccess("Pruned caches, freed " + ui.FormatBytes(freed)))
+	},
+}
+
+// cacheSources returns the named registries, or every configured registry
+// when names is empty.
+func cacheSources(names []string) []registry.Source {
+	if len(names) == 0 {
+		sources, err := registry.Sources()
+		if err != nil {
+			fmt.Println(styles.RenderError(err.Error()))
+			os.Exit(1)
+		}
+		return sources
+	}
+
+	sources := make([]registry.Source, 0, len(names))
+	for _, name := range names {
+		src, err := registry.SourceByName(name)
+		if err != nil {
+			fmt.Println(styles.RenderError(err.Error()))
+			os.Exit(1)
+		}
+		sources = append(sources, src)
+	}
+	return sources
+}
+
+func printCacheEntry(entry registry.CacheEntry) {
+	icon, style := styles.IconCheck, styles.SuccessStyle
+	state := "fresh"
+	switch {
+	case !entry.Exists:
+		icon, style, state = styles.IconArrow, styles.MutedStyle, "missing"
+	case entry.Store:
+		state = fmt.Sprintf("%d file(s)", entry.Files)
+	case entry.Expired():
+		icon, style, state = styles.IconWarning, styles.WarningStyle, "expired"
+	}
+
+	age := "-"
+	if entry.Exists {
+		age = formatAge(time.Since(entry.ModTime))
+	}
+	fmt.Printf("  %s %-16s %9s  %8s  %s\n", style.Render(icon), entry.Label, ui.FormatBytes(entry.Size), age, state)
+	fmt.Printf("    %s\n", styles.MutedStyle.Render(entry.Path))
+	if entry.URL != "" {
+		fmt.Printf("    %s\n", styles.MutedStyle.Render(entry.URL))
+	}
+}
+
+// formatAge renders d in its largest whole unit, such as "5m ago" or "2d ago".
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d/time.Minute))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d/time.Hour))
+	}
+	return fmt.Sprintf("%dd ago", int(d/(24*time.Hour)))
+}
+
+// startBackgroundRefresh re-runs sk cache refresh for names in a detached
+// process that outlives this one, logging to refresh.log in the cache
+// directory.
+func startBackgroundRefresh(names []string) (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(config.CacheDir(), 0755); err != nil {
+		return "", err
+	}
+	logPath := filepath.Join(config.CacheDir(), "refresh.log")
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return "", err
+	}
+	defer logFile.Close()
+
+	child := exec.Command(exe, append([]string{"cache", "refresh"}, names...)...)
+	child.Stdout = logFile
+	child.Stderr = logFile
+	if err := child.Start(); err != nil {
+		return "", err
+	}
+	return logPath, child.Process.Release()
+}
+
+func init() {
+	cacheRefreshCmd.Flags().BoolVar(&cacheRefreshAsync, "async", false, "Refresh in a background process and return immediately")
+	cacheCmd.AddCommand(cacheListCmd)
+	cacheCmd.AddCommand(cacheRefreshCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	cacheCmd.AddCommand(cachePruneCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/majiayu000/caude-skill-manager/internal/github"
+	"github.com/majiayu000/caude-skill-manager/internal/skill"
+	"github.com/majiayu000/caude-skill-manager/pkg/styles"
+)
+
+// Ways to resolve a name conflict, as accepted by --on-conflict.
+const (
+	conflictRename  = "rename"
+	conflictReplace = "replace"
+	conflictSkip    = "skip"
+)
+
+// conflictPlan is how an install deals with the installed skills it
+// conflicts with.
+type conflictPlan struct {
+	Name      string        // directory to install into
+	Rename    bool          // rewrite the front-matter name to Name
+	Replace   []skill.Skill // installed skills to remove first
+	Skip      bool          // do not install
+	Conflicts []skill.Conflict
+}
+
+// conflictError reports conflicts that were not resolved.
+type conflictError struct {
+	Conflicts []skill.Conflict
+}
+
+func (e *conflictError) Error() string {
+	described := make([]string, len(e.Conflicts))
+	for i, c := range e.Conflicts {
+		described[i] = c.Describe()
+	}
+	return "name conflict: " + strings.Join(described, "; ")
+}
+
+// checkOnConflict validates an --on-conflict value.
+func checkOnConflict(choice string) error {
+	switch choice {
+	case "", conflictRename, conflictReplace, conflictSkip:
+		return nil
+	}
+	return fmt.Errorf("--on-conflict must be %s, %s or %s", conflictRename, conflictReplace, conflictSkip)
+}
+
+// stagedSkillName returns the front-matter name of the SKILL.md in dir, or
+// "" when it sets none.
+func stagedSkillName(dir string) string {
+	content, err := os.ReadFile(filepath.Join(dir, "SKILL.md"))
+	if err != nil {
+		return ""
+	}
+	return skill.ParseDocument(content).Meta.Name
+}
+
+// conflictExclude is the install that --force replaces, which is not a
+// conflict.
+func conflictExclude(name string) string {
+	if installForce {
+		return skill.GetSkillDir(name)
+	}
+	return ""
+}
+
+// resolveConflicts checks a skill about to be installed into directory name,
+// with front-matter name frontName, against the skills installed in every
+// scope and decides what to do about any it conflicts with. choice is the
+// --on-conflict value; when it is empty the user is asked if prompt is set,
+// and otherwise a *conflictError is returned.
+func resolveConflicts(name, frontName string, info *github.RepoInfo, choice string, prompt bool) (*conflictPlan, error) {
+	exclude := conflictExclude(name)
+	conflicts, err := skill.FindConflicts(name, frontName, exclude)
+	if err != nil {
+		return nil, err
+	}
+	plan := &conflictPlan{Name: name, Conflicts: conflicts}
+	if len(conflicts) == 0 {
+		return plan, nil
+	}
+
+	if choice == "" {
+		if !prompt {
+			return plan, &conflictError{conflicts}
+		}
+		if choice, err = askConflictChoice(conflicts); err != nil {
+			return plan, err
+		}
+	}
+
+	switch choice {
+	case conflictSkip:
+		plan.Skip = true
+	case conflictReplace:
+		for _, c := range conflicts {
+			if c.Skill.Scope != skill.ScopeUser {
+				return plan, fmt.Errorf("%s: sk only replaces skills it manages; rename or skip instead", c.Describe())
+			}
+			plan.Replace = append(plan.Replace, c.Skill)
+		}
+	case conflictRename:
+		suggested, err := suggestName(name, info.Owner, exclude)
+		if err != nil {
+			return plan, err
+		}
+		plan.Name = suggested
+		if prompt {
+			if plan.Name, err = askNewName(suggested, exclude); err != nil {
+				return plan, err
+			}
+		}
+		plan.Rename = true
+	default:
+		return plan, checkOnConflict(choice)
+	}
+	return plan, nil
+}
+
+// suggestName proposes a conflict-free name for a skill: name-owner, then
+// name-owner-2 and so on.
+func suggestName(name, owner, exclude string) (string, error) {
+	base := name + "-" + strings.ToLower(owner)
+	for n := 1; n < 100; n++ {
+		candidate := base
+		if n > 1 {
+			candidate = fmt.Sprintf("%s-%d", base, n)
+		}
+		conflicts, err := skill.FindConflicts(candidate, candidate, exclude)
+		if err != nil {
+			return "", err
+		}
+		if len(conflicts) == 0 {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("no free name like '%s'; use --name", base)
+}
+
+func askConflictChoice(conflicts []skill.Conflict) (string, error) {
+	described := make([]string, len(conflicts))
+	for i, c := range conflicts {
+		described[i] = "  " + c.Describe()
+	}
+
+	var choice string
+	err := huh.NewSelect[string]().
+		Title("This skill conflicts with installed skills").
+		Description(strings.Join(described, "\n")).
+		Options(
+			huh.NewOption("Rename the new skill", conflictRename),
+			huh.NewOption("Replace the installed skill(s)", conflictReplace),
+			huh.NewOption("Skip this install", conflictSkip),
+		).
+		Value(&choice).
+		Run()
+	if err != nil {
+		return "", errors.New("cancelled")
+	}
+	return choice, nil
+}
+
+func askNewName(suggested, exclude string) (string, error) {
+	name := suggested
+	err := huh.NewInput().
+		Title("Install as").
+		Value(&name).
+		Validate(func(s string) error {
+			s = strings.TrimSpace(s)
+			if s == "" || s != filepath.Base(s) || strings.HasPrefix(s, ".") {
+				return errors.New("enter a directory name")
+			}
+			conflicts, err := skill.FindConflicts(s, s, exclude)
+			if err != nil {
+				return err
+			}
+			if len(conflicts) > 0 {
+				return errors.New(conflicts[0].Describe())
+			}
+			return nil
+		}).
+		Run()
+	if err != nil {
+		return "", errors.New("cancelled")
+	}
+	return strings.TrimSpace(name), nil
+}
+
+// printConflictError prints a resolveConflicts failure, listing each
+// conflict and how to resolve them.
+func printConflictError(err error) {
+	var conflictErr *conflictError
+	if !errors.As(err, &conflictErr) {
+		fmt.Println(styles.RenderError(err.Error()))
+		return
+	}
+	fmt.Println(styles.RenderError("This skill conflicts with installed skills:"))
+	for _, c := range conflictErr.Conflicts {
+		fmt.Printf("  %s %s\n", styles.WarningStyle.Render(styles.IconWarning), c.Describe())
+	}
+	fmt.Println(styles.MutedStyle.Render("Rerun with --on-conflict rename, replace or skip."))
+}
+
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/majiayu000/caude-skill-manager/internal/github"
+)
+
+func writeTestSkill(t *testing.T, root, dir, name string) {
+	t.Helper()
+	path := filepath.Join(root, dir)
+	if err := os.MkdirAll(path, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(path, "SKILL.md"), []byte("---\nname: "+name+"\n---\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResolveConflicts(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	work := t.TempDir()
+	t.Chdir(work)
+	user := filepath.Join(home, ".claude", "skills")
+	writeTestSkill(t, user, "pdf-tools", "pdf")
+	info := &github.RepoInfo{Owner: "Acme", Repo: "skills"}
+
+	plan, err := resolveConflicts("pdf", "pdf", info, "", false)
+	var conflictErr *conflictError
+	if !errors.As(err, &conflictErr) || len(conflictErr.Conflicts) != 1 {
+		t.Fatalf("expected an unresolved conflict, got %v", err)
+	}
+
+	plan, err = resolveConflicts("pdf", "pdf", info, conflictRename, false)
+	if err != nil || !plan.Rename || plan.Name != "pdf-acme" {
+		t.Fatalf("expected a rename to pdf-acme, got %+v, %v", plan, err)
+	}
+
+	plan, err = resolveConflicts("pdf", "pdf", info, conflictReplace, false)
+	if err != nil || len(plan.Replace) != 1 || filepath.Base(plan.Replace[0].Path) != "pdf-tools" {
+		t.Fatalf("expected pdf-tools to be replaced, got %+v, %v", plan, err)
+	}
+
+	writeTestSkill(t, filepath.Join(work, ".claude", "skills"), "pdf", "pdf")
+	if _, err := resolveConflicts("pdf", "pdf", info, conflictReplace, false); err == nil {
+		t.Fatal("expected a project skill not to be replaced")
+	}
+	plan, err = resolveConflicts("pdf", "pdf", info, conflictSkip, false)
+	if err != nil || !plan.Skip {
+		t.Fatalf("expected a skip, got %+v, %v", plan, err)
+	}
+
+	if plan, err := resolveConflicts("xlsx", "xlsx", info, "", false); err != nil || len(plan.Conflicts) != 0 {
+		t.Fatalf("expected no conflicts for xlsx, got %+v, %v", plan, err)
+	}
+}
@@ -3,28 +3,39 @@ package cmd
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/majiayu000/caude-skill-manager/internal/config"
+	"github.com/majiayu000/caude-skill-manager/internal/github"
+	"github.com/majiayu000/caude-skill-manager/internal/httpclient"
 	"github.com/majiayu000/caude-skill-manager/internal/registry"
 	"github.com/majiayu000/caude-skill-manager/internal/skill"
 	"github.com/majiayu000/caude-skill-manager/pkg/styles"
 	"github.com/spf13/cobra"
 )
 
-var doctorRegistry bool
+var (
+	doctorRegistry bool
+	doctorNetwork  bool
+)
 
 var doctorCmd = &cobra.Command{
 	Use:   "doctor",
 	Short: "Check skills health",
-	Long:  `Run diagnostics to check for common issues with your skills setup and registry cache.`,
+	Long:  `Run diagnostics to check for common issues with your skills setup, registry cache and network access.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if doctorRegistry {
 			runRegistryDiagnostics()
 			return
 		}
+		if doctorNetwork {
+			runNetworkDiagnostics()
+			return
+		}
 
 		fmt.Println()
 		fmt.Println(styles.TitleStyle.Render(styles.IconGear + " Skills Health Check"))
@@ -84,6 +95,36 @@ var doctorCmd = &cobra.Command{
 			}
 		}
 
+		// Claude Code cannot tell apart skills sharing a front-matter name,
+		// whichever scope they come from.
+		duplicates, err := skill.DuplicateNames()
+		if err != nil {
+			fmt.Printf("  %s Failed to check for duplicate names: %s\n",
+				styles.ErrorStyle.Render(styles.IconCross),
+				err.Error(),
+			)
+			issues++
+		}
+		for _, group := range duplicates {
+			fmt.Printf("\n  %s %d skills are named '%s':\n",
+				styles.WarningStyle.Render(styles.IconWarning),
+				len(group),
+				group[0].Name,
+			)
+			for _, s := range group {
+				fmt.Printf("    %s %s %s\n",
+					styles.MutedStyle.Render(styles.IconArrow),
+					s.Path,
+					styles.MutedStyle.Render("("+s.Scope+")"),
+				)
+			}
+			fmt.Printf("    %s Rename or remove all but one; reinstall with %s to rename\n",
+				styles.MutedStyle.Render(styles.IconArrow),
+				styles.CodeStyle.Render("sk install <source> --force --on-conflict rename"),
+			)
+			issues++
+		}
+
 		// Summary
 		fmt.Println()
 		if issues == 0 {
@@ -132,18 +173,54 @@ func runRegistryDiagnostics() {
 	fmt.Println()
 	fmt.Println(styles.TitleStyle.Render(styles.IconGear + " Registry Diagnostics"))
 	fmt.Println()
-	fmt.Printf("  %s Registry URL: %s\n", styles.SuccessStyle.Render(styles.IconCheck), config.GetRegistryBaseURL())
 	fmt.Printf("  %s Config file: %s\n", styles.SuccessStyle.Render(styles.IconCheck), config.ConfigPath())
 	fmt.Printf("  %s Cache TTL: %d hour(s)\n", styles.SuccessStyle.Render(styles.IconCheck), config.GetRegistryTTL())
-	fmt.Println()
 
-	printCacheInspection("Full registry cache", inspectCacheFile(config.RegistryCachePath(), ttl, validateRegistryCachePayload))
-	printCacheInspection("Search index cache", inspectCacheFile(config.SearchIndexCachePath(), ttl, validateSearchIndexCachePayload))
+	sources, err := registry.Sources()
+	if err != nil {
+		fmt.Printf("  %s %s\n", styles.ErrorStyle.Render(styles.IconCross), err.Error())
+		fmt.Println()
+		return
+	}
+
+	cached := false
+	for _, src := range sources {
+		fmt.Println()
+		fmt.Printf("  %s Registry %s: %s\n", styles.SuccessStyle.Render(styles.IconCheck), src.Name, src.BaseURL)
+		if src.PublicKey == "" {
+			fmt.Printf("  %s Manifest signatures: not required (no public key)\n", styles.MutedStyle.Render(styles.IconArrow))
+		} else if _, err := registry.ParsePublicKey(src.PublicKey); err != nil {
+			fmt.Printf("  %s Manifest signatures: %s\n", styles.ErrorStyle.Render(styles.IconCross), err.Error())
+		} else {
+			fmt.Printf("  %s Manifest signatures: required (ed25519 key pinned)\n", styles.SuccessStyle.Render(styles.IconCheck))
+		}
+
+		if src.Local() {
+			fmt.Printf("  %s Local registry: read from disk on every command, not cached\n", styles.MutedStyle.Render(styles.IconArrow))
+			continue
+		}
+
+		registryCache := config.RegistryCachePathFor(src.Name)
+		searchCache := config.SearchIndexCachePathFor(src.Name)
+		printCacheInspection("Full registry cache", inspectCacheFile(registryCache, ttl, validateRegistryCachePayload))
+		printCacheInspection("Search index cache", inspectCacheFile(searchCache, ttl, validateSearchIndexCachePayload))
+		printCacheInspection("Featured cache", inspectCacheFile(config.FeaturedCachePathFor(src.Name), ttl, validateFeaturedCachePayload))
+		printCacheInspection("Category index cache", inspectCacheFile(config.CategoryIndexCachePathFor(src.Name), ttl, validateCategoryIndexCachePayload))
+		categoryCaches, _ := filepath.Glob(filepath.Join(config.CategoryCacheDirFor(src.Name), "*.json"))
+		for _, path := range categoryCaches {
+			name := strings.TrimSuffix(filepath.Base(path), ".json")
+			printCacheInspection("Category cache "+name, inspectCacheFile(path, ttl, validateCategoryCachePayload))
+		}
+		cached = true
+	}
 
 	fmt.Println()
-	fmt.Println(styles.MutedStyle.Render("Recovery: remove stale or malformed cache files, then rerun a registry command."))
-	fmt.Printf("  rm -f %s %s\n", config.RegistryCachePath(), config.SearchIndexCachePath())
-	fmt.Println()
+	if cached {
+		fmt.Println(styles.MutedStyle.Render("Recovery: refresh stale caches, or clear malformed ones so the next registry command refetches them."))
+		fmt.Printf("  %s\n", styles.CodeStyle.Render("sk cache refresh"))
+		fmt.Printf("  %s\n", styles.CodeStyle.Render("sk cache clear"))
+		fmt.Println()
+	}
 }
 
 func inspectCacheFile(path string, ttl time.Duration, validate func([]byte) error) cacheInspection {
@@ -224,6 +301,33 @@ func validateSearchIndexCachePayload(data []byte) error {
 	return nil
 }
 
+func validateFeaturedCachePayload(data []byte) error {
+	var payload registry.Featured
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return fmt.Errorf("file is not a valid featured cache: %w", err)
+	}
+	return nil
+}
+
+func validateCategoryIndexCachePayload(data []byte) error {
+	var payload registry.CategoryIndex
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return fmt.Errorf("file is not a valid category index cache: %w", err)
+	}
+	return nil
+}
+
+func validateCategoryCachePayload(data []byte) error {
+	var payload registry.Category
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return fmt.Errorf("file is not a valid category cache: %w", err)
+	}
+	if payload.DeprecatedFullPayload && len(payload.Skills) == 0 {
+		return fmt.Errorf("category cache contains pointer without skills; remove it to force a clean remote fetch")
+	}
+	return nil
+}
+
 func printCacheInspection(name string, inspection cacheInspection) {
 	icon := styles.IconCheck
 	style := styles.SuccessStyle
@@ -237,7 +341,109 @@ func printCacheInspection(name string, inspection cacheInspection) {
 	fmt.Printf("    detail: %s\n", inspection.Detail)
 }
 
+// proxyEnvVars are the variables, in lookup order, that choose a proxy.
+var proxyEnvVars = []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy", "NO_PROXY", "no_proxy"}
+
+func runNetworkDiagnostics() {
+	network := config.GetNetwork()
+
+	fmt.Println()
+	fmt.Println(styles.TitleStyle.Render(styles.IconGear + " Network Diagnostics"))
+	fmt.Println()
+	fmt.Printf("  %s Timeouts: connect %ds, request %ds, %d retries\n",
+		styles.SuccessStyle.Render(styles.IconCheck),
+		network.ConnectTimeoutSeconds, network.TimeoutSeconds, network.Retries)
+
+	proxySet := false
+	for _, name := range proxyEnvVars {
+		if value := os.Getenv(name); value != "" {
+			fmt.Printf("  %s %s=%s\n", styles.SuccessStyle.Render(styles.IconCheck), name, redactProxy(value))
+			proxySet = true
+		}
+	}
+	if !proxySet {
+		fmt.Printf("  %s Proxy: none (HTTPS_PROXY, HTTP_PROXY and NO_PROXY are unset)\n", styles.MutedStyle.Render(styles.IconArrow))
+	}
+
+	caBundle := "system roots"
+	if network.CABundle != "" {
+		caBundle = "system roots + " + network.CABundle
+	}
+	clientCert := "none"
+	if network.ClientCert != "" {
+		clientCert = network.ClientCert + " (key " + network.ClientKey + ")"
+	}
+	if _, err := httpclient.Client(); err != nil {
+		fmt.Printf("  %s TLS: %s\n", styles.ErrorStyle.Render(styles.IconCross), err.Error())
+		fmt.Println()
+		return
+	}
+	fmt.Printf("  %s CA certificates: %s\n", styles.SuccessStyle.Render(styles.IconCheck), caBundle)
+	fmt.Printf("  %s Client certificate: %s\n", styles.SuccessStyle.Render(styles.IconCheck), clientCert)
+
+	type endpoint struct{ name, url string }
+	var endpoints []endpoint
+	if sources, err := registry.Sources(); err != nil {
+		fmt.Printf("  %s %s\n", styles.ErrorStyle.Render(styles.IconCross), err.Error())
+	} else {
+		for _, src := range sources {
+			if !src.Local() {
+				endpoints = append(endpoints, endpoint{"Registry " + src.Name, src.BaseURL})
+			}
+		}
+	}
+	for _, e := range github.Endpoints() {
+		endpoints = append(endpoints, endpoint{e.Name, e.URL})
+	}
+
+	failed := 0
+	fmt.Println()
+	for _, e := range endpoints {
+		route := "direct"
+		if proxy, err := httpclient.ProxyFor(e.url); err != nil {
+			route = "invalid proxy: " + err.Error()
+		} else if proxy != nil {
+			route = "via " + proxy.Redacted()
+		}
+
+		if config.IsOffline() {
+			fmt.Printf("  %s %s: %s\n", styles.MutedStyle.Render(styles.IconArrow), e.name, e.url)
+			fmt.Printf("    %s, not tested (--offline)\n", route)
+			continue
+		}
+		status, elapsed, err := httpclient.Probe(e.url)
+		if err != nil {
+			failed++
+			fmt.Printf("  %s %s: %s\n", styles.ErrorStyle.Render(styles.IconCross), e.name, e.url)
+			fmt.Printf("    %s, %s\n", route, err.Error())
+			if httpclient.IsUnknownAuthority(err) {
+				fmt.Printf("    %s Set %s in ~/.skrc to trust your organisation's CA\n",
+					styles.MutedStyle.Render(styles.IconArrow), styles.CodeStyle.Render("network.ca_bundle"))
+			}
+			continue
+		}
+		fmt.Printf("  %s %s: %s\n", styles.SuccessStyle.Render(styles.IconCheck), e.name, e.url)
+		fmt.Printf("    %s, HTTP %d in %s\n", route, status, elapsed.Round(time.Millisecond))
+	}
+
+	fmt.Println()
+	if failed > 0 {
+		fmt.Printf(styles.WarningStyle.Render("  %d of %d endpoint(s) unreachable. See above for details.\n"), failed, len(endpoints))
+		fmt.Println()
+	}
+}
+
+// redactProxy hides the password in a proxy URL.
+func redactProxy(value string) string {
+	u, err := url.Parse(value)
+	if err != nil || u.User == nil {
+		return value
+	}
+	return u.Redacted()
+}
+
 func init() {
 	doctorCmd.Flags().BoolVar(&doctorRegistry, "registry", false, "Show registry configuration and cache diagnostics")
+	doctorCmd.Flags().BoolVar(&doctorNetwork, "network", false, "Show proxy and TLS settings and test connectivity to each endpoint")
 	rootCmd.AddCommand(doctorCmd)
 }
@@ -91,6 +91,19 @@ func TestInspectCacheFileReportsInvalidSearchPointerCache(t *testing.T) {
 	}
 }
 
+func TestInspectCacheFileReportsInvalidCategoryPointerCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "documents.json")
+	if err := os.WriteFile(path, []byte(`{"deprecated_full_payload":true,"manifest":"categories/documents/manifest.json"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got := inspectCacheFile(path, time.Hour, validateCategoryCachePayload)
+
+	if got.State != "invalid" {
+		t.Fatalf("state = %q, want invalid; detail=%s", got.State, got.Detail)
+	}
+}
+
 func TestRegistrySourceMessage(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -4,89 +4,245 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/majiayu000/caude-skill-manager/internal/config"
+	"github.com/majiayu000/caude-skill-manager/internal/github"
 	"github.com/majiayu000/caude-skill-manager/internal/skill"
+	"github.com/majiayu000/caude-skill-manager/internal/ui"
 	"github.com/majiayu000/caude-skill-manager/pkg/styles"
 )
 
+var infoRemote bool // skip installed skills and show registry/GitHub details
+
 var infoCmd = &cobra.Command{
-	Use:     "info <skill-name>",
-	Aliases: []string{"show", "view"},
+	Use:     "info <skill-name|owner/repo/path>",
+	Aliases: []string{"view"},
 	Short:   "Show skill details",
-	Long:    `Display detailed information about an installed skill.`,
-	Args:    cobra.ExactArgs(1),
+	Long: `Display detailed information about a skill.
+
+Installed skills are shown from the local skills directory. Otherwise the
+skill is looked up in the registry (or on GitHub for owner/repo/path refs)
+and its metadata, SKILL.md and the files it would install are shown.`,
+	Example: `  sk info my-skill
+  sk info docx --remote
+  sk info anthropics/skills/docx`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		name := args[0]
 
-		s, err := skill.Get(name)
+		if !infoRemote {
+			s, err := skill.Get(name)
+			if err != nil {
+				fmt.Println(styles.RenderError("Failed to get skill: " + err.Error()))
+				os.Exit(1)
+			}
+			if s != nil {
+				printInstalledSkill(s)
+				return
+			}
+		}
+
+		resolved, err := resolveSource(name)
 		if err != nil {
-			fmt.Println(styles.RenderError("Failed to get skill: " + err.Error()))
+			if !infoRemote {
+				fmt.Println(styles.RenderError(fmt.Sprintf("Skill '%s' is not installed.", name)))
+			}
+			printSourceError(err)
 			os.Exit(1)
 		}
-		if s == nil {
-			fmt.Println(styles.RenderError(fmt.Sprintf("Skill '%s' is not installed.", name)))
-			os.Exit(1)
+		if resolved.Entry != nil {
+			printRegistrySource(resolved.Source)
 		}
 
-		fmt.Println()
-		fmt.Println(styles.TitleStyle.Render(styles.IconPackage + " " + s.Name))
-		fmt.Println()
+		var remote *github.RemoteSkill
+		err = ui.RunWithSpinner("Fetching skill details...", func() (string, error) {
+			var fetchErr error
+			remote, fetchErr = github.ResolveRemoteSkill(resolved.Info)
+			return "", fetchErr
+		})
+		if err != nil {
+			os.Exit(1)
+		}
 
-		// Description
-		if s.Description != "" {
-			fmt.Println(styles.SkillDescStyle.Render(s.Description))
-			fmt.Println()
+		content, err := remote.ReadFile("SKILL.md")
+		if err != nil {
+			fmt.Println(styles.RenderError("Failed to fetch SKILL.md: " + err.Error()))
+			os.Exit(1)
 		}
 
-		// Details
-		fmt.Println(styles.TableHeaderStyle.Render("Details"))
+		printRemoteSkill(resolved, remote, skill.ParseDocument(content))
+	},
+}
+
+func printInstalledSkill(s *skill.Skill) {
+	fmt.Println()
+	fmt.Println(styles.TitleStyle.Render(styles.IconPackage + " " + s.Name))
+	fmt.Println()
+
+	// Description
+	if s.Description != "" {
+		fmt.Println(styles.SkillDescStyle.Render(s.Description))
 		fmt.Println()
+	}
+
+	// Details
+	fmt.Println(styles.TableHeaderStyle.Render("Details"))
+	fmt.Println()
 
+	fmt.Printf("  %s  %s\n",
+		styles.MutedStyle.Render("Path:"),
+		s.Path,
+	)
+
+	if s.Source != "" {
 		fmt.Printf("  %s  %s\n",
-			styles.MutedStyle.Render("Path:"),
-			s.Path,
+			styles.MutedStyle.Render("Source:"),
+			s.Source,
 		)
+	}
 
-		if s.Source != "" {
-			fmt.Printf("  %s  %s\n",
-				styles.MutedStyle.Render("Source:"),
-				s.Source,
-			)
-		}
+	if !s.InstalledAt.IsZero() {
+		fmt.Printf("  %s  %s\n",
+			styles.MutedStyle.Render("Installed:"),
+			s.InstalledAt.Format("2006-01-02 15:04:05"),
+		)
+	}
 
-		if !s.InstalledAt.IsZero() {
-			fmt.Printf("  %s  %s\n",
-				styles.MutedStyle.Render("Installed:"),
-				s.InstalledAt.Format("2006-01-02 15:04:05"),
-			)
+	if meta, err := skill.ReadInstallMeta(s.Path); err == nil && meta.Digest != "" {
+		fmt.Printf("  %s  %s\n",
+			styles.MutedStyle.Render("Digest:"),
+			meta.Digest,
+		)
+	}
+
+	// List files
+	fmt.Println()
+	fmt.Println(styles.TableHeaderStyle.Render("Files"))
+	fmt.Println()
+
+	filepath.Walk(s.Path, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(s.Path, path)
+		if rel == "." || rel == skill.MetaFile {
+			return nil
+		}
+		if info.IsDir() {
+			fmt.Printf("  %s %s/\n", styles.IconFolder, rel)
+		} else {
+			fmt.Printf("  %s %s\n", styles.IconFile, rel)
 		}
+		return nil
+	})
 
-		// List files
-		fmt.Println()
-		fmt.Println(styles.TableHeaderStyle.Render("Files"))
+	fmt.Println()
+}
+
+func printRemoteSkill(resolved *resolvedSource, remote *github.RemoteSkill, doc *skill.Document) {
+	name := doc.Meta.Name
+	if name == "" && resolved.Entry != nil {
+		name = resolved.Entry.Name
+	}
+	if name == "" {
+		name = github.GetSkillName(resolved.Info)
+	}
+
+	fmt.Println()
+	fmt.Println(styles.TitleStyle.Render(styles.IconPackage + " " + name))
+	fmt.Println()
+
+	description := doc.Meta.Description
+	if description == "" && resolved.Entry != nil {
+		description = resolved.Entry.Description
+	}
+	if description != "" {
+		fmt.Println(styles.SkillDescStyle.Render(description))
 		fmt.Println()
+	}
 
-		filepath.Walk(s.Path, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return nil
-			}
-			rel, _ := filepath.Rel(s.Path, path)
-			if rel == "." {
-				return nil
-			}
-			if info.IsDir() {
-				fmt.Printf("  %s %s/\n", styles.IconFolder, rel)
-			} else {
-				fmt.Printf("  %s %s\n", styles.IconFile, rel)
-			}
-			return nil
-		})
+	// Details
+	fmt.Println(styles.TableHeaderStyle.Render("Details"))
+	fmt.Println()
 
+	if entry := resolved.Entry; entry != nil {
+		if entry.Category != "" {
+			printDetail("Category:", entry.Category)
+		}
+		if entry.Stars > 0 {
+			printDetail("Stars:", fmt.Sprintf("%s %d", styles.IconStar, entry.Stars))
+		}
+		if len(entry.Tags) > 0 {
+			printDetail("Tags:", strings.Join(entry.Tags, ", "))
+		}
+		printDetail("Install:", entry.Install)
+		if entry.Registry != config.DefaultRegistryName {
+			printDetail("Registry:", entry.Registry)
+		}
+		if entry.Digest != "" {
+			printDetail("Digest:", entry.Digest)
+		}
+		if entry.Commit != "" {
+			printDetail("Commit:", entry.Commit)
+		}
+	}
+
+	printDetail("Repository:", resolved.Info.FullURL)
+	printDetail("Branch:", remote.Branch)
+	printDetail("GitHub:", fmt.Sprintf("https://github.com/%s/%s/blob/%s/%s",
+		remote.Owner, remote.Repo, remote.Branch, remote.SkillFile))
+
+	installName := github.GetSkillName(resolved.Info)
+	if s, _ := skill.Get(installName); s != nil {
+		printDetail("Status:", styles.RenderInstalledBadge()+" "+styles.MutedStyle.Render(s.Path))
+	} else if s, _ := skill.Get(name); s != nil {
+		printDetail("Status:", styles.WarningStyle.Render("a skill named '"+name+"' is already installed at "+s.Path))
+	} else {
+		printDetail("Status:", "not installed")
+	}
+
+	// SKILL.md
+	fmt.Println()
+	fmt.Println(styles.TableHeaderStyle.Render("SKILL.md"))
+	fmt.Println()
+
+	for _, field := range doc.FrontMatter {
+		fmt.Printf("  %s %s\n", styles.MutedStyle.Render(field.Key+":"), field.Value)
+	}
+	if len(doc.FrontMatter) > 0 {
 		fmt.Println()
-	},
+	}
+	for _, line := range strings.Split(strings.TrimRight(doc.Body, "\n"), "\n") {
+		fmt.Println("  " + line)
+	}
+
+	// Files
+	fmt.Println()
+	fmt.Println(styles.TableHeaderStyle.Render("Files"))
+	fmt.Println()
+
+	for _, f := range remote.Files {
+		fmt.Printf("  %s %-40s %s\n", styles.IconFile, f.Path, styles.MutedStyle.Render(ui.FormatBytes(f.Size)))
+	}
+	fmt.Println()
+	fmt.Printf("%s %d file(s), %s\n",
+		styles.MutedStyle.Render(styles.IconInfo),
+		len(remote.Files),
+		ui.FormatBytes(remote.TotalSize()),
+	)
+	if remote.Truncated {
+		fmt.Println(styles.RenderWarning("GitHub truncated the repository listing; some files may be missing."))
+	}
+	fmt.Println()
+}
+
+func printDetail(label, value string) {
+	fmt.Printf("  %s  %s\n", styles.MutedStyle.Render(fmt.Sprintf("%-11s", label)), value)
 }
 
 func init() {
+	infoCmd.Flags().BoolVarP(&infoRemote, "remote", "r", false, "Show registry details even if the skill is installed")
 	rootCmd.AddCommand(infoCmd)
 }
@@ -1,11 +1,14 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
+	"github.com/majiayu000/caude-skill-manager/internal/audit"
+	"github.com/majiayu000/caude-skill-manager/internal/config"
 	"github.com/majiayu000/caude-skill-manager/internal/github"
-	"github.com/majiayu000/caude-skill-manager/internal/registry"
+	"github.com/majiayu000/caude-skill-manager/internal/integrity"
 	"github.com/majiayu000/caude-skill-manager/internal/skill"
 	"github.com/majiayu000/caude-skill-manager/internal/ui"
 	"github.com/majiayu000/caude-skill-manager/pkg/styles"
@@ -13,12 +16,17 @@ import (
 )
 
 var (
-	installName  string // custom name for the skill
-	installForce bool   // force reinstall
+	installName      string // custom name for the skill
+	installForce     bool   // force reinstall
+	installSkipAudit bool   // install even if the audit finds risky content
+	installFile      string // file listing sources to install
+	installJobs      int    // repositories downloaded at once in a batch
+	installDryRun    bool   // print the plan without installing
+	installConflict  string // how to resolve name conflicts: rename, replace or skip
 )
 
 var installCmd = &cobra.Command{
-	Use:     "install <source>",
+	Use:     "install <source>...",
 	Aliases: []string{"i", "add"},
 	Short:   "Install a skill from GitHub",
 	Long: `Install a Claude Code skill from GitHub.
@@ -28,31 +36,81 @@ Supported formats:
   owner/repo                     Install entire repo
   owner/repo/path/to/skill       Install skill from subdirectory
   https://github.com/owner/repo  Full GitHub URL
+
+Several sources, or a file of sources with -r (one per line, # for
+comments), are installed as a batch: skills from the same repository share
+one download, repositories download in parallel, and a failure is reported
+in the summary without stopping the rest.
 `,
 	Example: `  sk install anthropics/skills/docx
   sk install docx
   sk install obra/superpowers
-  sk install https://github.com/user/repo`,
-	Args: cobra.ExactArgs(1),
+  sk install https://github.com/user/repo
+  sk install docx pdf anthropics/skills/xlsx
+  sk install -r skills.txt`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if installFile != "" {
+			return nil
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
-		source := args[0]
-
-		// Parse GitHub URL or resolve from registry by name
-		info, err := github.ParseGitHubURL(source)
+		sources, err := installSources(args)
 		if err != nil {
-			install, regSource, regErr := registry.ResolveInstall(source)
-			if regErr != nil {
-				fmt.Println(styles.RenderError(err.Error()))
-				fmt.Println(styles.MutedStyle.Render("Also tried registry lookup: " + regErr.Error()))
+			fmt.Println(styles.RenderError("Failed to read sources: " + err.Error()))
+			os.Exit(1)
+		}
+		if len(sources) == 0 {
+			fmt.Println(styles.RenderWarning("No sources to install."))
+			return
+		}
+		if err := checkOnConflict(installConflict); err != nil {
+			fmt.Println(styles.RenderError(err.Error()))
+			os.Exit(1)
+		}
+		if installName != "" && len(sources) > 1 {
+			fmt.Println(styles.RenderError("--name cannot be used when installing several skills."))
+			os.Exit(1)
+		}
+
+		if installDryRun {
+			printDryRunHeader()
+			ok := true
+			for _, item := range resolveBatch(sources, installName) {
+				replace := ""
+				if target := skill.GetSkillDir(item.Name); installForce && dirExists(target) {
+					replace = target
+				}
+				plan := planInstall(item, replace)
+				printInstallPlan(plan)
+				ok = ok && len(plan.Problems) == 0
+			}
+			if !ok {
 				os.Exit(1)
 			}
-			source = install
-			info, err = github.ParseGitHubURL(source)
-			if err != nil {
-				fmt.Println(styles.RenderError(err.Error()))
+			return
+		}
+		if len(sources) > 1 {
+			if !runBatchInstall(sources, installJobs) {
 				os.Exit(1)
 			}
-			printRegistrySource(regSource)
+			return
+		}
+
+		// Parse GitHub URL or resolve from registry by name
+		resolved, err := resolveSource(sources[0])
+		if err != nil {
+			printSourceError(err)
+			os.Exit(1)
+		}
+		info := resolved.Info
+		if resolved.Entry != nil {
+			printRegistrySource(resolved.Source)
+		}
+		if err := checkPolicy(resolved); err != nil {
+			fmt.Println(styles.RenderError(err.Error()))
+			fmt.Println(styles.MutedStyle.Render("Run 'sk policy show' to see the effective trust policy."))
+			os.Exit(1)
 		}
 
 		// Determine skill name
@@ -61,54 +119,203 @@ Supported formats:
 			skillName = github.GetSkillName(info)
 		}
 
-		// Check if already installed
-		if skill.Exists(skillName) && !installForce {
+		// Without a way to resolve it, an existing install is an error
+		// before anything is downloaded.
+		prompt := ui.CanPrompt()
+		if skill.Exists(skillName) && !installForce && installConflict == "" && !prompt {
 			fmt.Println(styles.RenderWarning(fmt.Sprintf("Skill '%s' is already installed.", skillName)))
-			fmt.Println(styles.MutedStyle.Render("Use --force to reinstall."))
+			fmt.Println(styles.MutedStyle.Render("Use --force to reinstall, or --on-conflict rename to install alongside it."))
 			os.Exit(1)
 		}
 
-		// Remove existing if force
-		if installForce && skill.Exists(skillName) {
-			if err := skill.Remove(skillName); err != nil {
-				fmt.Println(styles.RenderError("Failed to remove existing skill: " + err.Error()))
-				os.Exit(1)
-			}
+		threshold, err := auditThreshold("")
+		if err != nil {
+			fmt.Println(styles.RenderError(err.Error()))
+			os.Exit(1)
 		}
 
 		fmt.Println()
 		fmt.Printf("%s Installing %s\n", styles.SpinnerStyle.Render("⠋"), styles.CodeStyle.Render(skillName))
 		fmt.Printf("  %s %s\n", styles.MutedStyle.Render("from"), info.FullURL)
+		if resolved.Entry != nil && resolved.Entry.Registry != config.DefaultRegistryName {
+			fmt.Printf("  %s %s\n", styles.MutedStyle.Render("via registry"), resolved.Entry.Registry)
+		}
 		fmt.Println()
 
-		// Download and install with spinner
+		// Extract into a staging directory so the skill can be audited
+		// before anything in the skills directory changes.
+		stagingDir, err := skill.NewStagingDir()
+		if err != nil {
+			fmt.Println(styles.RenderError("Failed to create staging directory: " + err.Error()))
+			os.Exit(1)
+		}
+		defer os.RemoveAll(stagingDir)
+
 		err = ui.RunWithSpinner("Downloading...", func() (string, error) {
-			if err := github.DownloadAndExtract(info, skillName); err != nil {
+			if err := github.DownloadAndExtractTo(info, stagingDir); err != nil {
 				return "", err
 			}
-
-			// Get installed skill info
-			s, _ := skill.Get(skillName)
-			result := styles.RenderSuccess(fmt.Sprintf("Installed %s", styles.CodeStyle.Render(skillName)))
-			if s != nil && s.Description != "" {
-				result += "\n  " + styles.SkillDescStyle.Render(s.Description)
-			}
-			return result, nil
+			return styles.RenderSuccess("Downloaded " + info.FullURL), nil
 		})
+		if err != nil {
+			os.RemoveAll(stagingDir)
+			os.Exit(1)
+		}
 
+		conflicts, err := resolveConflicts(skillName, stagedSkillName(stagingDir), info, installConflict, prompt)
+		if err != nil {
+			printConflictError(err)
+			os.RemoveAll(stagingDir)
+			os.Exit(1)
+		}
+		if conflicts.Skip {
+			fmt.Println(styles.RenderWarning(fmt.Sprintf("Skipped %s: it conflicts with an installed skill.", skillName)))
+			return
+		}
+		if conflicts.Rename {
+			fmt.Println(styles.MutedStyle.Render(fmt.Sprintf("  Installing as '%s' to avoid a name conflict.", conflicts.Name)))
+		}
+		skillName = conflicts.Name
+
+		staged, err := installStaged(sources[0], resolved, stagingDir, conflicts, threshold)
+		if staged.Warning != nil {
+			fmt.Println(styles.RenderWarning(staged.Warning.Error()))
+		}
+		if staged.Report != nil && len(staged.Report.Findings) > 0 {
+			printAuditReport(skillName, staged.Report)
+			fmt.Println()
+		}
 		if err != nil {
 			fmt.Println(styles.RenderError(err.Error()))
+			var mismatch *integrity.MismatchError
+			var blocked *auditBlockedError
+			switch {
+			case errors.As(err, &mismatch):
+				fmt.Println(styles.MutedStyle.Render("The downloaded content does not match the registry record. Set \"integrity_mode\": \"warn\" in ~/.skrc to install anyway."))
+			case errors.As(err, &blocked):
+				fmt.Println(styles.MutedStyle.Render("Review the findings, then rerun with --skip-audit to install anyway."))
+			}
+			os.RemoveAll(stagingDir)
 			os.Exit(1)
 		}
 
+		fmt.Println(styles.RenderSuccess(fmt.Sprintf("Installed %s", styles.CodeStyle.Render(skillName))))
+		if s, _ := skill.Get(skillName); s != nil && s.Description != "" {
+			fmt.Println("  " + styles.SkillDescStyle.Render(s.Description))
+		}
+
 		fmt.Println()
 		fmt.Println(styles.MutedStyle.Render("  Skill installed to: ") + skill.GetSkillDir(skillName))
 		fmt.Println()
 	},
 }
 
+// installSources returns the sources to install: args, then those listed in
+// the --requirements file.
+func installSources(args []string) ([]string, error) {
+	sources := append([]string(nil), args...)
+	if installFile != "" {
+		listed, err := readSourcesFile(installFile)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, listed...)
+	}
+	return sources, nil
+}
+
+// installError is a failed install step; Step describes the step.
+type installError struct {
+	Step string
+	Err  error
+}
+
+func (e *installError) Error() string {
+	return e.Step + ": " + e.Err.Error()
+}
+
+func (e *installError) Unwrap() error {
+	return e.Err
+}
+
+// auditBlockedError reports audit findings at or above the block severity.
+type auditBlockedError struct {
+	Threshold audit.Severity
+}
+
+func (e *auditBlockedError) Error() string {
+	return fmt.Sprintf("audit findings at or above '%s' severity", e.Threshold)
+}
+
+// stagedInstall describes the checks installStaged ran.
+type stagedInstall struct {
+	Report  *audit.Report // nil when the audit was skipped or did not run
+	Warning error         // digest mismatch tolerated by integrity_mode "warn"
+}
+
+// installStaged checks a skill extracted to stagingDir and moves it into
+// place as conflicts.Name: it verifies the registry digest, audits the files
+// unless --skip-audit is set, renames the skill or removes the skills it
+// replaces as conflicts says, records the install metadata and, with
+// --force, replaces an existing install, restoring the replaced skills if
+// the install fails. Failures are *installError.
+func installStaged(source string, resolved *resolvedSource, stagingDir string, conflicts *conflictPlan, threshold audit.Severity) (stagedInstall, error) {
+	skillName := conflicts.Name
+	var staged stagedInstall
+	digest, warning, err := verifyIntegrity(resolved, stagingDir)
+	staged.Warning = warning
+	if err != nil {
+		return staged, &installError{"Integrity check failed", err}
+	}
+
+	if !installSkipAudit {
+		report, err := audit.ScanDir(stagingDir)
+		if err != nil {
+			return staged, &installError{"Failed to audit skill", err}
+		}
+		staged.Report = report
+		if report.Blocks(threshold) {
+			return staged, &installError{"Install blocked", &auditBlockedError{threshold}}
+		}
+	}
+
+	if conflicts.Rename {
+		if err := skill.SetName(stagingDir, skillName); err != nil {
+			return staged, &installError{"Failed to rename skill", err}
+		}
+		// The recorded digest is of the files as installed.
+		if digest, err = integrity.DigestDir(stagingDir, skill.MetaFile); err != nil {
+			return staged, &installError{"Failed to hash renamed skill", err}
+		}
+	}
+
+	if err := skill.WriteInstallMeta(stagingDir, installMeta(source, resolved, digest)); err != nil {
+		return staged, &installError{"Failed to record install metadata", err}
+	}
+
+	var replace []string
+	for _, s := range conflicts.Replace {
+		replace = append(replace, s.Path)
+	}
+	// With --force the directory being reinstalled is replaced too. A skill
+	// elsewhere sharing the name is a conflict, handled above.
+	if target := skill.GetSkillDir(skillName); installForce && dirExists(target) {
+		replace = append(replace, target)
+	}
+
+	if err := skill.CommitReplacing(stagingDir, skillName, replace); err != nil {
+		return staged, &installError{"Failed to install skill", err}
+	}
+	return staged, nil
+}
+
 func init() {
 	installCmd.Flags().StringVarP(&installName, "name", "n", "", "Custom name for the skill")
 	installCmd.Flags().BoolVarP(&installForce, "force", "f", false, "Force reinstall if already exists")
+	installCmd.Flags().BoolVar(&installSkipAudit, "skip-audit", false, "Install even if the security audit finds risky content")
+	installCmd.Flags().StringVarP(&installFile, "requirements", "r", "", "Install the sources listed in a file, one per line (- for stdin)")
+	installCmd.Flags().BoolVar(&installDryRun, "dry-run", false, "Show what would be installed, from where and into which directory, without changing anything")
+	installCmd.Flags().StringVar(&installConflict, "on-conflict", "", "Resolve name conflicts with installed skills: rename, replace or skip (asks when interactive)")
+	installCmd.Flags().IntVarP(&installJobs, "jobs", "j", 4, "Repositories to download at once when installing several skills")
 	rootCmd.AddCommand(installCmd)
 }
@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+
+	"github.com/majiayu000/caude-skill-manager/internal/config"
+	"github.com/majiayu000/caude-skill-manager/internal/github"
+	"github.com/majiayu000/caude-skill-manager/internal/skill"
+	"github.com/majiayu000/caude-skill-manager/internal/ui"
+	"github.com/majiayu000/caude-skill-manager/pkg/styles"
+)
+
+// installPlan is what installing one source would do, worked out without
+// downloading the archive or touching the skills directory.
+type installPlan struct {
+	Item       *batchItem
+	Target     string
+	Candidates []github.Candidate
+	Remote     *github.RemoteSkill // nil when the skill could not be located
+	ResolveErr error
+	Removes    []string // files of the existing install that would be removed
+	Conflicts  []skill.Conflict
+	Resolution string   // what --on-conflict would do about Conflicts
+	Problems   []string // reasons the install would fail
+}
+
+// planInstall plans the install of a resolved batch item. replace is the
+// directory of an existing install that would be removed first, as with
+// --force, or "".
+func planInstall(item *batchItem, replace string) *installPlan {
+	plan := &installPlan{Item: item}
+	if item.Err != nil {
+		plan.Problems = append(plan.Problems, item.Err.Error())
+		return plan
+	}
+	info := item.Resolved.Info
+	plan.Target = skill.GetSkillDir(item.Name)
+	if replace != "" {
+		plan.Target = replace
+	}
+	plan.Candidates = github.InstallCandidates(info)
+
+	// ResolveRemoteSkill may switch the branch to master; plan on a copy.
+	infoCopy := *info
+	plan.Remote, plan.ResolveErr = github.ResolveRemoteSkill(&infoCopy)
+	if plan.Remote != nil {
+		plan.Problems = append(plan.Problems, archiveLimitProblems(plan.Remote)...)
+	} else if !errors.Is(plan.ResolveErr, config.ErrOffline) {
+		plan.Problems = append(plan.Problems, "no candidate contains the skill: "+plan.ResolveErr.Error())
+	}
+
+	if replace != "" && dirExists(replace) {
+		files, err := skillFiles(replace)
+		if err != nil {
+			plan.Problems = append(plan.Problems, "cannot read the existing install: "+err.Error())
+		}
+		plan.Removes = files
+	}
+	if plan.Remote != nil && item.Skipped == "" {
+		planConflicts(plan, replace)
+	}
+	return plan
+}
+
+// planConflicts finds the installed skills the planned skill would conflict
+// with, reading its front-matter name from GitHub, and what --on-conflict
+// would do about them.
+func planConflicts(plan *installPlan, exclude string) {
+	frontName := ""
+	if content, err := plan.Remote.ReadFile("SKILL.md"); err == nil {
+		frontName = skill.ParseDocument(content).Meta.Name
+	}
+	conflicts, err := skill.FindConflicts(plan.Item.Name, frontName, exclude)
+	if err != nil {
+		plan.Problems = append(plan.Problems, "cannot check for name conflicts: "+err.Error())
+		return
+	}
+	plan.Conflicts = conflicts
+	if len(conflicts) == 0 {
+		return
+	}
+
+	switch installConflict {
+	case "":
+		if ui.CanPrompt() {
+			plan.Resolution = "asked: rename, replace or skip"
+		} else {
+			plan.Problems = append(plan.Problems, "name conflict; choose --on-conflict rename, replace or skip")
+		}
+	case conflictSkip:
+		plan.Resolution = "skipped"
+	case conflictReplace:
+		plan.Resolution = "the conflicting skills are removed"
+		for _, c := range conflicts {
+			if c.Skill.Scope != skill.ScopeUser {
+				plan.Problems = append(plan.Problems, c.Describe()+": sk only replaces skills it manages")
+			}
+		}
+	case conflictRename:
+		name, err := suggestName(plan.Item.Name, plan.Item.Resolved.Info.Owner, exclude)
+		if err != nil {
+			plan.Problems = append(plan.Problems, err.Error())
+			return
+		}
+		plan.Resolution = "installed as '" + name + "'"
+		plan.Target = skill.GetSkillDir(name)
+	}
+}
+
+// archiveLimitProblems reports the archive_limits a skill would exceed when
+// extracted.
+func archiveLimitProblems(remote *github.RemoteSkill) []string {
+	limits := config.GetArchiveLimits()
+	var problems []string
+	if len(remote.Files) > limits.MaxFiles {
+		problems = append(problems, fmt.Sprintf("%d files exceed archive_limits.max_files (%d)", len(remote.Files), limits.MaxFiles))
+	}
+	if total := remote.TotalSize(); total > limits.MaxUncompressedBytes {
+		problems = append(problems, fmt.Sprintf("%s exceeds archive_limits.max_uncompressed_bytes (%s)",
+			ui.FormatBytes(total), ui.FormatBytes(limits.MaxUncompressedBytes)))
+	}
+	for _, f := range remote.Files {
+		if f.Size > limits.MaxFileBytes {
+			problems = append(problems, fmt.Sprintf("%s (%s) exceeds archive_limits.max_file_bytes (%s)",
+				f.Path, ui.FormatBytes(f.Size), ui.FormatBytes(limits.MaxFileBytes)))
+		}
+	}
+	return problems
+}
+
+// skillFiles lists the files under dir, relative to it.
+func skillFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+func printInstallPlan(plan *installPlan) {
+	item := plan.Item
+	fmt.Println(styles.SkillNameStyle.Render(item.Name))
+
+	source := item.Source
+	if item.Resolved != nil && item.Resolved.Entry != nil {
+		source += " (registry " + item.Resolved.Entry.Registry + ")"
+	}
+	printDetail("Source:", source)
+	if item.Resolved != nil {
+		info := item.Resolved.Info
+		printDetail("Repository:", info.FullURL)
+		if info.Commit != "" {
+			printDetail("Commit:", info.Commit)
+		} else {
+			printDetail("Branch:", info.Branch)
+		}
+	}
+
+	for i, c := range plan.Candidates {
+		label := ""
+		if i == 0 {
+			label = "Tries:"
+		}
+		path := c.Path
+		if path == "" {
+			path = "(repository root)"
+		}
+		line := c.Ref + ":" + path
+		if c.When != "" {
+			line += styles.MutedStyle.Render(" " + c.When)
+		}
+		printDetail(label, line)
+	}
+
+	switch {
+	case plan.Remote != nil:
+		printDetail("Resolved:", plan.Remote.Branch+":"+plan.Remote.Path)
+	case errors.Is(plan.ResolveErr, config.ErrOffline):
+		printDetail("Resolved:", styles.MutedStyle.Render("not checked (--offline)"))
+	}
+	if plan.Target != "" {
+		printDetail("Target:", plan.Target)
+	}
+
+	if item.Skipped != "" {
+		printDetail("Skip:", item.Skipped+styles.MutedStyle.Render(" (use --force to replace)"))
+	}
+	if plan.Remote != nil && item.Skipped == "" {
+		printDetail("Writes:", fmt.Sprintf("%d file(s), %s", len(plan.Remote.Files)+1, ui.FormatBytes(plan.Remote.TotalSize())))
+		for _, f := range plan.Remote.Files {
+			fmt.Printf("  %11s  %s %-40s %s\n", "", styles.IconFile, f.Path, styles.MutedStyle.Render(ui.FormatBytes(f.Size)))
+		}
+		fmt.Printf("  %11s  %s %-40s %s\n", "", styles.IconFile, skill.MetaFile, styles.MutedStyle.Render("install record"))
+		if plan.Remote.Truncated {
+			fmt.Printf("  %11s  %s\n", "", styles.WarningStyle.Render("GitHub truncated the repository listing; some files may be missing."))
+		}
+	}
+	for i, c := range plan.Conflicts {
+		label := ""
+		if i == 0 {
+			label = "Conflicts:"
+		}
+		printDetail(label, styles.WarningStyle.Render(c.Describe()))
+	}
+	if plan.Resolution != "" {
+		printDetail("Resolve:", plan.Resolution)
+	}
+	if len(plan.Removes) > 0 {
+		printDetail("Removes:", fmt.Sprintf("%d file(s) of the existing install", len(plan.Removes)))
+		for _, f := range plan.Removes {
+			fmt.Printf("  %11s  %s %s\n", "", styles.IconFile, f)
+		}
+	}
+
+	for _, problem := range plan.Problems {
+		fmt.Printf("  %s %s\n", styles.ErrorStyle.Render(styles.IconCross), problem)
+	}
+	fmt.Println()
+}
+
+// printDryRunHeader announces that nothing will be changed.
+func printDryRunHeader() {
+	fmt.Println()
+	fmt.Println(styles.TitleStyle.Render(styles.IconInfo + " Dry run: nothing will be changed"))
+	fmt.Println()
+}
+
+// planUpdate plans reinstalling an installed skill from the source its
+// install record names, replacing the current files in place.
+func planUpdate(s skill.Skill) *installPlan {
+	name := filepath.Base(s.Path)
+	item := &batchItem{Source: name, Name: name}
+	meta, err := skill.ReadInstallMeta(s.Path)
+	if err != nil || meta == nil || meta.Source == "" {
+		item.Err = fmt.Errorf("no install record names its source; reinstall it with sk install to record one")
+		return planInstall(item, s.Path)
+	}
+	item.Source = meta.Source
+	item.Resolved, item.Err = resolveSource(meta.Source)
+	if item.Err == nil {
+		item.Err = checkPolicy(item.Resolved)
+	}
+	return planInstall(item, s.Path)
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/majiayu000/caude-skill-manager/internal/config"
+	"github.com/majiayu000/caude-skill-manager/internal/github"
+	"github.com/majiayu000/caude-skill-manager/internal/skill"
+)
+
+func TestPlanInstallListsReplacedFilesWithoutChangingThem(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	config.SetOffline(true)
+	defer config.SetOffline(false)
+
+	dir := skill.GetSkillDir("docx")
+	if err := os.MkdirAll(filepath.Join(dir, "scripts"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"SKILL.md", "scripts/convert.py"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	items := resolveBatch([]string{"anthropics/skills/docx"}, "")
+	if items[0].Skipped == "" {
+		t.Fatal("expected an installed skill to be skipped without --force")
+	}
+	plan := planInstall(items[0], dir)
+	if len(plan.Problems) != 0 || plan.Target != dir {
+		t.Fatalf("unexpected plan %#v", plan)
+	}
+	if want := []string{"SKILL.md", "scripts/convert.py"}; !reflect.DeepEqual(plan.Removes, want) {
+		t.Fatalf("got removes %q, want %q", plan.Removes, want)
+	}
+	if len(plan.Candidates) == 0 || plan.Candidates[0].Path != "docx" {
+		t.Fatalf("unexpected candidates %#v", plan.Candidates)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "scripts/convert.py")); err != nil {
+		t.Fatalf("planning changed the existing install: %v", err)
+	}
+}
+
+func TestPlanUpdateNeedsInstallRecord(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	plan := planUpdate(skill.Skill{Name: "docx", Path: t.TempDir()})
+	if len(plan.Problems) != 1 {
+		t.Fatalf("expected a missing install record to be reported, got %#v", plan.Problems)
+	}
+}
+
+func TestPlanUpdateTargetsTheSkillDirectory(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Chdir(t.TempDir())
+	config.SetOffline(true)
+	defer config.SetOffline(false)
+
+	// Installed with --name, so the directory differs from the front-matter name.
+	dir := skill.GetSkillDir("my-docx")
+	writeTestSkill(t, filepath.Dir(dir), "my-docx", "docx")
+	if err := skill.WriteInstallMeta(dir, &skill.InstallMeta{Source: "anthropics/skills/docx"}); err != nil {
+		t.Fatal(err)
+	}
+	s, err := skill.Get("docx")
+	if err != nil || s == nil {
+		t.Fatalf("expected docx to be found by its front-matter name: %v", err)
+	}
+
+	plan := planUpdate(*s)
+	if plan.Target != dir || plan.Item.Name != "my-docx" {
+		t.Fatalf("expected the plan to target %s, got %s (%s)", dir, plan.Target, plan.Item.Name)
+	}
+	if want := []string{skill.MetaFile, "SKILL.md"}; !reflect.DeepEqual(plan.Removes, want) {
+		t.Fatalf("got removes %q, want %q", plan.Removes, want)
+	}
+
+	// The skill being updated is not a conflict with itself.
+	plan.Remote = &github.RemoteSkill{}
+	planConflicts(plan, dir)
+	if len(plan.Conflicts) != 0 {
+		t.Fatalf("expected no conflicts, got %+v", plan.Conflicts)
+	}
+}
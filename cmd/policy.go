@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/majiayu000/caude-skill-manager/internal/policy"
+	"github.com/majiayu000/caude-skill-manager/pkg/styles"
+	"github.com/spf13/cobra"
+)
+
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Inspect the install trust policy",
+	Long: `Inspect the trust policy that restricts which owners, repositories and
+registry categories skills may be installed from.
+
+Rules come from the "policy" section of ~/.skrc and, when "policy_file" is
+set, from an org-wide policy file. Every policy source must allow a source.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var policyShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the effective trust policy",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		p, err := policy.Load()
+		if err != nil {
+			fmt.Println(styles.RenderError(err.Error()))
+			os.Exit(1)
+		}
+
+		fmt.Println()
+		fmt.Println(styles.TitleStyle.Render(styles.IconGear + " Trust Policy"))
+		fmt.Println()
+
+		if p.Empty() {
+			fmt.Println(styles.MutedStyle.Render("  No policy configured; installs from any source are allowed."))
+			fmt.Println()
+			return
+		}
+
+		for _, src := range p.Sources {
+			fmt.Printf("  %s %s\n", styles.SuccessStyle.Render(styles.IconFile), src.Origin)
+			printPolicyRule("allowed_owners", src.Rules.AllowedOwners)
+			printPolicyRule("denied_owners", src.Rules.DeniedOwners)
+			printPolicyRule("allowed_repos", src.Rules.AllowedRepos)
+			printPolicyRule("denied_repos", src.Rules.DeniedRepos)
+			printPolicyRule("allowed_categories", src.Rules.AllowedCategories)
+			printPolicyRule("denied_categories", src.Rules.DeniedCategories)
+			fmt.Println()
+		}
+	},
+}
+
+var policyCheckCmd = &cobra.Command{
+	Use:   "check <source>",
+	Short: "Check whether a source may be installed",
+	Example: `  sk policy check docx
+  sk policy check someone/repo/skill`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		resolved, err := resolveSource(args[0])
+		if err != nil {
+			printSourceError(err)
+			os.Exit(1)
+		}
+
+		p, err := policy.Load()
+		if err != nil {
+			fmt.Println(styles.RenderError(err.Error()))
+			os.Exit(1)
+		}
+
+		subject := resolved.policySubject()
+		fmt.Println()
+		printDetail("Owner:", subject.Owner)
+		printDetail("Repository:", subject.Repo)
+		if subject.Category != "" {
+			printDetail("Category:", subject.Category)
+		}
+		fmt.Println()
+
+		if err := p.Check(subject); err != nil {
+			fmt.Println(styles.RenderError(err.Error()))
+			if v, ok := err.(*policy.Violation); ok && v.Value != "" {
+				fmt.Println(styles.MutedStyle.Render(fmt.Sprintf("  Matched rule entry: %q", v.Value)))
+			}
+			fmt.Println()
+			os.Exit(1)
+		}
+		fmt.Println(styles.RenderSuccess("Allowed by policy"))
+		fmt.Println()
+	},
+}
+
+func printPolicyRule(name string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	fmt.Printf("    %s %s\n", styles.MutedStyle.Render(name+":"), strings.Join(values, ", "))
+}
+
+func init() {
+	policyCmd.AddCommand(policyShowCmd)
+	policyCmd.AddCommand(policyCheckCmd)
+	rootCmd.AddCommand(policyCmd)
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/majiayu000/caude-skill-manager/internal/github"
+	"github.com/majiayu000/caude-skill-manager/internal/registry"
+	"github.com/majiayu000/caude-skill-manager/internal/ui"
+)
+
+// registryProgress reports shard downloads on stderr while a registry
+// manifest with more than one shard is fetched.
+func registryProgress() func(registry.ShardProgress) {
+	var progress *ui.Progress
+	return func(p registry.ShardProgress) {
+		if p.Total <= 1 {
+			return
+		}
+		if p.Done >= p.Total {
+			if progress != nil {
+				progress.Finish()
+				progress = nil
+			}
+			return
+		}
+		if progress == nil {
+			progress = ui.NewProgress(os.Stderr, "Fetching "+p.Manifest)
+		}
+		noun := "shards"
+		if p.Reused > 0 {
+			noun += " (" + strconv.Itoa(p.Reused) + " unchanged)"
+		}
+		progress.SetItems(p.Done, p.Total, noun)
+		progress.SetBytes(p.Bytes, 0)
+	}
+}
+
+// archiveProgress reports repository archive downloads on stderr.
+func archiveProgress() func(github.DownloadProgress) {
+	downloads := make(map[string]*ui.Progress)
+	return func(p github.DownloadProgress) {
+		progress := downloads[p.Repo]
+		if p.Done {
+			if progress != nil {
+				progress.Finish()
+				delete(downloads, p.Repo)
+			}
+			return
+		}
+		if progress == nil {
+			progress = ui.NewProgress(os.Stderr, "Downloading "+p.Repo)
+			downloads[p.Repo] = progress
+		}
+		progress.SetBytes(p.Bytes, p.Total)
+	}
+}
@@ -0,0 +1,286 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/majiayu000/caude-skill-manager/internal/github"
+	"github.com/majiayu000/caude-skill-manager/internal/registry"
+	"github.com/majiayu000/caude-skill-manager/internal/ui"
+	"github.com/majiayu000/caude-skill-manager/pkg/styles"
+	"github.com/spf13/cobra"
+)
+
+var (
+	registryBuildOut       string
+	registryBuildGzip      bool
+	registryBuildShardSize int
+	registryBuildFeatured  int
+	registryBuildSignKey   string
+
+	registryValidateKey string
+)
+
+var registryCmd = &cobra.Command{
+	Use:   "registry",
+	Short: "Build and inspect skill registries",
+	Long: `Tools for publishing a skill registry, such as a private registry for an
+organisation. Point "registries" in ~/.skrc at the output to use it.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+var registryBuildCmd = &cobra.Command{
+	Use:   "build <owner/repo[@branch][=dir]>...",
+	Short: "Generate a registry from skill repositories",
+	Long: `Scan repositories for SKILL.md files and write a registry in the layout sk
+reads: registry.json with its manifest and shards, and a docs/ directory with
+the search index, categories, category index and featured list.
+
+Each source names the GitHub repository that install refs point at. With
+=dir, the local checkout at dir is scanned; otherwise the repository archive
+is downloaded from GitHub and its commit is recorded. Skills take their name,
+description, category and tags from SKILL.md front matter, and every record
+carries the content digest sk install verifies.`,
+	Example: `  sk registry build anthropics/skills --out ./registry
+  sk registry build my-org/skills@dev=./skills --gzip --sign-key key.txt`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		opts := registry.BuildOptions{
+			OutDir:    registryBuildOut,
+			ShardSize: registryBuildShardSize,
+			Featured:  registryBuildFeatured,
+			Gzip:      registryBuildGzip,
+		}
+		if registryBuildSignKey != "" {
+			data, err := os.ReadFile(registryBuildSignKey)
+			if err != nil {
+				fmt.Println(styles.RenderError("Failed to read signing key: " + err.Error()))
+				os.Exit(1)
+			}
+			key, err := registry.ParsePrivateKey(string(data))
+			if err != nil {
+				fmt.Println(styles.RenderError(err.Error()))
+				os.Exit(1)
+			}
+			opts.SigningKey = key
+		}
+
+		// Downloaded sources live in temp dirs until the build is written.
+		var tmpDirs []string
+		exit := func() {
+			for _, dir := range tmpDirs {
+				os.RemoveAll(dir)
+			}
+			os.Exit(1)
+		}
+
+		var skills []registry.Skill
+		for _, arg := range args {
+			src, err := parseBuildSource(arg)
+			if err != nil {
+				fmt.Println(styles.RenderError(err.Error()))
+				exit()
+			}
+			if src.Dir == "" {
+				tmpDir, err := downloadBuildSource(&src)
+				if tmpDir != "" {
+					tmpDirs = append(tmpDirs, tmpDir)
+				}
+				if err != nil {
+					exit()
+				}
+			} else if info, err := os.Stat(src.Dir); err != nil || !info.IsDir() {
+				fmt.Println(styles.RenderError(src.Dir + " is not a directory"))
+				exit()
+			}
+			found, err := registry.ScanSkills(src)
+			if err != nil {
+				fmt.Println(styles.RenderError(fmt.Sprintf("Failed to scan %s: %v", arg, err)))
+				exit()
+			}
+			fmt.Printf("  %s %s %s\n", styles.IconFolder, src.Repo, styles.MutedStyle.Render(fmt.Sprintf("(%d skills)", len(found))))
+			skills = append(skills, found...)
+		}
+
+		for _, dup := range duplicateSkillNames(skills) {
+			fmt.Println(styles.RenderWarning(fmt.Sprintf("Skill name %q is listed more than once; sk install %s resolves to the first", dup, dup)))
+		}
+
+		result, err := registry.Build(skills, opts)
+		for _, dir := range tmpDirs {
+			os.RemoveAll(dir)
+		}
+		if err != nil {
+			fmt.Println(styles.RenderError("Failed to build registry: " + err.Error()))
+			os.Exit(1)
+		}
+
+		fmt.Println()
+		fmt.Println(styles.RenderSuccess(fmt.Sprintf("Built registry with %d skills in %d categories", result.Skills, result.Categories)))
+		fmt.Println(styles.MutedStyle.Render(fmt.Sprintf("  %d files written to %s", len(result.Files), registryBuildOut)))
+		if opts.SigningKey == nil {
+			fmt.Println(styles.MutedStyle.Render("  Manifests are unsigned; pass --sign-key to sign them."))
+		}
+	},
+}
+
+var registryValidateCmd = &cobra.Command{
+	Use:   "validate [url|dir]",
+	Short: "Check a registry for publishing mistakes",
+	Long: `Walk a registry the way sk reads it and report every problem found:
+missing or undecodable artifacts, pointers without manifests, shards whose
+hashes, gzip data or counts disagree with their manifest, unknown schema
+versions, records that cannot be installed, malformed digests, duplicate names
+and category codes the client cannot map.
+
+Without an argument every configured registry is validated with its
+configured public key. With --public-key, manifest signatures are checked too.`,
+	Example: `  sk registry validate
+  sk registry validate ./registry --public-key "$(cat registry.pub.b64)"
+  sk registry validate https://skills.example.com/registry`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var sources []registry.Source
+		if len(args) == 1 {
+			sources = []registry.Source{{Name: args[0], BaseURL: args[0]}}
+		} else {
+			var err error
+			sources, err = registry.Sources()
+			if err != nil {
+				fmt.Println(styles.RenderError(err.Error()))
+				os.Exit(1)
+			}
+		}
+
+		failed := false
+		for _, src := range sources {
+			encodedKey := src.PublicKey
+			if registryValidateKey != "" {
+				encodedKey = registryValidateKey
+			}
+			var key ed25519.PublicKey
+			if encodedKey != "" {
+				var err error
+				if key, err = registry.ParsePublicKey(encodedKey); err != nil {
+					fmt.Println(styles.RenderError(err.Error()))
+					os.Exit(1)
+				}
+			}
+
+			report := registry.Validate(src.BaseURL, key)
+			printValidationReport(src.Name, report, key != nil)
+			if report.Errors() > 0 {
+				failed = true
+			}
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+func printValidationReport(name string, report *registry.ValidationReport, signed bool) {
+	fmt.Println()
+	fmt.Println(styles.TitleStyle.Render(styles.IconGear + " Registry " + name))
+	if report.BaseURL != name {
+		fmt.Println(styles.MutedStyle.Render("  " + report.BaseURL))
+	}
+	fmt.Println()
+
+	for _, issue := range report.Issues {
+		line := issue.Artifact + ": " + issue.Message
+		if issue.Level == registry.LevelError {
+			fmt.Println("  " + styles.RenderError(line))
+		} else {
+			fmt.Println("  " + styles.RenderWarning(line))
+		}
+	}
+	if len(report.Issues) > 0 {
+		fmt.Println()
+	}
+
+	summary := fmt.Sprintf("%d skills, %d search entries, %d categories; %d artifacts checked",
+		report.Skills, report.SearchEntries, report.Categories, report.Artifacts)
+	if !signed {
+		summary += " (signatures not checked)"
+	}
+	switch {
+	case report.Errors() > 0:
+		fmt.Println(styles.RenderError(fmt.Sprintf("%d errors, %d warnings", report.Errors(), report.Warnings())))
+	case report.Warnings() > 0:
+		fmt.Println(styles.RenderWarning(fmt.Sprintf("Valid with %d warnings", report.Warnings())))
+	default:
+		fmt.Println(styles.RenderSuccess("Valid"))
+	}
+	fmt.Println(styles.MutedStyle.Render("  " + summary))
+}
+
+// parseBuildSource parses owner/repo[@branch][=dir].
+func parseBuildSource(spec string) (registry.BuildSource, error) {
+	var src registry.BuildSource
+	ref, dir, _ := strings.Cut(spec, "=")
+	ref, branch, _ := strings.Cut(ref, "@")
+	parts := strings.Split(strings.Trim(ref, "/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return src, fmt.Errorf("invalid source %q: expected owner/repo[@branch][=dir]", spec)
+	}
+	src.Repo = parts[0] + "/" + parts[1]
+	src.Branch = branch
+	src.Dir = dir
+	return src, nil
+}
+
+// downloadBuildSource downloads the repository archive for src into a
+// temporary directory, which the caller removes, and points src at it. The
+// spinner has already reported any error.
+func downloadBuildSource(src *registry.BuildSource) (string, error) {
+	tmpDir, err := os.MkdirTemp("", "sk-registry-build-*")
+	if err != nil {
+		fmt.Println(styles.RenderError(err.Error()))
+		return "", err
+	}
+
+	owner, repo, _ := strings.Cut(src.Repo, "/")
+	info := &github.RepoInfo{Owner: owner, Repo: repo, Branch: src.Branch}
+	if info.Branch == "" {
+		info.Branch = "main"
+	}
+	err = ui.RunWithSpinner("Downloading "+src.Repo+"...", func() (string, error) {
+		branch, commit, err := github.DownloadRepoTo(info, tmpDir)
+		if err != nil {
+			return "", err
+		}
+		src.Branch, src.Commit = branch, commit
+		return styles.RenderSuccess("Downloaded " + src.Repo), nil
+	})
+	src.Dir = tmpDir
+	return tmpDir, err
+}
+
+func duplicateSkillNames(skills []registry.Skill) []string {
+	seen := make(map[string]int)
+	var dups []string
+	for _, s := range skills {
+		seen[s.Name]++
+		if seen[s.Name] == 2 {
+			dups = append(dups, s.Name)
+		}
+	}
+	return dups
+}
+
+func init() {
+	registryBuildCmd.Flags().StringVarP(&registryBuildOut, "out", "o", "registry", "Output directory")
+	registryBuildCmd.Flags().BoolVar(&registryBuildGzip, "gzip", false, "Also write gzip-compressed shards")
+	registryBuildCmd.Flags().IntVar(&registryBuildShardSize, "shard-size", 500, "Skills per shard")
+	registryBuildCmd.Flags().IntVar(&registryBuildFeatured, "featured", 100, "Number of skills in featured.json")
+	registryBuildCmd.Flags().StringVar(&registryBuildSignKey, "sign-key", "", "File holding a base64 ed25519 private key to sign manifests")
+	registryValidateCmd.Flags().StringVar(&registryValidateKey, "public-key", "", "Base64 ed25519 public key to check manifest signatures against")
+	registryCmd.AddCommand(registryBuildCmd)
+	registryCmd.AddCommand(registryValidateCmd)
+	rootCmd.AddCommand(registryCmd)
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import "testing"
+
+func TestParseBuildSource(t *testing.T) {
+	tests := []struct {
+		spec, repo, branch, dir string
+	}{
+		{"corp/skills", "corp/skills", "", ""},
+		{"corp/skills@dev", "corp/skills", "dev", ""},
+		{"corp/skills=./checkout", "corp/skills", "", "./checkout"},
+		{"corp/skills@dev=/src/skills", "corp/skills", "dev", "/src/skills"},
+	}
+	for _, tt := range tests {
+		src, err := parseBuildSource(tt.spec)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.spec, err)
+		}
+		if src.Repo != tt.repo || src.Branch != tt.branch || src.Dir != tt.dir {
+			t.Fatalf("%s: got %#v", tt.spec, src)
+		}
+	}
+
+	for _, spec := range []string{"skills", "corp/skills/extra", "/skills=dir"} {
+		if _, err := parseBuildSource(spec); err == nil {
+			t.Fatalf("expected %q to be rejected", spec)
+		}
+	}
+}
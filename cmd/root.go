@@ -5,6 +5,9 @@ import (
 	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/majiayu000/caude-skill-manager/internal/config"
+	"github.com/majiayu000/caude-skill-manager/internal/github"
+	"github.com/majiayu000/caude-skill-manager/internal/registry"
 	"github.com/majiayu000/caude-skill-manager/pkg/styles"
 )
 
@@ -27,6 +30,10 @@ var rootCmd = &cobra.Command{
   sk list
   sk uninstall my-skill
 `,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		offline, _ := cmd.Flags().GetBool("offline")
+		config.SetOffline(offline)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		cmd.Help()
 	},
@@ -42,4 +49,7 @@ func Execute() {
 
 func init() {
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
+	rootCmd.PersistentFlags().Bool("offline", false, "Never use the network; serve registry data from the cache, however old")
+	registry.SetProgressHandler(registryProgress())
+	github.SetDownloadProgressHandler(archiveProgress())
 }
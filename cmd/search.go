@@ -1,9 +1,11 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
+	"github.com/majiayu000/caude-skill-manager/internal/config"
 	"github.com/majiayu000/caude-skill-manager/internal/registry"
 	"github.com/majiayu000/caude-skill-manager/pkg/styles"
 	"github.com/spf13/cobra"
@@ -49,7 +51,7 @@ func showFeaturedSkills() {
 	fmt.Println(styles.TitleStyle.Render(styles.IconStar + " Popular Skills (Top 100)"))
 	fmt.Println()
 
-	featured, err := registry.FetchFeatured()
+	featured, source, err := registry.FetchFeaturedWithSource()
 	if err != nil {
 		fmt.Println(styles.WarningStyle.Render("Could not fetch featured skills: " + err.Error()))
 		fmt.Println(styles.MutedStyle.Render("Showing fallback list..."))
@@ -57,6 +59,7 @@ func showFeaturedSkills() {
 		showFallbackSkills()
 		return
 	}
+	printRegistrySource(source)
 
 	for i, skill := range featured.Skills {
 		starStr := ""
@@ -126,7 +129,7 @@ func showByCategory(category string) {
 	)
 
 	skills, source, err := registry.GetByCategoryWithSource(category)
-	if err != nil {
+	if err = partialRegistryError(err); err != nil {
 		fmt.Println(styles.RenderError("Failed to fetch category: " + err.Error()))
 		showAvailableCategories()
 		return
@@ -156,6 +159,7 @@ func showByCategory(category string) {
 		if skill.Stars > 0 {
 			fmt.Printf("  %s%d", styles.MutedStyle.Render(styles.IconStar), skill.Stars)
 		}
+		printRegistryBadge(skill.Registry)
 		fmt.Println()
 
 		if skill.Description != "" {
@@ -187,10 +191,14 @@ func showByCategory(category string) {
 }
 
 func showAvailableCategories() {
-	idx, err := registry.FetchCategoryIndex()
+	idx, source, err := registry.FetchCategoryIndexWithSource()
 	if err != nil {
 		return
 	}
+	// The listing follows other output, so only an outdated index is worth a note.
+	if source == registry.RegistrySourceStale {
+		printRegistrySource(source)
+	}
 
 	fmt.Println(styles.MutedStyle.Render("Available categories:"))
 	for _, cat := range idx.Categories {
@@ -212,7 +220,7 @@ func searchRegistry(keyword string) {
 	)
 
 	skills, source, err := registry.SearchWithSource(keyword)
-	if err != nil {
+	if err = partialRegistryError(err); err != nil {
 		fmt.Println(styles.RenderError("Search failed: " + err.Error()))
 		return
 	}
@@ -260,6 +268,7 @@ func searchRegistry(keyword string) {
 		if skill.Featured {
 			fmt.Printf(" %s", styles.BadgeStyle.Render("featured"))
 		}
+		printRegistryBadge(skill.Registry)
 
 		fmt.Println()
 
@@ -302,8 +311,35 @@ func printRegistrySource(source registry.RegistrySource) {
 	if message == "" {
 		return
 	}
-	fmt.Println(styles.MutedStyle.Render(message))
+	if source == registry.RegistrySourceStale {
+		fmt.Println(styles.RenderWarning(message))
+	} else {
+		fmt.Println(styles.MutedStyle.Render(message))
+	}
+	fmt.Println()
+}
+
+// partialRegistryError prints a warning for each registry that could not be
+// read while others returned results, and returns err otherwise.
+func partialRegistryError(err error) error {
+	var partial *registry.PartialError
+	if !errors.As(err, &partial) {
+		return err
+	}
+	for _, failure := range partial.Failures {
+		fmt.Println(styles.RenderWarning(fmt.Sprintf("Registry '%s' unavailable: %s", failure.Registry, failure.Err)))
+	}
 	fmt.Println()
+	return nil
+}
+
+// printRegistryBadge labels a result with its registry when several
+// registries are configured.
+func printRegistryBadge(name string) {
+	if name == "" || name == config.DefaultRegistryName {
+		return
+	}
+	fmt.Printf(" %s", styles.MutedStyle.Render("["+name+"]"))
 }
 
 func registrySourceMessage(source registry.RegistrySource) string {
@@ -312,6 +348,13 @@ func registrySourceMessage(source registry.RegistrySource) string {
 		return "Using remote registry data..."
 	case registry.RegistrySourceCache:
 		return "Using cached registry data..."
+	case registry.RegistrySourceLocal:
+		return "Using local registry data..."
+	case registry.RegistrySourceStale:
+		if config.IsOffline() {
+			return "Offline: using cached registry data older than registry_ttl_hours..."
+		}
+		return "Registry unreachable: using cached registry data older than registry_ttl_hours..."
 	default:
 		return ""
 	}
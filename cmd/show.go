@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/majiayu000/caude-skill-manager/internal/github"
+	"github.com/majiayu000/caude-skill-manager/internal/skill"
+	"github.com/majiayu000/caude-skill-manager/internal/ui"
+	"github.com/majiayu000/caude-skill-manager/pkg/styles"
+	"github.com/spf13/cobra"
+)
+
+var showPlain bool // print raw markdown without styling or paging
+
+var showCmd = &cobra.Command{
+	Use:   "show <skill-name> [file]",
+	Short: "Render a skill's SKILL.md or bundled docs",
+	Long: `Render SKILL.md, or another markdown file bundled with the skill, as
+styled terminal text.
+
+Installed skills are read from the skills directory; other names are looked up
+in the registry or on GitHub. Long output opens in a pager. When stdout is not
+a terminal, or with --plain, the raw markdown is printed instead.`,
+	Example: `  sk show docx
+  sk show docx reference.md
+  sk show anthropics/skills/pdf --plain | less`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		file := "SKILL.md"
+		if len(args) > 1 {
+			var err error
+			if file, err = cleanSkillFilePath(args[1]); err != nil {
+				fmt.Println(styles.RenderError(err.Error()))
+				os.Exit(1)
+			}
+		}
+
+		readFile, err := skillFileReader(name)
+		if err != nil {
+			printSourceError(err)
+			os.Exit(1)
+		}
+
+		content, err := readFile(file)
+		if err != nil {
+			fmt.Println(styles.RenderError(fmt.Sprintf("Failed to read %s: %s", file, err.Error())))
+			os.Exit(1)
+		}
+
+		if showPlain || !ui.IsTerminal() {
+			fmt.Print(string(content))
+			return
+		}
+
+		rendered := renderSkillMarkdown(name, file, content, ui.TerminalWidth())
+		if err := ui.Page(name+" "+styles.IconArrow+" "+file, rendered); err != nil {
+			fmt.Println(styles.RenderError(err.Error()))
+			os.Exit(1)
+		}
+	},
+}
+
+// skillFileReader returns a function that reads files from an installed
+// skill, or from GitHub when the skill is not installed.
+func skillFileReader(name string) (func(string) ([]byte, error), error) {
+	s, err := skill.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	if s != nil {
+		root := s.Path
+		return func(rel string) ([]byte, error) {
+			return os.ReadFile(filepath.Join(root, filepath.FromSlash(rel)))
+		}, nil
+	}
+
+	resolved, err := resolveSource(name)
+	if err != nil {
+		return nil, err
+	}
+	remote, err := github.ResolveRemoteSkill(resolved.Info)
+	if err != nil {
+		return nil, err
+	}
+	return func(rel string) ([]byte, error) {
+		for _, f := range remote.Files {
+			if f.Path == rel {
+				return remote.ReadFile(rel)
+			}
+		}
+		return nil, os.ErrNotExist
+	}, nil
+}
+
+// cleanSkillFilePath validates a file argument relative to a skill directory.
+func cleanSkillFilePath(file string) (string, error) {
+	cleaned := path.Clean(strings.ReplaceAll(file, "\\", "/"))
+	if path.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("file must be inside the skill directory: %s", file)
+	}
+	return cleaned, nil
+}
+
+func renderSkillMarkdown(name, file string, content []byte, width int) string {
+	var b strings.Builder
+	body := string(content)
+
+	if file == "SKILL.md" {
+		doc := skill.ParseDocument(content)
+		if len(doc.FrontMatter) > 0 {
+			for _, field := range doc.FrontMatter {
+				b.WriteString(fmt.Sprintf("%s %s\n", styles.MutedStyle.Render(field.Key+":"), field.Value))
+			}
+			b.WriteString("\n")
+		}
+		body = doc.Body
+	}
+
+	b.WriteString(ui.RenderMarkdown(body, width))
+
+	if targets := referencedDocs(file, body); len(targets) > 0 {
+		b.WriteString("\n")
+		b.WriteString(styles.TableHeaderStyle.Render("Referenced docs"))
+		b.WriteString("\n\n")
+		for _, target := range targets {
+			b.WriteString(fmt.Sprintf("  %s %s  %s\n",
+				styles.IconFile,
+				target,
+				styles.MutedStyle.Render("sk show "+name+" "+target),
+			))
+		}
+	}
+	return b.String()
+}
+
+// referencedDocs returns the markdown files that body, the content of file,
+// links to, relative to the skill directory. Links that leave the skill
+// directory are left out, since sk show cannot open them.
+func referencedDocs(file, body string) []string {
+	base := path.Dir(file)
+	var targets []string
+	for _, link := range ui.MarkdownLinks(body) {
+		if target, err := cleanSkillFilePath(path.Join(base, link)); err == nil {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+func init() {
+	showCmd.Flags().BoolVar(&showPlain, "plain", false, "Print raw markdown without styling or paging")
+	rootCmd.AddCommand(showCmd)
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCleanSkillFilePath(t *testing.T) {
+	valid := map[string]string{
+		"reference.md":     "reference.md",
+		"./docs/forms.md":  "docs/forms.md",
+		"docs/../guide.md": "guide.md",
+		"docs\\windows.md": "docs/windows.md",
+	}
+	for input, want := range valid {
+		got, err := cleanSkillFilePath(input)
+		if err != nil {
+			t.Fatalf("cleanSkillFilePath(%q) returned error: %v", input, err)
+		}
+		if got != want {
+			t.Fatalf("cleanSkillFilePath(%q) = %q, want %q", input, got, want)
+		}
+	}
+
+	for _, input := range []string{"../secret.md", "/etc/passwd", "docs/../../x.md"} {
+		if _, err := cleanSkillFilePath(input); err == nil {
+			t.Fatalf("expected %q to be rejected", input)
+		}
+	}
+}
+
+func TestReferencedDocsSkipsLinksOutsideTheSkill(t *testing.T) {
+	body := "See [forms](docs/forms.md), [guide](../shared/GUIDE.md) and [back](../SKILL.md)."
+	got := referencedDocs("docs/reference.md", body)
+	if want := []string{"docs/docs/forms.md", "shared/GUIDE.md", "SKILL.md"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	if got := referencedDocs("SKILL.md", body); !reflect.DeepEqual(got, []string{"docs/forms.md"}) {
+		t.Fatalf("expected only the link inside the skill, got %q", got)
+	}
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/majiayu000/caude-skill-manager/internal/config"
+	"github.com/majiayu000/caude-skill-manager/internal/github"
+	"github.com/majiayu000/caude-skill-manager/internal/integrity"
+	"github.com/majiayu000/caude-skill-manager/internal/policy"
+	"github.com/majiayu000/caude-skill-manager/internal/registry"
+	"github.com/majiayu000/caude-skill-manager/internal/skill"
+	"github.com/majiayu000/caude-skill-manager/pkg/styles"
+)
+
+// resolvedSource is a skill source resolved to a GitHub location.
+type resolvedSource struct {
+	Info   *github.RepoInfo
+	Entry  *registry.Skill // nil when the source was a direct GitHub ref
+	Source registry.RegistrySource
+}
+
+// sourceError reports that a source is neither a GitHub ref nor a registry name.
+type sourceError struct {
+	ParseErr    error
+	RegistryErr error
+}
+
+func (e *sourceError) Error() string {
+	if e.RegistryErr == nil {
+		return e.ParseErr.Error()
+	}
+	return fmt.Sprintf("%s (registry lookup: %s)", e.ParseErr, e.RegistryErr)
+}
+
+// resolveSource parses a GitHub ref, falling back to a registry name lookup.
+func resolveSource(source string) (*resolvedSource, error) {
+	info, err := github.ParseGitHubURL(source)
+	if err == nil {
+		return &resolvedSource{Info: info}, nil
+	}
+
+	entry, regSource, regErr := registry.Lookup(source)
+	if regErr = partialRegistryError(regErr); regErr != nil {
+		return nil, &sourceError{ParseErr: err, RegistryErr: regErr}
+	}
+
+	info, err = github.ParseGitHubURL(entry.Install)
+	if err != nil {
+		return nil, &sourceError{ParseErr: err}
+	}
+	// Download the commit the registry digest was computed at, if known.
+	info.Commit = entry.Commit
+	return &resolvedSource{Info: info, Entry: entry, Source: regSource}, nil
+}
+
+// printSourceError prints a resolveSource failure, listing the registry
+// lookup error separately when both resolution paths failed.
+func printSourceError(err error) {
+	if srcErr, ok := err.(*sourceError); ok && srcErr.RegistryErr != nil {
+		fmt.Println(styles.RenderError(srcErr.ParseErr.Error()))
+		fmt.Println(styles.MutedStyle.Render("Also tried registry lookup: " + srcErr.RegistryErr.Error()))
+		return
+	}
+	fmt.Println(styles.RenderError(err.Error()))
+}
+
+// policySubject returns the identity the trust policy checks for a source.
+// The category is taken only from a registry with a pinned public key: any
+// other registry could label a skill with whatever category a policy allows.
+func (r *resolvedSource) policySubject() policy.Subject {
+	subject := policy.Subject{
+		Owner: r.Info.Owner,
+		Repo:  r.Info.Owner + "/" + r.Info.Repo,
+	}
+	if r.Entry != nil && r.Entry.Registry != "" {
+		if src, err := registry.SourceByName(r.Entry.Registry); err == nil && src.PublicKey != "" {
+			subject.Category = r.Entry.Category
+		}
+	}
+	return subject
+}
+
+// checkPolicy loads the trust policy and checks a resolved source against it.
+func checkPolicy(r *resolvedSource) error {
+	p, err := policy.Load()
+	if err != nil {
+		return err
+	}
+	return p.Check(r.policySubject())
+}
+
+// verifyIntegrity computes the digest of a staged skill and compares it with
+// the registry record, if the record has one. It returns the computed digest.
+// A mismatch is an error in "refuse" integrity mode and is returned as
+// warning in "warn" mode.
+func verifyIntegrity(r *resolvedSource, dir string) (digest string, warning, err error) {
+	if r.Entry == nil || r.Entry.Digest == "" {
+		digest, err = integrity.DigestDir(dir, skill.MetaFile)
+		return digest, nil, err
+	}
+
+	digest, err = integrity.Verify(dir, r.Entry.Digest, skill.MetaFile)
+	var mismatch *integrity.MismatchError
+	if errors.As(err, &mismatch) && config.GetIntegrityMode() == "warn" {
+		return digest, mismatch, nil
+	}
+	return digest, nil, err
+}
+
+// installMeta builds the install record for a staged skill.
+func installMeta(source string, r *resolvedSource, digest string) *skill.InstallMeta {
+	meta := &skill.InstallMeta{
+		Source:      source,
+		URL:         r.Info.FullURL,
+		Commit:      r.Info.Commit,
+		Digest:      digest,
+		InstalledAt: time.Now(),
+	}
+	if r.Entry != nil {
+		meta.Registry = r.Entry.Registry
+		meta.RegistryDigest = r.Entry.Digest
+	}
+	return meta
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/majiayu000/caude-skill-manager/internal/github"
+	"github.com/majiayu000/caude-skill-manager/internal/registry"
+)
+
+func TestPolicySubjectTrustsCategoryOnlyFromPinnedRegistries(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	registries := `{"registries":[
+  {"name":"internal","url":"https://skills.example.com","public_key":"MCowBQYDK2VwAyEA"},
+  {"name":"public","url":"github"}
+]}`
+	if err := os.WriteFile(filepath.Join(home, ".skrc"), []byte(registries), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for registryName, want := range map[string]string{"internal": "official", "public": "", "": ""} {
+		r := &resolvedSource{
+			Info:  &github.RepoInfo{Owner: "someone", Repo: "skills"},
+			Entry: &registry.Skill{Name: "pdf", Category: "official", Registry: registryName},
+		}
+		if got := r.policySubject().Category; got != want {
+			t.Fatalf("registry %q: category %q, want %q", registryName, got, want)
+		}
+	}
+}
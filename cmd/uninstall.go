@@ -10,7 +10,10 @@ import (
 	"github.com/majiayu000/caude-skill-manager/pkg/styles"
 )
 
-var uninstallForce bool
+var (
+	uninstallForce  bool
+	uninstallDryRun bool
+)
 
 var uninstallCmd = &cobra.Command{
 	Use:     "uninstall <skill-name>",
@@ -34,6 +37,28 @@ var uninstallCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
+		if uninstallDryRun {
+			files, err := skillFiles(s.Path)
+			printDryRunHeader()
+			fmt.Println(styles.SkillNameStyle.Render(name))
+			printDetail("Directory:", s.Path)
+			if meta, _ := skill.ReadInstallMeta(s.Path); meta != nil && meta.Source != "" {
+				printDetail("Source:", meta.Source)
+			}
+			printDetail("Removes:", fmt.Sprintf("%d file(s)", len(files)))
+			for _, f := range files {
+				fmt.Printf("  %11s  %s %s\n", "", styles.IconFile, f)
+			}
+			if err != nil {
+				fmt.Printf("  %s %s\n", styles.ErrorStyle.Render(styles.IconCross), "cannot list every file: "+err.Error())
+			}
+			if !uninstallForce {
+				printDetail("Confirm:", "asked before removing (skip with --force)")
+			}
+			fmt.Println()
+			return
+		}
+
 		// Confirm unless --force
 		if !uninstallForce {
 			var confirm bool
@@ -65,5 +90,6 @@ var uninstallCmd = &cobra.Command{
 
 func init() {
 	uninstallCmd.Flags().BoolVarP(&uninstallForce, "force", "f", false, "Skip confirmation")
+	uninstallCmd.Flags().BoolVar(&uninstallDryRun, "dry-run", false, "Show the files that would be removed without removing them")
 	rootCmd.AddCommand(uninstallCmd)
 }
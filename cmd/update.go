@@ -2,12 +2,15 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/majiayu000/caude-skill-manager/internal/skill"
 	"github.com/majiayu000/caude-skill-manager/pkg/styles"
 	"github.com/spf13/cobra"
 )
 
+var updateDryRun bool
+
 var updateCmd = &cobra.Command{
 	Use:     "update [skill-name]",
 	Aliases: []string{"up", "upgrade"},
@@ -16,7 +19,8 @@ var updateCmd = &cobra.Command{
 
 If no skill name is provided, all skills will be updated.`,
 	Example: `  sk update           # Update all skills
-  sk update my-skill  # Update specific skill`,
+  sk update my-skill  # Update specific skill
+  sk update --dry-run # Show what updating would change`,
 	Run: func(cmd *cobra.Command, args []string) {
 		skills, err := skill.List()
 		if err != nil {
@@ -34,20 +38,40 @@ If no skill name is provided, all skills will be updated.`,
 			target = args[0]
 		}
 
+		// Match the name as every other command does: the directory first,
+		// then a unique front-matter name.
+		var only *skill.Skill
 		if target != "" {
-			found := false
-			for _, s := range skills {
-				if s.Name == target {
-					found = true
-					break
-				}
+			only, err = skill.Get(target)
+			if err != nil {
+				fmt.Println(styles.RenderError(err.Error()))
+				return
 			}
-			if !found {
+			if only == nil {
 				fmt.Println(styles.RenderError(fmt.Sprintf("Skill '%s' is not installed.", target)))
 				return
 			}
 		}
 
+		if updateDryRun {
+			printDryRunHeader()
+			fmt.Println(styles.MutedStyle.Render("Updates are not applied yet; each plan shows what 'sk install --force <source>' would do."))
+			fmt.Println()
+			ok := true
+			for _, s := range skills {
+				if only != nil && s.Path != only.Path {
+					continue
+				}
+				plan := planUpdate(s)
+				printInstallPlan(plan)
+				ok = ok && len(plan.Problems) == 0
+			}
+			if !ok {
+				os.Exit(1)
+			}
+			return
+		}
+
 		fmt.Println()
 		fmt.Println(styles.TitleStyle.Render(styles.IconSync + " Update not implemented"))
 		fmt.Println()
@@ -69,5 +93,6 @@ If no skill name is provided, all skills will be updated.`,
 }
 
 func init() {
+	updateCmd.Flags().BoolVar(&updateDryRun, "dry-run", false, "Show what updating would download and replace without changing anything")
 	rootCmd.AddCommand(updateCmd)
 }
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/majiayu000/caude-skill-manager/internal/integrity"
+	"github.com/majiayu000/caude-skill-manager/internal/skill"
+	"github.com/majiayu000/caude-skill-manager/pkg/styles"
+	"github.com/spf13/cobra"
+)
+
+// verifyResult is the outcome of re-checking one installed skill.
+type verifyResult struct {
+	State  string // "ok", "modified", "unrecorded" or "error"
+	Detail string
+}
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify [skill-name]",
+	Short: "Check installed skills against their install records",
+	Long: `Recompute the content digest of installed skills and compare it with the
+digest recorded when they were installed.
+
+A modified skill has changed on disk since install. Skills installed before
+sk recorded digests have no install record and are reported but not failed.`,
+	Example: `  sk verify           # Verify all skills
+  sk verify pdf       # Verify one skill`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var skills []skill.Skill
+		if len(args) == 1 {
+			s, err := skill.Get(args[0])
+			if err != nil {
+				fmt.Println(styles.RenderError("Failed to get skill: " + err.Error()))
+				os.Exit(1)
+			}
+			if s == nil {
+				fmt.Println(styles.RenderError(fmt.Sprintf("Skill '%s' is not installed.", args[0])))
+				os.Exit(1)
+			}
+			skills = append(skills, *s)
+		} else {
+			list, err := skill.List()
+			if err != nil {
+				fmt.Println(styles.RenderError("Failed to list skills: " + err.Error()))
+				os.Exit(1)
+			}
+			skills = list
+		}
+
+		if len(skills) == 0 {
+			fmt.Println(styles.RenderWarning("No skills installed."))
+			return
+		}
+
+		fmt.Println()
+		fmt.Println(styles.TitleStyle.Render(styles.IconCheck + " Verify Skills"))
+		fmt.Println()
+
+		failed := 0
+		for _, s := range skills {
+			result := verifySkill(s.Path)
+			icon, style := styles.IconCheck, styles.SuccessStyle
+			switch result.State {
+			case "unrecorded":
+				icon, style = styles.IconWarning, styles.WarningStyle
+			case "modified", "error":
+				icon, style = styles.IconCross, styles.ErrorStyle
+				failed++
+			}
+			fmt.Printf("  %s %s  %s\n", style.Render(icon), styles.SkillNameStyle.Render(s.Name), styles.MutedStyle.Render(result.State))
+			if result.Detail != "" {
+				fmt.Printf("    %s %s\n", styles.MutedStyle.Render(styles.IconArrow), result.Detail)
+			}
+		}
+		fmt.Println()
+
+		if failed > 0 {
+			fmt.Println(styles.RenderError(fmt.Sprintf("%d skill(s) failed verification. Reinstall with 'sk install --force <source>'.", failed)))
+			os.Exit(1)
+		}
+	},
+}
+
+// verifySkill compares a skill directory with its install record.
+func verifySkill(dir string) verifyResult {
+	meta, err := skill.ReadInstallMeta(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return verifyResult{State: "unrecorded", Detail: "no install record; reinstall to start tracking its digest"}
+	}
+	if err != nil {
+		return verifyResult{State: "error", Detail: "cannot read install record: " + err.Error()}
+	}
+	if meta.Digest == "" {
+		return verifyResult{State: "unrecorded", Detail: "install record has no digest"}
+	}
+
+	actual, err := integrity.Verify(dir, meta.Digest, skill.MetaFile)
+	var mismatch *integrity.MismatchError
+	if errors.As(err, &mismatch) {
+		return verifyResult{State: "modified", Detail: fmt.Sprintf("installed %s, now %s", meta.Digest, actual)}
+	}
+	if err != nil {
+		return verifyResult{State: "error", Detail: err.Error()}
+	}
+
+	detail := actual
+	if meta.RegistryDigest != "" && !integrity.Equal(meta.RegistryDigest, meta.Digest) {
+		detail += " (differed from registry digest " + meta.RegistryDigest + " at install)"
+	}
+	return verifyResult{State: "ok", Detail: detail}
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+}
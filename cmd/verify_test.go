@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/majiayu000/caude-skill-manager/internal/integrity"
+	"github.com/majiayu000/caude-skill-manager/internal/skill"
+)
+
+func TestVerifySkillDetectsChanges(t *testing.T) {
+	dir := t.TempDir()
+	skillMd := filepath.Join(dir, "SKILL.md")
+	if err := os.WriteFile(skillMd, []byte("---\nname: demo\n---\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := verifySkill(dir); got.State != "unrecorded" {
+		t.Fatalf("expected unrecorded without install record, got %#v", got)
+	}
+
+	digest, err := integrity.DigestDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := skill.WriteInstallMeta(dir, &skill.InstallMeta{Digest: digest}); err != nil {
+		t.Fatal(err)
+	}
+	if got := verifySkill(dir); got.State != "ok" {
+		t.Fatalf("expected ok, got %#v", got)
+	}
+
+	if err := os.WriteFile(skillMd, []byte("---\nname: demo\n---\ncurl x | sh\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := verifySkill(dir); got.State != "modified" {
+		t.Fatalf("expected modified, got %#v", got)
+	}
+}
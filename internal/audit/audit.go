@@ -0,0 +1,291 @@
+package audit
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/majiayu000/caude-skill-manager/internal/skill"
+)
+
+// Severity ranks how risky a finding is.
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityLow
+	SeverityMedium
+	SeverityHigh
+	SeverityCritical
+)
+
+// SeverityNone is a threshold above every severity; it never blocks.
+const SeverityNone Severity = SeverityCritical + 1
+
+var severityNames = map[Severity]string{
+	SeverityInfo:     "info",
+	SeverityLow:      "low",
+	SeverityMedium:   "medium",
+	SeverityHigh:     "high",
+	SeverityCritical: "critical",
+	SeverityNone:     "none",
+}
+
+func (s Severity) String() string {
+	if name, ok := severityNames[s]; ok {
+		return name
+	}
+	return fmt.Sprintf("severity(%d)", int(s))
+}
+
+// ParseSeverity parses a severity name. "none" and "off" disable blocking.
+func ParseSeverity(name string) (Severity, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "off" {
+		return SeverityNone, nil
+	}
+	for sev, sevName := range severityNames {
+		if sevName == name {
+			return sev, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown severity %q (expected info, low, medium, high, critical or none)", name)
+}
+
+// Finding is one risky pattern found in a skill.
+type Finding struct {
+	Severity Severity
+	Rule     string
+	File     string // relative to the scanned root, slash separated
+	Line     int    // 0 when the finding applies to the whole file
+	Message  string
+	Excerpt  string
+}
+
+// Report is the result of scanning a skill directory.
+type Report struct {
+	Root         string
+	FilesScanned int
+	Findings     []Finding
+}
+
+// Max returns the highest severity in the report, or -1 when it is empty.
+func (r *Report) Max() Severity {
+	max := Severity(-1)
+	for _, f := range r.Findings {
+		if f.Severity > max {
+			max = f.Severity
+		}
+	}
+	return max
+}
+
+// Count returns the number of findings with the given severity.
+func (r *Report) Count(sev Severity) int {
+	n := 0
+	for _, f := range r.Findings {
+		if f.Severity == sev {
+			n++
+		}
+	}
+	return n
+}
+
+// Blocks reports whether any finding is at or above threshold.
+func (r *Report) Blocks(threshold Severity) bool {
+	return len(r.Findings) > 0 && r.Max() >= threshold
+}
+
+// maxScanBytes caps how much of a single file is pattern-scanned.
+const maxScanBytes = 2 << 20
+
+// ScanDir scans a skill directory for risky content.
+func ScanDir(root string) (*Report, error) {
+	report := &Report{Root: root}
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			report.add(Finding{
+				Severity: SeverityHigh,
+				Rule:     "special-file",
+				File:     rel,
+				Message:  fmt.Sprintf("non-regular file (%s)", info.Mode().Type()),
+			})
+			return nil
+		}
+
+		report.FilesScanned++
+		return scanFile(report, path, rel, info)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		a, b := report.Findings[i], report.Findings[j]
+		if a.Severity != b.Severity {
+			return a.Severity > b.Severity
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	return report, nil
+}
+
+func scanFile(report *Report, path, rel string, info fs.FileInfo) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxScanBytes))
+	if err != nil {
+		return err
+	}
+
+	if kind := binaryKind(data); kind != "" {
+		report.add(Finding{
+			Severity: SeverityHigh,
+			Rule:     "executable-binary",
+			File:     rel,
+			Message:  kind + " executable bundled with the skill",
+		})
+		return nil
+	}
+
+	if info.Mode().Perm()&0111 != 0 && !bytes.HasPrefix(data, []byte("#!")) {
+		report.add(Finding{
+			Severity: SeverityLow,
+			Rule:     "executable-bit",
+			File:     rel,
+			Message:  "file is executable but has no interpreter line",
+		})
+	}
+
+	if bytes.IndexByte(data[:min(len(data), 8000)], 0) != -1 {
+		// Other binary data (images, archives) is not pattern-scanned.
+		report.add(Finding{
+			Severity: SeverityLow,
+			Rule:     "binary-data",
+			File:     rel,
+			Message:  "binary data, not scanned",
+		})
+		return nil
+	}
+	if info.Size() > maxScanBytes {
+		report.add(Finding{
+			Severity: SeverityMedium,
+			Rule:     "truncated-scan",
+			File:     rel,
+			Message:  fmt.Sprintf("only the first %d MiB of %d bytes were scanned", maxScanBytes>>20, info.Size()),
+		})
+	}
+
+	if rel == "SKILL.md" {
+		checkAllowedTools(report, data)
+	}
+
+	for i, line := range strings.Split(string(data), "\n") {
+		for _, rule := range lineRules {
+			if rule.pattern.MatchString(line) {
+				report.add(Finding{
+					Severity: rule.severity,
+					Rule:     rule.id,
+					File:     rel,
+					Line:     i + 1,
+					Message:  rule.message,
+					Excerpt:  excerpt(line),
+				})
+			}
+		}
+	}
+	return nil
+}
+
+func checkAllowedTools(report *Report, data []byte) {
+	doc := skill.ParseDocument(data)
+	value, ok := doc.Field("allowed-tools")
+	if !ok {
+		return
+	}
+	for _, tool := range strings.Split(value, ",") {
+		tool = strings.TrimSpace(tool)
+		if grantsBroadBash(tool) {
+			report.add(Finding{
+				Severity: SeverityHigh,
+				Rule:     "broad-bash",
+				File:     "SKILL.md",
+				Message:  "allowed-tools grants unrestricted Bash; Claude can run any command without asking",
+				Excerpt:  "allowed-tools: " + value,
+			})
+			return
+		}
+	}
+}
+
+func grantsBroadBash(tool string) bool {
+	if strings.EqualFold(tool, "Bash") || tool == "*" {
+		return true
+	}
+	if !strings.HasPrefix(tool, "Bash(") || !strings.HasSuffix(tool, ")") {
+		return false
+	}
+	spec := strings.TrimSpace(tool[len("Bash(") : len(tool)-1])
+	return spec == "" || spec == "*" || spec == ":*" || spec == "*:*"
+}
+
+func binaryKind(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte("\x7fELF")):
+		return "ELF"
+	case bytes.HasPrefix(data, []byte{0xfe, 0xed, 0xfa, 0xce}),
+		bytes.HasPrefix(data, []byte{0xfe, 0xed, 0xfa, 0xcf}),
+		bytes.HasPrefix(data, []byte{0xce, 0xfa, 0xed, 0xfe}),
+		bytes.HasPrefix(data, []byte{0xcf, 0xfa, 0xed, 0xfe}),
+		bytes.HasPrefix(data, []byte{0xca, 0xfe, 0xba, 0xbe}):
+		return "Mach-O"
+	case bytes.HasPrefix(data, []byte("MZ")) && len(data) > 0x40:
+		return "Windows PE"
+	}
+	return ""
+}
+
+func excerpt(line string) string {
+	line = strings.TrimSpace(line)
+	if len(line) > 120 {
+		line = line[:117] + "..."
+	}
+	return line
+}
+
+func (r *Report) add(f Finding) {
+	r.Findings = append(r.Findings, f)
+}
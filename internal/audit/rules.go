@@ -0,0 +1,68 @@
+package audit
+
+import "regexp"
+
+type lineRule struct {
+	id       string
+	severity Severity
+	pattern  *regexp.Regexp
+	message  string
+}
+
+// lineRules are matched against every line of every text file.
+var lineRules = []lineRule{
+	{
+		id:       "pipe-to-shell",
+		severity: SeverityCritical,
+		pattern:  regexp.MustCompile(`\b(curl|wget)\b[^|\n]*\|\s*(sudo\s+)?(ba|z|da|k)?sh\b|\b(curl|wget)\b[^|\n]*\|\s*(sudo\s+)?python[0-9.]*\b`),
+		message:  "downloads a remote script and pipes it straight into an interpreter",
+	},
+	{
+		id:       "decode-and-exec",
+		severity: SeverityCritical,
+		pattern:  regexp.MustCompile(`base64\s+(-d|--decode|-D)\b[^\n]*\|\s*(ba|z)?sh\b|\b(exec|eval)\s*\(\s*[\w.]*(b64decode|atob|decodebytes|fromhex)\s*\(|eval\s+"?\$\(\s*echo\s+[^)]*base64`),
+		message:  "decodes an encoded payload and executes it",
+	},
+	{
+		id:       "reverse-shell",
+		severity: SeverityCritical,
+		pattern:  regexp.MustCompile(`/dev/tcp/|\bnc(at)?\b[^\n]*\s-e\s|\bsocat\b[^\n]*exec:|\bmkfifo\b[^\n]*\bnc\b`),
+		message:  "opens a raw network shell",
+	},
+	{
+		id:       "credential-access",
+		severity: SeverityHigh,
+		pattern:  regexp.MustCompile(`~?/\.ssh/|\bid_(rsa|ed25519|ecdsa|dsa)\b|\.aws/credentials|\.netrc\b|\.git-credentials|\.docker/config\.json|\.kube/config|\.gnupg/|/etc/shadow|\.config/gh/hosts\.yml|Library/Keychains|security\s+find-(generic|internet)-password`),
+		message:  "reads credential or key material",
+	},
+	{
+		id:       "data-exfiltration",
+		severity: SeverityHigh,
+		pattern:  regexp.MustCompile(`\b(curl|wget)\b[^\n]*(\s(-d|--data(-binary|-raw|-urlencode)?|-F|--form|-T|--upload-file|--post-file)[=\s]+["']?@|\$\{?[A-Z_]*(TOKEN|SECRET|KEY|PASSWORD)\b)|\benv\b\s*\|\s*(curl|nc|wget)\b`),
+		message:  "sends local files or secrets to a remote host",
+	},
+	{
+		id:       "destructive-command",
+		severity: SeverityHigh,
+		pattern:  regexp.MustCompile(`\brm\s+-[a-zA-Z]*[rf][a-zA-Z]*\s+(/|~|\$HOME)(\s|$|/\*)|\bmkfs\.|\bdd\b[^\n]*of=/dev/`),
+		message:  "deletes or overwrites data outside the project",
+	},
+	{
+		id:       "obfuscated-payload",
+		severity: SeverityMedium,
+		pattern:  regexp.MustCompile(`[A-Za-z0-9+/]{200,}={0,2}|(\\x[0-9a-fA-F]{2}){20,}|\b(chr\(\d+\)\s*\+\s*){8,}`),
+		message:  "contains a long encoded or obfuscated string",
+	},
+	{
+		id:       "dynamic-exec",
+		severity: SeverityLow,
+		pattern:  regexp.MustCompile(`\b(exec|eval)\s*\(|\bcompile\s*\([^)]*['"]exec['"]|\bnew\s+Function\s*\(|\bchild_process\b|\bos\.system\s*\(|\bsubprocess\.[a-z_]+\([^)]*shell\s*=\s*True`),
+		message:  "executes dynamically built code or shell commands",
+	},
+	{
+		id:       "privilege-escalation",
+		severity: SeverityMedium,
+		pattern:  regexp.MustCompile(`\bsudo\s+|\bchmod\s+[0-7]*[4-7][0-7]{3}\b|\bchmod\s+[ugo]*\+s\b`),
+		message:  "requests elevated privileges or sets setuid bits",
+	},
+}
@@ -2,25 +2,104 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // Config represents the global configuration
 type Config struct {
-	SkillsDir        string `json:"skills_dir"`
-	Registry         string `json:"registry"`
-	RegistryTTLHours int    `json:"registry_ttl_hours"`
+	SkillsDir          string           `json:"skills_dir"`
+	Registry           string           `json:"registry"`
+	RegistryTTLHours   int              `json:"registry_ttl_hours"`
+	AuditBlockSeverity string           `json:"audit_block_severity"`
+	Policy             Policy           `json:"policy"`
+	PolicyFile         string           `json:"policy_file,omitempty"`
+	ArchiveLimits      ArchiveLimits    `json:"archive_limits"`
+	ArchiveCache       ArchiveCache     `json:"archive_cache"`
+	Network            Network          `json:"network"`
+	IntegrityMode      string           `json:"integrity_mode"`
+	RegistryPublicKey  string           `json:"registry_public_key,omitempty"`
+	Registries         []RegistryConfig `json:"registries,omitempty"`
+}
+
+// DefaultRegistryName names the registry configured by the legacy
+// "registry" key. Its caches keep their original paths.
+const DefaultRegistryName = "default"
+
+// RegistryConfig is one entry of the ordered "registries" list.
+type RegistryConfig struct {
+	Name      string `json:"name"`
+	URL       string `json:"url"`
+	PublicKey string `json:"public_key,omitempty"`
+}
+
+// ArchiveLimits bounds repository archive downloads and skill extraction.
+// Zero values use the defaults.
+type ArchiveLimits struct {
+	MaxDownloadBytes     int64 `json:"max_download_bytes,omitempty"`
+	MaxUncompressedBytes int64 `json:"max_uncompressed_bytes,omitempty"`
+	MaxFiles             int   `json:"max_files,omitempty"`
+	MaxFileBytes         int64 `json:"max_file_bytes,omitempty"`
+}
+
+// ArchiveCache bounds the cache of downloaded repository archives. Zero
+// values use the defaults.
+type ArchiveCache struct {
+	MaxBytes      int64 `json:"max_bytes,omitempty"`       // total size kept
+	MaxAgeDays    int   `json:"max_age_days,omitempty"`    // archives unused this long are removed
+	RefTTLMinutes int   `json:"ref_ttl_minutes,omitempty"` // how long a branch archive is reused before downloading again
+}
+
+// Network configures HTTP requests to registries and GitHub. Zero values use
+// the defaults. Proxies come from HTTPS_PROXY, HTTP_PROXY and NO_PROXY.
+type Network struct {
+	ConnectTimeoutSeconds int    `json:"connect_timeout_seconds,omitempty"` // TCP connect and TLS handshake
+	TimeoutSeconds        int    `json:"timeout_seconds,omitempty"`         // whole request, including the body
+	Retries               int    `json:"retries,omitempty"`                 // retries after the first attempt; -1 disables
+	CABundle              string `json:"ca_bundle,omitempty"`               // PEM certificates trusted in addition to the system roots
+	ClientCert            string `json:"client_cert,omitempty"`             // PEM client certificate for mutual TLS
+	ClientKey             string `json:"client_key,omitempty"`              // PEM private key for client_cert
+}
+
+// Policy restricts which sources skills may be installed from.
+// Entries are case-insensitive and may use * wildcards.
+type Policy struct {
+	AllowedOwners     []string `json:"allowed_owners,omitempty"`
+	DeniedOwners      []string `json:"denied_owners,omitempty"`
+	AllowedRepos      []string `json:"allowed_repos,omitempty"`
+	DeniedRepos       []string `json:"denied_repos,omitempty"`
+	AllowedCategories []string `json:"allowed_categories,omitempty"`
+	DeniedCategories  []string `json:"denied_categories,omitempty"`
 }
 
 // DefaultConfig returns default configuration
 func DefaultConfig() *Config {
 	homeDir, _ := os.UserHomeDir()
 	return &Config{
-		SkillsDir:        filepath.Join(homeDir, ".claude", "skills"),
-		Registry:         "github",
-		RegistryTTLHours: 24,
+		SkillsDir:          filepath.Join(homeDir, ".claude", "skills"),
+		Registry:           "github",
+		RegistryTTLHours:   24,
+		AuditBlockSeverity: "high",
+		IntegrityMode:      "refuse",
+		ArchiveLimits: ArchiveLimits{
+			MaxDownloadBytes:     256 << 20,
+			MaxUncompressedBytes: 100 << 20,
+			MaxFiles:             5000,
+			MaxFileBytes:         25 << 20,
+		},
+		ArchiveCache: ArchiveCache{
+			MaxBytes:      1 << 30,
+			MaxAgeDays:    30,
+			RefTTLMinutes: 60,
+		},
+		Network: Network{
+			ConnectTimeoutSeconds: 10,
+			TimeoutSeconds:        300,
+			Retries:               3,
+		},
 	}
 }
 
@@ -30,6 +109,20 @@ func GetSkillsDir() string {
 	return cfg.SkillsDir
 }
 
+// ProjectSkillsDir returns the project skills directory Claude Code reads in
+// the current working directory, or "" when it is the skills directory.
+func ProjectSkillsDir() string {
+	wd, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	dir := filepath.Join(wd, ".claude", "skills")
+	if filepath.Clean(dir) == filepath.Clean(GetSkillsDir()) {
+		return ""
+	}
+	return dir
+}
+
 // GetRegistryTTL returns registry cache TTL in hours.
 func GetRegistryTTL() int {
 	cfg := Load()
@@ -39,14 +132,179 @@ func GetRegistryTTL() int {
 	return cfg.RegistryTTLHours
 }
 
+// GetAuditBlockSeverity returns the audit severity at or above which
+// installs are blocked ("info" through "critical", or "none").
+func GetAuditBlockSeverity() string {
+	cfg := Load()
+	if cfg.AuditBlockSeverity == "" {
+		return DefaultConfig().AuditBlockSeverity
+	}
+	return cfg.AuditBlockSeverity
+}
+
 // GetRegistryBaseURL returns the registry base URL.
 // If config uses legacy "github", return default registry URL.
 func GetRegistryBaseURL() string {
-	cfg := Load()
-	if cfg.Registry == "" || cfg.Registry == "github" {
+	return registryURL(Load().Registry)
+}
+
+func registryURL(url string) string {
+	if url == "" || url == "github" {
 		return "https://raw.githubusercontent.com/majiayu000/claude-skill-registry/main"
 	}
-	return cfg.Registry
+	return expandHome(url)
+}
+
+// GetRegistries returns the configured registries in priority order. Without
+// a "registries" list it returns the single legacy "registry" entry, named
+// DefaultRegistryName and verified with registry_public_key. With a list,
+// registry_public_key still applies to an entry named DefaultRegistryName
+// that sets no public_key of its own, and it is an error for the list to
+// have no such entry.
+//
+// The config file is read with LoadStrict: it carries the registry keys.
+func GetRegistries() ([]RegistryConfig, error) {
+	cfg, err := LoadStrict()
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.Registries) == 0 {
+		return []RegistryConfig{{
+			Name:      DefaultRegistryName,
+			URL:       registryURL(cfg.Registry),
+			PublicKey: strings.TrimSpace(cfg.RegistryPublicKey),
+		}}, nil
+	}
+
+	registries := make([]RegistryConfig, len(cfg.Registries))
+	keyUsed := strings.TrimSpace(cfg.RegistryPublicKey) == ""
+	for i, r := range cfg.Registries {
+		registries[i] = RegistryConfig{
+			Name:      strings.TrimSpace(r.Name),
+			URL:       registryURL(strings.TrimSpace(r.URL)),
+			PublicKey: strings.TrimSpace(r.PublicKey),
+		}
+		if registries[i].Name == DefaultRegistryName {
+			keyUsed = true
+			if registries[i].PublicKey == "" {
+				registries[i].PublicKey = strings.TrimSpace(cfg.RegistryPublicKey)
+			}
+		}
+	}
+	if !keyUsed {
+		// A key that verifies nothing is a misconfiguration, not a choice
+		// to go unverified.
+		return nil, fmt.Errorf("registry_public_key is set but no registries entry is named '%s'; set public_key on the entry it belongs to", DefaultRegistryName)
+	}
+	return registries, nil
+}
+
+// GetIntegrityMode returns how installs react to a registry digest mismatch:
+// "refuse" (default) or "warn".
+func GetIntegrityMode() string {
+	cfg := Load()
+	if cfg.IntegrityMode == "" {
+		return DefaultConfig().IntegrityMode
+	}
+	return cfg.IntegrityMode
+}
+
+// GetArchiveLimits returns the archive limits with defaults for unset values.
+func GetArchiveLimits() ArchiveLimits {
+	limits := Load().ArchiveLimits
+	defaults := DefaultConfig().ArchiveLimits
+	if limits.MaxDownloadBytes <= 0 {
+		limits.MaxDownloadBytes = defaults.MaxDownloadBytes
+	}
+	if limits.MaxUncompressedBytes <= 0 {
+		limits.MaxUncompressedBytes = defaults.MaxUncompressedBytes
+	}
+	if limits.MaxFiles <= 0 {
+		limits.MaxFiles = defaults.MaxFiles
+	}
+	if limits.MaxFileBytes <= 0 {
+		limits.MaxFileBytes = defaults.MaxFileBytes
+	}
+	return limits
+}
+
+// GetArchiveCache returns the archive cache bounds with defaults for unset
+// values.
+func GetArchiveCache() ArchiveCache {
+	cache := Load().ArchiveCache
+	defaults := DefaultConfig().ArchiveCache
+	if cache.MaxBytes <= 0 {
+		cache.MaxBytes = defaults.MaxBytes
+	}
+	if cache.MaxAgeDays <= 0 {
+		cache.MaxAgeDays = defaults.MaxAgeDays
+	}
+	if cache.RefTTLMinutes <= 0 {
+		cache.RefTTLMinutes = defaults.RefTTLMinutes
+	}
+	return cache
+}
+
+// GetNetwork returns the network settings with defaults for unset values.
+func GetNetwork() Network {
+	network := Load().Network
+	defaults := DefaultConfig().Network
+	if network.ConnectTimeoutSeconds <= 0 {
+		network.ConnectTimeoutSeconds = defaults.ConnectTimeoutSeconds
+	}
+	if network.TimeoutSeconds <= 0 {
+		network.TimeoutSeconds = defaults.TimeoutSeconds
+	}
+	if network.Retries == 0 {
+		network.Retries = defaults.Retries
+	} else if network.Retries < 0 {
+		network.Retries = 0
+	}
+	network.CABundle = expandHome(network.CABundle)
+	network.ClientCert = expandHome(network.ClientCert)
+	network.ClientKey = expandHome(network.ClientKey)
+	return network
+}
+
+// ArchiveCacheDir returns the directory caching downloaded repository archives.
+func ArchiveCacheDir() string {
+	return filepath.Join(CacheDir(), "archives")
+}
+
+// GetPolicyFile returns the org-wide policy file path, expanding a leading ~.
+func GetPolicyFile() string {
+	return Load().GetPolicyFile()
+}
+
+// GetPolicyFile returns the org-wide policy file path of c, expanding a
+// leading ~.
+func (c *Config) GetPolicyFile() string {
+	return expandHome(c.PolicyFile)
+}
+
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		homeDir, _ := os.UserHomeDir()
+		return filepath.Join(homeDir, strings.TrimPrefix(path, "~"))
+	}
+	return path
+}
+
+// ErrOffline is returned instead of making a network request when network
+// access is disabled.
+var ErrOffline = errors.New("network access disabled by --offline")
+
+// offline is set for the process by the global --offline flag.
+var offline bool
+
+// SetOffline disables (or re-enables) network access for the process.
+func SetOffline(v bool) {
+	offline = v
+}
+
+// IsOffline reports whether network access is disabled.
+func IsOffline() bool {
+	return offline
 }
 
 // ConfigPath returns the path to config file
@@ -55,40 +313,91 @@ func ConfigPath() string {
 	return filepath.Join(homeDir, ".skrc")
 }
 
-// RegistryCachePath returns the registry cache file path.
-func RegistryCachePath() string {
+// CacheDir returns the sk cache directory.
+func CacheDir() string {
 	cacheDir, err := os.UserCacheDir()
 	if err != nil || cacheDir == "" {
 		homeDir, _ := os.UserHomeDir()
 		cacheDir = filepath.Join(homeDir, ".cache")
 	}
-	return filepath.Join(cacheDir, "sk", "registry.json")
+	return filepath.Join(cacheDir, "sk")
 }
 
-// SearchIndexCachePath returns the compact search index cache file path.
-func SearchIndexCachePath() string {
-	cacheDir, err := os.UserCacheDir()
-	if err != nil || cacheDir == "" {
-		homeDir, _ := os.UserHomeDir()
-		cacheDir = filepath.Join(homeDir, ".cache")
+// RegistryCacheDir returns the cache directory for a named registry. The
+// default registry uses the top-level cache directory.
+func RegistryCacheDir(name string) string {
+	if name == "" || name == DefaultRegistryName {
+		return CacheDir()
 	}
-	return filepath.Join(cacheDir, "sk", "search-index.json")
+	return filepath.Join(CacheDir(), "registries", name)
+}
+
+// RegistryCachePath returns the default registry cache file path.
+func RegistryCachePath() string {
+	return RegistryCachePathFor(DefaultRegistryName)
+}
+
+// RegistryCachePathFor returns the full registry cache file path for a named registry.
+func RegistryCachePathFor(name string) string {
+	return filepath.Join(RegistryCacheDir(name), "registry.json")
+}
+
+// SearchIndexCachePath returns the default compact search index cache file path.
+func SearchIndexCachePath() string {
+	return SearchIndexCachePathFor(DefaultRegistryName)
+}
+
+// SearchIndexCachePathFor returns the search index cache file path for a named registry.
+func SearchIndexCachePathFor(name string) string {
+	return filepath.Join(RegistryCacheDir(name), "search-index.json")
+}
+
+// FeaturedCachePathFor returns the featured skills cache file path for a named registry.
+func FeaturedCachePathFor(name string) string {
+	return filepath.Join(RegistryCacheDir(name), "featured.json")
+}
+
+// CategoryIndexCachePathFor returns the category index cache file path for a named registry.
+func CategoryIndexCachePathFor(name string) string {
+	return filepath.Join(RegistryCacheDir(name), "category-index.json")
 }
 
-// Load loads configuration from file
+// CategoryCacheDirFor returns the directory caching individual categories for a named registry.
+func CategoryCacheDirFor(name string) string {
+	return filepath.Join(RegistryCacheDir(name), "categories")
+}
+
+// Load loads configuration from file. A config file that cannot be read or
+// parsed is reported on stderr and the defaults are used; settings that
+// guard security use LoadStrict instead.
 func Load() *Config {
+	cfg, err := LoadStrict()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Warning: "+err.Error()+"; using defaults")
+		return DefaultConfig()
+	}
+	return cfg
+}
+
+// LoadStrict loads configuration from file, returning an error when the file
+// exists but cannot be read or parsed. A missing file yields the defaults.
+// The trust policy and registry keys are read with it, so a broken config
+// never silently turns them off.
+func LoadStrict() (*Config, error) {
 	cfg := DefaultConfig()
 
 	data, err := os.ReadFile(ConfigPath())
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
 	if err != nil {
-		return cfg
+		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
 	if err := json.Unmarshal(data, cfg); err != nil {
-		fmt.Fprintln(os.Stderr, "Warning: failed to parse config file, using defaults:", err)
-		return cfg
+		return nil, fmt.Errorf("failed to parse config file %s: %w", ConfigPath(), err)
 	}
-	return cfg
+	return cfg, nil
 }
 
 // Save saves configuration to file
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeConfig(t *testing.T, content string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.WriteFile(filepath.Join(home, ".skrc"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRegistryPublicKeyAppliesToDefaultEntry(t *testing.T) {
+	writeConfig(t, `{
+  "registry_public_key": "top-level-key",
+  "registries": [
+    {"name": "internal", "url": "https://skills.example.com", "public_key": "internal-key"},
+    {"name": "default", "url": "github"}
+  ]
+}`)
+
+	registries, err := GetRegistries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(registries) != 2 {
+		t.Fatalf("expected 2 registries, got %#v", registries)
+	}
+	if registries[0].PublicKey != "internal-key" {
+		t.Fatalf("expected the entry's own key to be kept, got %q", registries[0].PublicKey)
+	}
+	if registries[1].PublicKey != "top-level-key" {
+		t.Fatalf("expected registry_public_key on the default entry, got %q", registries[1].PublicKey)
+	}
+}
+
+func TestRegistryPublicKeyWithoutDefaultEntryFails(t *testing.T) {
+	writeConfig(t, `{
+  "registry_public_key": "top-level-key",
+  "registries": [{"name": "internal", "url": "https://skills.example.com"}]
+}`)
+
+	if registries, err := GetRegistries(); err == nil {
+		t.Fatalf("expected an unused registry_public_key to fail, got %#v", registries)
+	}
+}
+
+func TestLoadStrictRejectsBrokenConfig(t *testing.T) {
+	writeConfig(t, `{"registry_public_key": "key",}`)
+
+	if _, err := LoadStrict(); err == nil {
+		t.Fatal("expected a parse error")
+	}
+	if _, err := GetRegistries(); err == nil {
+		t.Fatal("expected registries not to fall back to unverified defaults")
+	}
+	if cfg := Load(); cfg.RegistryPublicKey != "" {
+		t.Fatalf("expected Load to fall back to defaults, got %#v", cfg)
+	}
+}
+
+func TestLoadStrictWithoutConfigFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	cfg, err := LoadStrict()
+	if err != nil || cfg.SkillsDir == "" {
+		t.Fatalf("expected defaults without a config file, got %#v, %v", cfg, err)
+	}
+}
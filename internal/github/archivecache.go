@@ -0,0 +1,307 @@
+package github
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/majiayu000/caude-skill-manager/internal/config"
+)
+
+// archive is an open repository archive. It stays readable while open even
+// if the archive cache evicts it meanwhile.
+type archive struct {
+	file *os.File
+	temp bool // not in the archive cache; removed by release
+}
+
+// zip returns a reader over the archive.
+func (a *archive) zip() (*zip.Reader, error) {
+	info, err := a.file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return zip.NewReader(a.file, info.Size())
+}
+
+func (a *archive) release() {
+	_ = a.file.Close()
+	if a.temp {
+		_ = os.Remove(a.file.Name())
+	}
+}
+
+// archiveCache keeps downloaded repository archives under blobs/, named by
+// the sha256 of their content, and records under refs/ which archive each
+// owner/repo@ref resolved to. Commit refs are immutable and reused for as
+// long as the archive is kept; branch refs are reused for the ref TTL, or
+// indefinitely with --offline.
+type archiveCache struct {
+	dir    string
+	bounds config.ArchiveCache
+}
+
+type archiveRef struct {
+	Key       string    `json:"key"`
+	Commit    string    `json:"commit,omitempty"`
+	SHA256    string    `json:"sha256"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// ArchiveCacheStats describes the archive cache on disk.
+type ArchiveCacheStats struct {
+	Dir      string
+	Archives int
+	Size     int64
+	ModTime  time.Time // most recent use
+}
+
+func newArchiveCache() *archiveCache {
+	return &archiveCache{dir: config.ArchiveCacheDir(), bounds: config.GetArchiveCache()}
+}
+
+// archiveKey identifies the ref info downloads, and whether it is a pinned
+// commit.
+func archiveKey(info *RepoInfo) (string, bool) {
+	if info.Commit != "" {
+		return commitArchiveKey(info, info.Commit), true
+	}
+	return strings.ToLower(info.Owner+"/"+info.Repo) + "@heads/" + info.Branch, false
+}
+
+func commitArchiveKey(info *RepoInfo, commit string) string {
+	return strings.ToLower(info.Owner+"/"+info.Repo) + "@" + commit
+}
+
+var archiveLocks sync.Map // archive key -> *sync.Mutex
+
+// lockArchiveKey serialises downloads of one ref within the process, so
+// concurrent installs from the same repository share one download.
+func lockArchiveKey(key string) func() {
+	v, _ := archiveLocks.LoadOrStore(key, &sync.Mutex{})
+	mu := v.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
+func (c *archiveCache) blobsDir() string {
+	return filepath.Join(c.dir, "blobs")
+}
+
+func (c *archiveCache) blobPath(sum string) string {
+	return filepath.Join(c.blobsDir(), sum+".zip")
+}
+
+func (c *archiveCache) refPath(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, "refs", hex.EncodeToString(sum[:])+".json")
+}
+
+// lookup opens the cached archive for key, marking it as used. The archive
+// is opened here, so a prune by another install cannot remove it before it
+// is read; an archive already pruned is a cache miss.
+func (c *archiveCache) lookup(key string, pinned bool, limits config.ArchiveLimits) (*archive, bool) {
+	data, err := os.ReadFile(c.refPath(key))
+	if err != nil {
+		return nil, false
+	}
+	var ref archiveRef
+	if err := json.Unmarshal(data, &ref); err != nil || ref.Key != key || ref.SHA256 == "" {
+		return nil, false
+	}
+	ttl := time.Duration(c.bounds.RefTTLMinutes) * time.Minute
+	if !pinned && !config.IsOffline() && time.Since(ref.FetchedAt) > ttl {
+		return nil, false
+	}
+	path := c.blobPath(ref.SHA256)
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, false
+	}
+	info, err := f.Stat()
+	if err != nil || (limits.MaxDownloadBytes > 0 && info.Size() > limits.MaxDownloadBytes) {
+		f.Close()
+		return nil, false
+	}
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+	return &archive{file: f}, true
+}
+
+// createTemp creates the file a download is written to, inside the cache so
+// it can be moved into place without copying.
+func (c *archiveCache) createTemp() (*os.File, error) {
+	if err := os.MkdirAll(c.blobsDir(), 0755); err == nil {
+		if f, err := os.CreateTemp(c.blobsDir(), ".download-*.zip"); err == nil {
+			return f, nil
+		}
+	}
+	return os.CreateTemp("", "sk-*.zip")
+}
+
+// store moves the downloaded archive at tmpPath into the cache under keys
+// and prunes the cache, keeping the new archive.
+func (c *archiveCache) store(tmpPath string, keys []string, commit string) (string, error) {
+	sum, err := fileSHA256(tmpPath)
+	if err != nil {
+		return "", err
+	}
+	path := c.blobPath(sum)
+	if err := os.MkdirAll(c.blobsDir(), 0755); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Join(c.dir, "refs"), 0755); err != nil {
+		return path, nil
+	}
+	for _, key := range keys {
+		data, err := json.Marshal(archiveRef{Key: key, Commit: commit, SHA256: sum, FetchedAt: time.Now()})
+		if err == nil {
+			_ = os.WriteFile(c.refPath(key), data, 0644)
+		}
+	}
+	c.prune(path)
+	return path, nil
+}
+
+// prune removes archives unused for the configured age, then the least
+// recently used ones until the cache fits its size bound, and refs whose
+// archive is gone. keep is never removed. It returns the bytes freed.
+func (c *archiveCache) prune(keep string) int64 {
+	entries, err := os.ReadDir(c.blobsDir())
+	if err != nil {
+		return 0
+	}
+	type blob struct {
+		path string
+		size int64
+		used time.Time
+	}
+	var blobs []blob
+	var freed, total int64
+	maxAge := time.Duration(c.bounds.MaxAgeDays) * 24 * time.Hour
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(c.blobsDir(), entry.Name())
+		if strings.HasPrefix(entry.Name(), ".") {
+			// Left behind by an interrupted download.
+			if time.Since(info.ModTime()) > 24*time.Hour && os.Remove(path) == nil {
+				freed += info.Size()
+			}
+			continue
+		}
+		if path != keep && time.Since(info.ModTime()) > maxAge {
+			if os.Remove(path) == nil {
+				freed += info.Size()
+			}
+			continue
+		}
+		blobs = append(blobs, blob{path: path, size: info.Size(), used: info.ModTime()})
+		total += info.Size()
+	}
+
+	sort.Slice(blobs, func(i, j int) bool { return blobs[i].used.Before(blobs[j].used) })
+	for _, b := range blobs {
+		if total <= c.bounds.MaxBytes {
+			break
+		}
+		if b.path != keep && os.Remove(b.path) == nil {
+			total -= b.size
+			freed += b.size
+		}
+	}
+
+	refs, _ := os.ReadDir(filepath.Join(c.dir, "refs"))
+	for _, entry := range refs {
+		path := filepath.Join(c.dir, "refs", entry.Name())
+		data, err := os.ReadFile(path)
+		var ref archiveRef
+		if err != nil || json.Unmarshal(data, &ref) != nil {
+			_ = os.Remove(path)
+			continue
+		}
+		if _, err := os.Stat(c.blobPath(ref.SHA256)); os.IsNotExist(err) {
+			_ = os.Remove(path)
+		}
+	}
+	return freed
+}
+
+// GetArchiveCacheStats reports the size of the archive cache.
+func GetArchiveCacheStats() ArchiveCacheStats {
+	c := newArchiveCache()
+	stats := ArchiveCacheStats{Dir: c.dir}
+	entries, err := os.ReadDir(c.blobsDir())
+	if err != nil {
+		return stats
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		stats.Archives++
+		stats.Size += info.Size()
+		if info.ModTime().After(stats.ModTime) {
+			stats.ModTime = info.ModTime()
+		}
+	}
+	return stats
+}
+
+// PruneArchiveCache applies the archive_cache age and size bounds and
+// returns the bytes freed.
+func PruneArchiveCache() int64 {
+	return newArchiveCache().prune("")
+}
+
+// ClearArchiveCache removes every cached archive and returns the bytes freed.
+func ClearArchiveCache() (int64, error) {
+	stats := GetArchiveCacheStats()
+	if err := os.RemoveAll(stats.Dir); err != nil {
+		return 0, err
+	}
+	return stats.Size, nil
+}
+
+// archiveCommit returns the commit SHA GitHub records in the archive
+// comment, or "" if there is none.
+func archiveCommit(zipPath string) string {
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return ""
+	}
+	defer r.Close()
+	commit := strings.TrimSpace(r.Comment)
+	if !commitPattern.MatchString(commit) {
+		return ""
+	}
+	return commit
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,245 @@
+package github
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/majiayu000/caude-skill-manager/internal/config"
+)
+
+const testCommit = "0123456789abcdef0123456789abcdef01234567"
+
+// archiveZip returns a repository archive stamped with testCommit.
+func archiveZip(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, body := range map[string]string{"repo-main/": "", "repo-main/pdf/SKILL.md": "---\nname: pdf\n---\n"} {
+		fw, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = fw.Write([]byte(body))
+	}
+	if err := w.SetComment(testCommit); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// archiveServer serves one repository archive stamped with testCommit and
+// counts the downloads.
+func archiveServer(t *testing.T) *int {
+	t.Helper()
+	body := archiveZip(t)
+	downloads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, ".zip") {
+			http.NotFound(w, r)
+			return
+		}
+		downloads++
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	oldBase := archiveBaseURL
+	archiveBaseURL = server.URL
+	t.Cleanup(func() { archiveBaseURL = oldBase })
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	return &downloads
+}
+
+func TestDownloadsReuseCachedArchive(t *testing.T) {
+	downloads := archiveServer(t)
+
+	for i := 0; i < 2; i++ {
+		target := filepath.Join(t.TempDir(), "pdf")
+		if err := DownloadAndExtractTo(&RepoInfo{Owner: "owner", Repo: "repo", Branch: "main", Path: "pdf"}, target); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The branch download also recorded its commit, so a pinned install reuses it.
+	if err := DownloadAndExtractTo(&RepoInfo{Owner: "Owner", Repo: "repo", Branch: "main", Path: "pdf", Commit: testCommit}, filepath.Join(t.TempDir(), "pdf")); err != nil {
+		t.Fatal(err)
+	}
+	if *downloads != 1 {
+		t.Fatalf("expected one download, got %d", *downloads)
+	}
+	if stats := GetArchiveCacheStats(); stats.Archives != 1 || stats.Size == 0 {
+		t.Fatalf("unexpected archive cache stats %#v", stats)
+	}
+}
+
+func TestBranchArchiveRefetchedAfterRefTTL(t *testing.T) {
+	downloads := archiveServer(t)
+	info := &RepoInfo{Owner: "owner", Repo: "repo", Branch: "main", Path: "pdf"}
+	if err := DownloadAndExtractTo(info, filepath.Join(t.TempDir(), "pdf")); err != nil {
+		t.Fatal(err)
+	}
+
+	cache := newArchiveCache()
+	key, _ := archiveKey(info)
+	data, err := os.ReadFile(cache.refPath(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ref archiveRef
+	if err := json.Unmarshal(data, &ref); err != nil {
+		t.Fatal(err)
+	}
+	ref.FetchedAt = time.Now().Add(-2 * time.Hour)
+	data, _ = json.Marshal(ref)
+	if err := os.WriteFile(cache.refPath(key), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config.SetOffline(true)
+	err = DownloadAndExtractTo(info, filepath.Join(t.TempDir(), "pdf"))
+	config.SetOffline(false)
+	if err != nil {
+		t.Fatalf("expected the expired branch archive to be used offline: %v", err)
+	}
+	if *downloads != 1 {
+		t.Fatalf("expected no download offline, got %d", *downloads)
+	}
+
+	if err := DownloadAndExtractTo(info, filepath.Join(t.TempDir(), "pdf")); err != nil {
+		t.Fatal(err)
+	}
+	if *downloads != 2 {
+		t.Fatalf("expected the expired branch archive to be downloaded again, got %d downloads", *downloads)
+	}
+}
+
+func TestArchiveCachePruneEvictsLeastRecentlyUsed(t *testing.T) {
+	cache := &archiveCache{dir: t.TempDir(), bounds: config.ArchiveCache{MaxBytes: 10, MaxAgeDays: 30, RefTTLMinutes: 60}}
+	if err := os.MkdirAll(cache.blobsDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	for name, age := range map[string]time.Duration{"old": 40 * 24 * time.Hour, "lru": 2 * time.Hour, "recent": time.Hour, "new": 0} {
+		path := cache.blobPath(name)
+		if err := os.WriteFile(path, []byte("123456"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, now.Add(-age), now.Add(-age)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	freed := cache.prune(cache.blobPath("new"))
+
+	if freed != 18 {
+		t.Fatalf("expected 18 bytes freed, got %d", freed)
+	}
+	for name, kept := range map[string]bool{"old": false, "lru": false, "recent": false, "new": true} {
+		if _, err := os.Stat(cache.blobPath(name)); (err == nil) != kept {
+			t.Fatalf("%s: kept=%v, want %v", name, err == nil, kept)
+		}
+	}
+}
+
+func TestDownloadReportsProgress(t *testing.T) {
+	archiveServer(t)
+	var calls []DownloadProgress
+	SetDownloadProgressHandler(func(p DownloadProgress) { calls = append(calls, p) })
+	defer SetDownloadProgressHandler(nil)
+
+	if err := DownloadAndExtractTo(&RepoInfo{Owner: "owner", Repo: "repo", Branch: "main", Path: "pdf"}, filepath.Join(t.TempDir(), "pdf")); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) < 3 || calls[0].Bytes != 0 || calls[0].Repo != "owner/repo" || calls[0].Ref != "main" {
+		t.Fatalf("expected a start call and byte updates, got %#v", calls)
+	}
+	last := calls[len(calls)-1]
+	if !last.Done || last.Bytes == 0 || last.Bytes != last.Total {
+		t.Fatalf("expected a final call with every byte of the Content-Length, got %#v", last)
+	}
+}
+
+func TestMasterFallbackKeepsRequestedRef(t *testing.T) {
+	body := archiveZip(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/heads/master.zip") {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	oldBase := archiveBaseURL
+	archiveBaseURL = server.URL
+	t.Cleanup(func() { archiveBaseURL = oldBase })
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	var refs []string
+	SetDownloadProgressHandler(func(p DownloadProgress) { refs = append(refs, p.Ref) })
+	t.Cleanup(func() { SetDownloadProgressHandler(nil) })
+
+	info := &RepoInfo{Owner: "owner", Repo: "repo", Branch: "main", Path: "pdf"}
+	if err := DownloadAndExtractTo(info, filepath.Join(t.TempDir(), "pdf")); err != nil {
+		t.Fatal(err)
+	}
+	if info.Branch != "main" {
+		t.Fatalf("expected the caller's RepoInfo to be left alone, got branch %q", info.Branch)
+	}
+	if len(refs) == 0 {
+		t.Fatal("expected download progress")
+	}
+	for _, ref := range refs {
+		if ref != "main" {
+			t.Fatalf("expected progress for the requested ref, got %q", refs)
+		}
+	}
+}
+
+func TestCachedArchiveSurvivesPruneAfterLookup(t *testing.T) {
+	downloads := archiveServer(t)
+	info := &RepoInfo{Owner: "owner", Repo: "repo", Branch: "main", Path: "pdf"}
+	if err := DownloadAndExtractTo(info, filepath.Join(t.TempDir(), "pdf")); err != nil {
+		t.Fatal(err)
+	}
+
+	cache := newArchiveCache()
+	key, pinned := archiveKey(info)
+	cached, ok := cache.lookup(key, pinned, config.GetArchiveLimits())
+	if !ok {
+		t.Fatal("expected the archive to be cached")
+	}
+	defer cached.release()
+
+	// Another install prunes every archive before this one is read.
+	cache.bounds.MaxBytes = 0
+	cache.prune("")
+	if stats := GetArchiveCacheStats(); stats.Archives != 0 {
+		t.Fatalf("expected the archive to be pruned, got %#v", stats)
+	}
+	r, err := cached.zip()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := extractZip(r, filepath.Join(t.TempDir(), "pdf"), info, config.GetArchiveLimits()); err != nil {
+		t.Fatalf("expected the looked-up archive to stay readable: %v", err)
+	}
+
+	// The pruned archive is a cache miss, not a failed install.
+	if err := DownloadAndExtractTo(info, filepath.Join(t.TempDir(), "pdf")); err != nil {
+		t.Fatal(err)
+	}
+	if *downloads != 2 {
+		t.Fatalf("expected the pruned archive to be downloaded again, got %d downloads", *downloads)
+	}
+}
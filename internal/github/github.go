@@ -2,6 +2,7 @@ package github
 
 import (
 	"archive/zip"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -12,6 +13,7 @@ import (
 	"strings"
 
 	"github.com/majiayu000/caude-skill-manager/internal/config"
+	"github.com/majiayu000/caude-skill-manager/internal/httpclient"
 )
 
 // RepoInfo contains parsed GitHub repository information
@@ -23,6 +25,7 @@ type RepoInfo struct {
 	Branch           string
 	TreeRef          string
 	TreeRefAmbiguous bool
+	Commit           string // pinned commit SHA; downloads use it instead of Branch
 	FullURL          string
 	CloneURL         string
 }
@@ -139,71 +142,42 @@ func DownloadAndExtract(info *RepoInfo, targetName string) error {
 		return fmt.Errorf("failed to create skills directory: %w", err)
 	}
 
-	// Download as zip
-	zipURL := fmt.Sprintf("https://github.com/%s/%s/archive/refs/heads/%s.zip",
-		info.Owner, info.Repo, info.Branch)
-
-	resp, err := http.Get(zipURL)
-	if err != nil {
-		return fmt.Errorf("failed to download: %w", err)
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		resp.Body.Close()
-		// Try 'master' branch if 'main' fails
-		if info.Branch == "main" {
-			info.Branch = "master"
-			zipURL = fmt.Sprintf("https://github.com/%s/%s/archive/refs/heads/%s.zip",
-				info.Owner, info.Repo, info.Branch)
-			resp, err = http.Get(zipURL)
-			if err != nil {
-				return fmt.Errorf("failed to download: %w", err)
-			}
-		}
-	}
-	defer resp.Body.Close()
+	return DownloadAndExtractTo(info, filepath.Join(config.GetSkillsDir(), targetName))
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("download failed with status: %s", resp.Status)
-	}
+// DownloadAndExtractTo downloads a repository and extracts the skill into
+// targetDir, which may be a staging directory outside the skills directory.
+func DownloadAndExtractTo(info *RepoInfo, targetDir string) error {
+	limits := config.GetArchiveLimits()
 
-	// Create temp file for zip
-	tmpFile, err := os.CreateTemp("", "sk-*.zip")
+	zipArchive, info, err := downloadArchiveWithFallback(info, limits)
 	if err != nil {
-		return fmt.Errorf("failed to create temp file: %w", err)
+		return err
 	}
-	defer os.Remove(tmpFile.Name())
-
-	if _, err := io.Copy(tmpFile, resp.Body); err != nil {
-		return fmt.Errorf("failed to save zip: %w", err)
+	defer zipArchive.release()
+	r, err := zipArchive.zip()
+	if err != nil {
+		return err
 	}
-	tmpFile.Close()
-
-	targetDir := filepath.Join(config.GetSkillsDir(), targetName)
 
 	// Try the specified path first
-	err = extractZip(tmpFile.Name(), targetDir, info)
-	if err != nil && info.Path != "" {
+	err = extractZip(r, targetDir, info, limits)
+	if err != nil && info.Path != "" && !isArchiveSafetyError(err) {
 		// If path doesn't work, try common skill locations
 		// e.g., "docx" -> "skills/docx" for anthropics/skills repo
-		alternativePaths := []string{
-			"skills/" + info.Path,
-			"skill/" + info.Path,
-		}
-
-		for _, altPath := range alternativePaths {
+		for _, altPath := range skillPathCandidates(info.Path)[1:] {
 			infoCopy := *info
 			infoCopy.Path = altPath
 			os.RemoveAll(targetDir) // Clean up failed attempt
-			if err = extractZip(tmpFile.Name(), targetDir, &infoCopy); err == nil {
-				return nil
+			if err = extractZip(r, targetDir, &infoCopy, limits); err == nil || isArchiveSafetyError(err) {
+				break
 			}
 		}
 	}
 
 	if err != nil {
-		if info.TreeRef != "" && info.TreeRefAmbiguous {
-			if resolveErr := tryResolveAmbiguousTreeRef(info, targetName); resolveErr == nil {
+		if info.TreeRef != "" && info.TreeRefAmbiguous && !isArchiveSafetyError(err) {
+			if resolveErr := tryResolveAmbiguousTreeRef(info, targetDir, limits); resolveErr == nil {
 				return nil
 			}
 			return fmt.Errorf("failed to extract: %w (if your branch contains '/', URL-encode it, e.g. feature%%2Ffoo)", err)
@@ -214,25 +188,15 @@ func DownloadAndExtract(info *RepoInfo, targetName string) error {
 	return nil
 }
 
-func tryResolveAmbiguousTreeRef(info *RepoInfo, targetName string) error {
-	parts := strings.Split(info.TreeRef, "/")
-	if len(parts) < 2 {
+func tryResolveAmbiguousTreeRef(info *RepoInfo, targetDir string, limits config.ArchiveLimits) error {
+	candidates := refCandidates(info)
+	if len(candidates) < 2 {
 		return fmt.Errorf("ambiguous tree ref has insufficient parts")
 	}
 
-	// Try longer branch candidates first.
-	for i := len(parts); i >= 1; i-- {
-		branch := strings.Join(parts[:i], "/")
-		path := ""
-		if i < len(parts) {
-			path = strings.Join(parts[i:], "/")
-		}
-
-		infoCopy := *info
-		infoCopy.Branch = branch
-		infoCopy.Path = path
-
-		if err := downloadAndExtractWithBranch(&infoCopy, targetName); err == nil {
+	// Candidates after the first are alternative branch splits, longest first.
+	for i := range candidates[1:] {
+		if err := downloadAndExtractWithBranch(&candidates[i+1], targetDir, limits); err == nil {
 			return nil
 		}
 	}
@@ -240,65 +204,176 @@ func tryResolveAmbiguousTreeRef(info *RepoInfo, targetName string) error {
 	return fmt.Errorf("unable to resolve tree ref")
 }
 
-func downloadAndExtractWithBranch(info *RepoInfo, targetName string) error {
-	zipURL := fmt.Sprintf("https://github.com/%s/%s/archive/refs/heads/%s.zip",
-		info.Owner, info.Repo, info.Branch)
-
-	resp, err := http.Get(zipURL)
+func downloadAndExtractWithBranch(info *RepoInfo, targetDir string, limits config.ArchiveLimits) error {
+	zipArchive, err := downloadArchive(info, downloadRef(info), limits)
 	if err != nil {
-		return fmt.Errorf("failed to download: %w", err)
+		return err
+	}
+	defer zipArchive.release()
+	r, err := zipArchive.zip()
+	if err != nil {
+		return err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		resp.Body.Close()
-		return fmt.Errorf("download failed with status: %s", resp.Status)
+	return extractZip(r, targetDir, info, limits)
+}
+
+// DownloadRepoTo extracts the whole repository (or info.Path within it) into
+// targetDir without requiring a SKILL.md at its root. It returns the branch
+// downloaded, which is 'master' when an unpinned 'main' does not exist, and
+// the commit SHA GitHub records in the archive comment, or "" if there is
+// none.
+func DownloadRepoTo(info *RepoInfo, targetDir string) (branch, commit string, err error) {
+	limits := config.GetArchiveLimits()
+
+	zipArchive, info, err := downloadArchiveWithFallback(info, limits)
+	if err != nil {
+		return "", "", err
 	}
-	defer resp.Body.Close()
+	defer zipArchive.release()
 
-	tmpFile, err := os.CreateTemp("", "sk-*.zip")
+	r, err := zipArchive.zip()
 	if err != nil {
-		return fmt.Errorf("failed to create temp file: %w", err)
+		return "", "", err
 	}
-	defer os.Remove(tmpFile.Name())
 
-	if _, err := io.Copy(tmpFile, resp.Body); err != nil {
-		return fmt.Errorf("failed to save zip: %w", err)
+	prefix := archiveRootPrefix(r, info)
+	if info.Path != "" {
+		prefix += info.Path + "/"
+	}
+	if _, err := extractTree(r, prefix, targetDir, limits); err != nil {
+		return "", "", err
 	}
-	tmpFile.Close()
 
-	targetDir := filepath.Join(config.GetSkillsDir(), targetName)
-	if err := extractZip(tmpFile.Name(), targetDir, info); err != nil {
-		return err
+	commit = strings.TrimSpace(r.Comment)
+	if !commitPattern.MatchString(commit) {
+		commit = ""
 	}
+	return info.Branch, commit, nil
+}
 
-	return nil
+var commitPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// downloadArchiveWithFallback downloads the archive for info, retrying the
+// 'master' branch when an unpinned 'main' does not exist. It returns the
+// archive and info as downloaded: a copy on 'master' after the fallback.
+// Progress is reported for the ref requested either way.
+func downloadArchiveWithFallback(info *RepoInfo, limits config.ArchiveLimits) (*archive, *RepoInfo, error) {
+	requested := downloadRef(info)
+	zipArchive, err := downloadArchive(info, requested, limits)
+	var statusErr *downloadStatusError
+	if errors.As(err, &statusErr) && info.Branch == "main" && info.Commit == "" {
+		// Try 'master' branch if 'main' fails
+		master := *info
+		master.Branch = "master"
+		zipArchive, err = downloadArchive(&master, requested, limits)
+		return zipArchive, &master, err
+	}
+	return zipArchive, info, err
 }
 
-// extractZip extracts the zip file to target directory
-func extractZip(zipPath, targetDir string, info *RepoInfo) error {
-	r, err := zip.OpenReader(zipPath)
+// downloadStatusError reports a non-200 archive download response.
+type downloadStatusError struct {
+	Status string
+}
+
+func (e *downloadStatusError) Error() string {
+	return "download failed with status: " + e.Status
+}
+
+// downloadRef is the ref an archive download of info is for.
+func downloadRef(info *RepoInfo) string {
+	if info.Commit != "" {
+		return info.Commit
+	}
+	return info.Branch
+}
+
+// downloadArchive returns the archive for info from the archive cache, or
+// downloads it into the cache, reporting progress for ref. The caller
+// releases the archive.
+func downloadArchive(info *RepoInfo, ref string, limits config.ArchiveLimits) (*archive, error) {
+	cache := newArchiveCache()
+	key, pinned := archiveKey(info)
+	unlock := lockArchiveKey(key)
+	defer unlock()
+
+	if cached, ok := cache.lookup(key, pinned, limits); ok {
+		return cached, nil
+	}
+	if config.IsOffline() {
+		return nil, config.ErrOffline
+	}
+	zipURL := fmt.Sprintf("%s/%s/%s/archive/refs/heads/%s.zip",
+		archiveBaseURL, info.Owner, info.Repo, info.Branch)
+	if info.Commit != "" {
+		zipURL = fmt.Sprintf("%s/%s/%s/archive/%s.zip",
+			archiveBaseURL, info.Owner, info.Repo, info.Commit)
+	}
+
+	resp, err := httpclient.Get(zipURL)
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to download: %w", err)
 	}
-	defer r.Close()
+	defer resp.Body.Close()
 
-	// Find the actual root prefix from the zip (it might vary)
-	var rootPrefix string
-	for _, f := range r.File {
-		// First entry should be the root directory
-		if strings.Count(f.Name, "/") == 1 && strings.HasSuffix(f.Name, "/") {
-			rootPrefix = f.Name
-			break
-		}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &downloadStatusError{Status: resp.Status}
+	}
+	if limits.MaxDownloadBytes > 0 && resp.ContentLength > limits.MaxDownloadBytes {
+		return nil, fmt.Errorf("%w: %d bytes (limit %d)", ErrDownloadTooLarge, resp.ContentLength, limits.MaxDownloadBytes)
 	}
 
-	if rootPrefix == "" {
-		// Fallback to expected format
-		rootPrefix = fmt.Sprintf("%s-%s/", info.Repo, info.Branch)
+	// Create temp file for zip
+	tmpFile, err := cache.createTemp()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
 	}
 
+	progress := &progressReader{r: resp.Body, progress: DownloadProgress{Repo: info.Owner + "/" + info.Repo, Ref: ref, Total: max(resp.ContentLength, 0)}}
+	reportDownload(progress.progress)
+	var body io.Reader = progress
+	if limits.MaxDownloadBytes > 0 {
+		// Content-Length can be absent or wrong; read one byte past the limit.
+		body = io.LimitReader(progress, limits.MaxDownloadBytes+1)
+	}
+	written, err := io.Copy(tmpFile, body)
+	progress.finish()
+	if err == nil {
+		err = tmpFile.Sync()
+	}
+	if err == nil && limits.MaxDownloadBytes > 0 && written > limits.MaxDownloadBytes {
+		err = fmt.Errorf("%w: more than %d bytes", ErrDownloadTooLarge, limits.MaxDownloadBytes)
+	} else if err != nil {
+		err = fmt.Errorf("failed to save zip: %w", err)
+	}
+	if err != nil {
+		tmpFile.Close()
+		os.Remove(tmpFile.Name())
+		return nil, err
+	}
+
+	keys := []string{key}
+	commit := info.Commit
+	if commit == "" {
+		commit = archiveCommit(tmpFile.Name())
+		if commit != "" {
+			keys = append(keys, commitArchiveKey(info, commit))
+		}
+	}
+	// The download stays open, so it is read even if pruned once stored.
+	if _, err := cache.store(tmpFile.Name(), keys, commit); err == nil {
+		return &archive{file: tmpFile}, nil
+	}
+	return &archive{file: tmpFile, temp: true}, nil
+}
+
+// extractZip extracts the zip file to target directory
+func extractZip(r *zip.Reader, targetDir string, info *RepoInfo, limits config.ArchiveLimits) error {
+	rootPrefix := archiveRootPrefix(r, info)
+
 	if info.FilePath != "" {
-		return extractSkillFile(r, rootPrefix, targetDir, info.FilePath)
+		return extractSkillFile(r, rootPrefix, targetDir, info.FilePath, limits)
 	}
 
 	subPath := ""
@@ -308,11 +383,48 @@ func extractZip(zipPath, targetDir string, info *RepoInfo) error {
 
 	fullPrefix := rootPrefix + subPath
 
+	extractedFiles, err := extractTree(r, fullPrefix, targetDir, limits)
+	if err != nil {
+		return err
+	}
+
+	// Verify SKILL.md exists
+	skillMdPath := filepath.Join(targetDir, "SKILL.md")
+	if _, err := os.Stat(skillMdPath); os.IsNotExist(err) {
+		// Clean up
+		os.RemoveAll(targetDir)
+		if extractedFiles == 0 {
+			return fmt.Errorf("no files found at path '%s' - check if the path is correct", info.Path)
+		}
+		return fmt.Errorf("no SKILL.md found - this doesn't appear to be a valid skill")
+	}
+
+	return nil
+}
+
+// archiveRootPrefix returns the top-level directory of a GitHub archive.
+func archiveRootPrefix(r *zip.Reader, info *RepoInfo) string {
+	// Find the actual root prefix from the zip (it might vary)
+	for _, f := range r.File {
+		// First entry should be the root directory
+		if strings.Count(f.Name, "/") == 1 && strings.HasSuffix(f.Name, "/") {
+			return f.Name
+		}
+	}
+
+	// Fallback to expected format
+	return fmt.Sprintf("%s-%s/", info.Repo, info.Branch)
+}
+
+// extractTree extracts the entries under fullPrefix into targetDir within
+// the archive limits and returns the number of files written.
+func extractTree(r *zip.Reader, fullPrefix, targetDir string, limits config.ArchiveLimits) (int, error) {
 	// Create target directory
 	if err := os.MkdirAll(targetDir, 0755); err != nil {
-		return err
+		return 0, err
 	}
 
+	budget := newExtractBudget(limits)
 	extractedFiles := 0
 	for _, f := range r.File {
 		// Skip files not in the target path
@@ -328,55 +440,39 @@ func extractZip(zipPath, targetDir string, info *RepoInfo) error {
 
 		targetPath := filepath.Join(targetDir, relPath)
 		if !isWithinDir(targetDir, targetPath) {
-			return fmt.Errorf("zip entry escapes target dir: %s", relPath)
+			return 0, fmt.Errorf("zip entry escapes target dir: %s", relPath)
+		}
+		if err := checkEntryMode(f, relPath); err != nil {
+			os.RemoveAll(targetDir)
+			return 0, err
 		}
 
 		if f.FileInfo().IsDir() {
-			os.MkdirAll(targetPath, f.Mode())
+			os.MkdirAll(targetPath, 0755)
 			continue
 		}
 
-		// Create parent directories
-		if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
-			return err
+		if err := budget.addFile(relPath, f.UncompressedSize64); err != nil {
+			os.RemoveAll(targetDir)
+			return 0, err
 		}
 
-		// Extract file
-		outFile, err := os.OpenFile(targetPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
-		if err != nil {
-			return err
-		}
-
-		rc, err := f.Open()
-		if err != nil {
-			outFile.Close()
-			return err
+		// Create parent directories
+		if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
+			return 0, err
 		}
 
-		_, err = io.Copy(outFile, rc)
-		outFile.Close()
-		rc.Close()
-		if err != nil {
-			return err
+		if err := extractEntry(f, targetPath, relPath, budget); err != nil {
+			os.RemoveAll(targetDir)
+			return 0, err
 		}
 		extractedFiles++
 	}
 
-	// Verify SKILL.md exists
-	skillMdPath := filepath.Join(targetDir, "SKILL.md")
-	if _, err := os.Stat(skillMdPath); os.IsNotExist(err) {
-		// Clean up
-		os.RemoveAll(targetDir)
-		if extractedFiles == 0 {
-			return fmt.Errorf("no files found at path '%s' - check if the path is correct", info.Path)
-		}
-		return fmt.Errorf("no SKILL.md found - this doesn't appear to be a valid skill")
-	}
-
-	return nil
+	return extractedFiles, nil
 }
 
-func extractSkillFile(r *zip.ReadCloser, rootPrefix, targetDir, filePath string) error {
+func extractSkillFile(r *zip.Reader, rootPrefix, targetDir, filePath string, limits config.ArchiveLimits) error {
 	wanted := rootPrefix + strings.Trim(filePath, "/")
 
 	for _, f := range r.File {
@@ -384,6 +480,14 @@ func extractSkillFile(r *zip.ReadCloser, rootPrefix, targetDir, filePath string)
 			continue
 		}
 
+		if err := checkEntryMode(f, filePath); err != nil {
+			return err
+		}
+		budget := newExtractBudget(limits)
+		if err := budget.addFile(filePath, f.UncompressedSize64); err != nil {
+			return err
+		}
+
 		if err := os.MkdirAll(targetDir, 0755); err != nil {
 			return err
 		}
@@ -392,25 +496,11 @@ func extractSkillFile(r *zip.ReadCloser, rootPrefix, targetDir, filePath string)
 			return fmt.Errorf("zip entry escapes target dir: %s", filePath)
 		}
 
-		outFile, err := os.OpenFile(targetPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, f.Mode())
-		if err != nil {
-			return err
-		}
-		rc, err := f.Open()
-		if err != nil {
-			if closeErr := outFile.Close(); closeErr != nil {
-				return closeErr
-			}
+		if err := extractEntry(f, targetPath, filePath, budget); err != nil {
+			_ = os.RemoveAll(targetDir)
 			return err
 		}
-		_, err = io.Copy(outFile, rc)
-		if closeErr := rc.Close(); err == nil && closeErr != nil {
-			err = closeErr
-		}
-		if closeErr := outFile.Close(); err == nil && closeErr != nil {
-			err = closeErr
-		}
-		return err
+		return nil
 	}
 
 	_ = os.RemoveAll(targetDir)
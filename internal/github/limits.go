@@ -0,0 +1,138 @@
+package github
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/majiayu000/caude-skill-manager/internal/config"
+)
+
+var archiveBaseURL = "https://github.com"
+
+// Archive safety errors. Extraction stops at the first one and does not
+// try alternative skill paths.
+var (
+	ErrDownloadTooLarge = errors.New("archive download exceeds size limit")
+	ErrArchiveTooLarge  = errors.New("extracted skill exceeds total size limit")
+	ErrTooManyFiles     = errors.New("extracted skill exceeds file count limit")
+	ErrFileTooLarge     = errors.New("archive entry exceeds per-file size limit")
+	ErrUnsafeEntry      = errors.New("archive entry type is not allowed")
+)
+
+func isArchiveSafetyError(err error) bool {
+	return errors.Is(err, ErrDownloadTooLarge) ||
+		errors.Is(err, ErrArchiveTooLarge) ||
+		errors.Is(err, ErrTooManyFiles) ||
+		errors.Is(err, ErrFileTooLarge) ||
+		errors.Is(err, ErrUnsafeEntry)
+}
+
+// checkEntryMode rejects symlinks, devices, pipes, sockets and setuid or
+// setgid entries. Permissions from the archive are otherwise ignored.
+func checkEntryMode(f *zip.File, relPath string) error {
+	mode := f.Mode()
+	switch {
+	case mode&os.ModeSymlink != 0:
+		return fmt.Errorf("%w: %s is a symlink", ErrUnsafeEntry, relPath)
+	case mode&(os.ModeDevice|os.ModeCharDevice) != 0:
+		return fmt.Errorf("%w: %s is a device", ErrUnsafeEntry, relPath)
+	case mode&(os.ModeNamedPipe|os.ModeSocket|os.ModeIrregular) != 0:
+		return fmt.Errorf("%w: %s is a special file", ErrUnsafeEntry, relPath)
+	case mode&(os.ModeSetuid|os.ModeSetgid) != 0:
+		return fmt.Errorf("%w: %s has setuid/setgid bits", ErrUnsafeEntry, relPath)
+	}
+	return nil
+}
+
+// normalizedFileMode returns 0755 for entries with any execute bit and 0644
+// otherwise.
+func normalizedFileMode(f *zip.File) os.FileMode {
+	if f.Mode().Perm()&0111 != 0 {
+		return 0755
+	}
+	return 0644
+}
+
+// extractBudget tracks extraction against the configured limits.
+type extractBudget struct {
+	limits config.ArchiveLimits
+	files  int
+	bytes  int64
+}
+
+func newExtractBudget(limits config.ArchiveLimits) *extractBudget {
+	return &extractBudget{limits: limits}
+}
+
+// addFile checks an entry's declared size and counts it. Declared sizes can
+// lie, so extractEntry enforces the limits again while copying.
+func (b *extractBudget) addFile(relPath string, declared uint64) error {
+	b.files++
+	if b.limits.MaxFiles > 0 && b.files > b.limits.MaxFiles {
+		return fmt.Errorf("%w: more than %d files", ErrTooManyFiles, b.limits.MaxFiles)
+	}
+	if b.limits.MaxFileBytes > 0 && declared > uint64(b.limits.MaxFileBytes) {
+		return fmt.Errorf("%w: %s is %d bytes (limit %d)", ErrFileTooLarge, relPath, declared, b.limits.MaxFileBytes)
+	}
+	if b.limits.MaxUncompressedBytes > 0 && uint64(b.bytes)+declared > uint64(b.limits.MaxUncompressedBytes) {
+		return fmt.Errorf("%w: more than %d bytes", ErrArchiveTooLarge, b.limits.MaxUncompressedBytes)
+	}
+	return nil
+}
+
+// remaining returns how many more bytes relPath may write.
+func (b *extractBudget) remaining() int64 {
+	remaining := int64(-1)
+	if b.limits.MaxFileBytes > 0 {
+		remaining = b.limits.MaxFileBytes
+	}
+	if b.limits.MaxUncompressedBytes > 0 {
+		total := b.limits.MaxUncompressedBytes - b.bytes
+		if remaining < 0 || total < remaining {
+			remaining = total
+		}
+	}
+	return remaining
+}
+
+// extractEntry writes one regular file with normalised permissions,
+// stopping as soon as it exceeds the per-file or total size budget.
+func extractEntry(f *zip.File, targetPath, relPath string, budget *extractBudget) error {
+	outFile, err := os.OpenFile(targetPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, normalizedFileMode(f))
+	if err != nil {
+		return err
+	}
+	rc, err := f.Open()
+	if err != nil {
+		outFile.Close()
+		return err
+	}
+
+	var src io.Reader = rc
+	remaining := budget.remaining()
+	if remaining >= 0 {
+		src = io.LimitReader(rc, remaining+1)
+	}
+	written, err := io.Copy(outFile, src)
+	if closeErr := rc.Close(); err == nil && closeErr != nil {
+		err = closeErr
+	}
+	if closeErr := outFile.Close(); err == nil && closeErr != nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	budget.bytes += written
+	if remaining >= 0 && written > remaining {
+		if budget.limits.MaxFileBytes > 0 && written > budget.limits.MaxFileBytes {
+			return fmt.Errorf("%w: %s (limit %d bytes)", ErrFileTooLarge, relPath, budget.limits.MaxFileBytes)
+		}
+		return fmt.Errorf("%w: more than %d bytes", ErrArchiveTooLarge, budget.limits.MaxUncompressedBytes)
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package github
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/majiayu000/caude-skill-manager/internal/config"
+)
+
+type zipFixtureEntry struct {
+	Name    string
+	Body    string
+	Mode    os.FileMode
+	Symlink bool
+}
+
+func writeZipFixture(t *testing.T, entries []zipFixtureEntry) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, entry := range entries {
+		header := &zip.FileHeader{Name: entry.Name, Method: zip.Deflate}
+		mode := entry.Mode
+		if mode == 0 {
+			mode = 0644
+		}
+		if entry.Symlink {
+			mode |= os.ModeSymlink
+		}
+		if strings.HasSuffix(entry.Name, "/") {
+			mode |= os.ModeDir | 0755
+		}
+		header.SetMode(mode)
+		fw, err := w.CreateHeader(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write([]byte(entry.Body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "fixture.zip")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func testLimits() config.ArchiveLimits {
+	return config.ArchiveLimits{
+		MaxDownloadBytes:     1 << 20,
+		MaxUncompressedBytes: 1 << 16,
+		MaxFiles:             10,
+		MaxFileBytes:         1 << 12,
+	}
+}
+
+func extractFixture(t *testing.T, entries []zipFixtureEntry, limits config.ArchiveLimits) (string, error) {
+	t.Helper()
+	zipPath := writeZipFixture(t, append([]zipFixtureEntry{{Name: "repo-main/"}}, entries...))
+	target := filepath.Join(t.TempDir(), "skill")
+	info := &RepoInfo{Owner: "owner", Repo: "repo", Branch: "main"}
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	return target, extractZip(&r.Reader, target, info, limits)
+}
+
+func TestExtractZipNormalizesPermissions(t *testing.T) {
+	target, err := extractFixture(t, []zipFixtureEntry{
+		{Name: "repo-main/SKILL.md", Body: "---\nname: demo\n---\n", Mode: 0666},
+		{Name: "repo-main/scripts/run.sh", Body: "#!/bin/sh\n", Mode: 0777},
+	}, testLimits())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for rel, want := range map[string]os.FileMode{"SKILL.md": 0644, "scripts/run.sh": 0755} {
+		info, err := os.Stat(filepath.Join(target, rel))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != want {
+			t.Fatalf("%s mode = %v, want %v", rel, info.Mode().Perm(), want)
+		}
+	}
+}
+
+func TestExtractZipRejectsUnsafeEntries(t *testing.T) {
+	cases := map[string]zipFixtureEntry{
+		"symlink": {Name: "repo-main/link", Body: "/etc/passwd", Symlink: true},
+		"setuid":  {Name: "repo-main/tool", Body: "x", Mode: 0755 | os.ModeSetuid},
+		"device":  {Name: "repo-main/dev", Mode: 0644 | os.ModeDevice},
+		"pipe":    {Name: "repo-main/fifo", Mode: 0644 | os.ModeNamedPipe},
+	}
+	for name, entry := range cases {
+		t.Run(name, func(t *testing.T) {
+			target, err := extractFixture(t, []zipFixtureEntry{
+				{Name: "repo-main/SKILL.md", Body: "---\nname: demo\n---\n"},
+				entry,
+			}, testLimits())
+			if !errors.Is(err, ErrUnsafeEntry) {
+				t.Fatalf("expected ErrUnsafeEntry, got %v", err)
+			}
+			if _, statErr := os.Stat(target); !os.IsNotExist(statErr) {
+				t.Fatalf("expected target to be removed, stat err = %v", statErr)
+			}
+		})
+	}
+}
+
+func TestExtractZipEnforcesLimits(t *testing.T) {
+	limits := testLimits()
+	manyFiles := []zipFixtureEntry{{Name: "repo-main/SKILL.md", Body: "x"}}
+	for i := 0; i < limits.MaxFiles; i++ {
+		manyFiles = append(manyFiles, zipFixtureEntry{Name: "repo-main/f" + string(rune('a'+i)), Body: "x"})
+	}
+	bigFiles := []zipFixtureEntry{{Name: "repo-main/SKILL.md", Body: "x"}}
+	for i := 0; i < 9; i++ {
+		bigFiles = append(bigFiles, zipFixtureEntry{
+			Name: "repo-main/big" + string(rune('a'+i)),
+			Body: strings.Repeat("a", int(limits.MaxFileBytes)),
+		})
+	}
+	totalLimits := limits
+	totalLimits.MaxUncompressedBytes = 4 * limits.MaxFileBytes
+
+	cases := []struct {
+		name    string
+		entries []zipFixtureEntry
+		limits  config.ArchiveLimits
+		want    error
+	}{
+		{"file count", manyFiles, limits, ErrTooManyFiles},
+		{"file size", []zipFixtureEntry{
+			{Name: "repo-main/SKILL.md", Body: strings.Repeat("a", int(limits.MaxFileBytes)+1)},
+		}, limits, ErrFileTooLarge},
+		{"total size", bigFiles, totalLimits, ErrArchiveTooLarge},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := extractFixture(t, tc.entries, tc.limits)
+			if !errors.Is(err, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestDownloadArchiveRejectsOversizedBody(t *testing.T) {
+	body := strings.Repeat("z", 2048)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Chunked response without Content-Length exercises the streaming limit.
+		w.(http.Flusher).Flush()
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	oldBase := archiveBaseURL
+	archiveBaseURL = server.URL
+	t.Cleanup(func() { archiveBaseURL = oldBase })
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	limits := testLimits()
+	limits.MaxDownloadBytes = 1024
+	_, err := downloadArchive(&RepoInfo{Owner: "owner", Repo: "repo", Branch: "main"}, "main", limits)
+	if !errors.Is(err, ErrDownloadTooLarge) {
+		t.Fatalf("expected ErrDownloadTooLarge, got %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package github
+
+import (
+	"io"
+	"sync"
+)
+
+// DownloadProgress describes a repository archive download.
+type DownloadProgress struct {
+	Repo  string // owner/repo
+	Ref   string // the commit requested, or else the branch, before any fallback
+	Bytes int64  // received so far
+	Total int64  // from Content-Length; 0 when unknown
+	Done  bool   // the download finished or failed
+}
+
+var (
+	downloadProgressMu      sync.Mutex
+	downloadProgressHandler func(DownloadProgress)
+)
+
+// SetDownloadProgressHandler installs fn to be called as archive downloads
+// receive data, ending with a call where Done is set. Calls are serialised.
+// A nil fn stops reporting.
+func SetDownloadProgressHandler(fn func(DownloadProgress)) {
+	downloadProgressMu.Lock()
+	defer downloadProgressMu.Unlock()
+	downloadProgressHandler = fn
+}
+
+func reportDownload(p DownloadProgress) {
+	downloadProgressMu.Lock()
+	defer downloadProgressMu.Unlock()
+	if downloadProgressHandler != nil {
+		downloadProgressHandler(p)
+	}
+}
+
+// progressReader reports the bytes read through it.
+type progressReader struct {
+	r        io.Reader
+	progress DownloadProgress
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.progress.Bytes += int64(n)
+		reportDownload(p.progress)
+	}
+	return n, err
+}
+
+// finish reports the end of the download.
+func (p *progressReader) finish() {
+	p.progress.Done = true
+	reportDownload(p.progress)
+}
@@ -0,0 +1,283 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/majiayu000/caude-skill-manager/internal/config"
+	"github.com/majiayu000/caude-skill-manager/internal/httpclient"
+)
+
+var (
+	apiBaseURL = "https://api.github.com"
+	rawBaseURL = "https://raw.githubusercontent.com"
+)
+
+// Endpoint is a GitHub host sk connects to.
+type Endpoint struct {
+	Name string
+	URL  string
+}
+
+// Endpoints returns the GitHub hosts used for API calls, raw files and
+// archive downloads.
+func Endpoints() []Endpoint {
+	return []Endpoint{
+		{Name: "GitHub API", URL: apiBaseURL},
+		{Name: "GitHub raw files", URL: rawBaseURL},
+		{Name: "GitHub archives", URL: archiveBaseURL},
+	}
+}
+
+// RemoteFile is one file that installing a skill would write.
+type RemoteFile struct {
+	Path string // relative to the skill directory
+	Size int64
+}
+
+// RemoteSkill describes a skill as it exists in a GitHub repository,
+// before it is installed.
+type RemoteSkill struct {
+	Owner     string
+	Repo      string
+	Branch    string
+	Path      string // resolved skill directory inside the repository
+	SkillFile string // repository path of the SKILL.md-like file
+	Files     []RemoteFile
+	Truncated bool // GitHub truncated the tree listing
+}
+
+type treeEntry struct {
+	Path string `json:"path"`
+	Type string `json:"type"`
+	Size int64  `json:"size"`
+}
+
+type treeResponse struct {
+	Tree      []treeEntry `json:"tree"`
+	Truncated bool        `json:"truncated"`
+}
+
+// ResolveRemoteSkill locates the skill described by info in the repository
+// tree, applying the same branch and path fallbacks as DownloadAndExtract.
+func ResolveRemoteSkill(info *RepoInfo) (*RemoteSkill, error) {
+	if config.IsOffline() {
+		return nil, config.ErrOffline
+	}
+	var lastErr error
+	for _, candidate := range refCandidates(info) {
+		tree, err := fetchTree(candidate.Owner, candidate.Repo, candidate.Branch)
+		if err != nil && candidate.Branch == "main" && candidate.TreeRef == "" {
+			candidate.Branch = "master"
+			tree, err = fetchTree(candidate.Owner, candidate.Repo, candidate.Branch)
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		remote, err := findSkillInTree(&candidate, tree)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return remote, nil
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("skill not found")
+	}
+	return nil, lastErr
+}
+
+// ReadFile downloads a file from the skill directory. rel is relative to the
+// skill directory; "SKILL.md" always maps to the skill's SKILL.md-like file.
+func (s *RemoteSkill) ReadFile(rel string) ([]byte, error) {
+	repoPath := s.SkillFile
+	if rel != "SKILL.md" {
+		repoPath = joinRepoPath(s.Path, rel)
+	}
+	return fetchRaw(s.Owner, s.Repo, s.Branch, repoPath)
+}
+
+// TotalSize returns the sum of all file sizes.
+func (s *RemoteSkill) TotalSize() int64 {
+	var total int64
+	for _, f := range s.Files {
+		total += f.Size
+	}
+	return total
+}
+
+// refCandidates returns the branch/path combinations to try for info, in
+// order. Ambiguous tree refs expand to every branch split, longest first.
+func refCandidates(info *RepoInfo) []RepoInfo {
+	if info.TreeRef == "" || !info.TreeRefAmbiguous {
+		return []RepoInfo{*info}
+	}
+
+	candidates := []RepoInfo{*info}
+	parts := strings.Split(info.TreeRef, "/")
+	for i := len(parts); i >= 1; i-- {
+		infoCopy := *info
+		infoCopy.Branch = strings.Join(parts[:i], "/")
+		infoCopy.Path = ""
+		if i < len(parts) {
+			infoCopy.Path = strings.Join(parts[i:], "/")
+		}
+		if infoCopy.Branch == info.Branch && infoCopy.Path == info.Path {
+			continue
+		}
+		candidates = append(candidates, infoCopy)
+	}
+	return candidates
+}
+
+// Candidate is a ref and skill path an install may try.
+type Candidate struct {
+	Ref  string // branch, or commit when pinned
+	Path string // directory in the repository; "" for the root
+	When string // condition under which it is tried, "" for always
+}
+
+// InstallCandidates returns, in order, the ref and path combinations
+// DownloadAndExtractTo tries for info: the path as given and under skills/
+// and skill/, the same on master when an unpinned main does not exist, and
+// the other splits of an ambiguous tree ref.
+func InstallCandidates(info *RepoInfo) []Candidate {
+	ref := info.Branch
+	if info.Commit != "" {
+		ref = info.Commit
+	}
+	var candidates []Candidate
+	for _, path := range skillPathCandidates(info.Path) {
+		candidates = append(candidates, Candidate{Ref: ref, Path: path})
+	}
+	if info.Branch == "main" && info.Commit == "" {
+		for _, path := range skillPathCandidates(info.Path) {
+			candidates = append(candidates, Candidate{Ref: "master", Path: path, When: "if main does not exist"})
+		}
+	}
+	if info.TreeRef != "" && info.TreeRefAmbiguous {
+		for _, split := range refCandidates(info)[1:] {
+			candidates = append(candidates, Candidate{Ref: split.Branch, Path: split.Path, When: "if the tree ref splits here"})
+		}
+	}
+	return candidates
+}
+
+// skillPathCandidates returns the directory paths tried for info.Path.
+func skillPathCandidates(path string) []string {
+	if path == "" {
+		return []string{""}
+	}
+	return []string{path, "skills/" + path, "skill/" + path}
+}
+
+func findSkillInTree(info *RepoInfo, tree *treeResponse) (*RemoteSkill, error) {
+	remote := &RemoteSkill{
+		Owner:     info.Owner,
+		Repo:      info.Repo,
+		Branch:    info.Branch,
+		Truncated: tree.Truncated,
+	}
+
+	if info.FilePath != "" {
+		wanted := strings.Trim(info.FilePath, "/")
+		for _, entry := range tree.Tree {
+			if entry.Type == "blob" && entry.Path == wanted {
+				remote.SkillFile = entry.Path
+				remote.Files = []RemoteFile{{Path: "SKILL.md", Size: entry.Size}}
+				return remote, nil
+			}
+		}
+		return nil, fmt.Errorf("no file found at path '%s' - check if the path is correct", info.FilePath)
+	}
+
+	for _, dir := range skillPathCandidates(info.Path) {
+		prefix := ""
+		if dir != "" {
+			prefix = dir + "/"
+		}
+
+		var files []RemoteFile
+		hasSkillMd := false
+		for _, entry := range tree.Tree {
+			if entry.Type != "blob" || !strings.HasPrefix(entry.Path, prefix) {
+				continue
+			}
+			rel := strings.TrimPrefix(entry.Path, prefix)
+			if rel == "SKILL.md" {
+				hasSkillMd = true
+			}
+			files = append(files, RemoteFile{Path: rel, Size: entry.Size})
+		}
+		if !hasSkillMd {
+			continue
+		}
+
+		sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+		remote.Path = dir
+		remote.SkillFile = joinRepoPath(dir, "SKILL.md")
+		remote.Files = files
+		return remote, nil
+	}
+
+	if info.Path == "" {
+		return nil, fmt.Errorf("no SKILL.md found - this doesn't appear to be a valid skill")
+	}
+	return nil, fmt.Errorf("no SKILL.md found at path '%s' - check if the path is correct", info.Path)
+}
+
+func fetchTree(owner, repo, branch string) (*treeResponse, error) {
+	treeURL := fmt.Sprintf("%s/repos/%s/%s/git/trees/%s?recursive=1",
+		apiBaseURL, owner, repo, url.PathEscape(branch))
+
+	resp, err := httpclient.Get(treeURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list repository files: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if reset, ok := httpclient.RateLimitReset(resp); ok {
+		return nil, fmt.Errorf("GitHub API rate limit exceeded listing %s/%s; it resets at %s", owner, repo, reset.Format("15:04"))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("listing %s/%s@%s failed with status: %s", owner, repo, branch, resp.Status)
+	}
+
+	var tree treeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tree); err != nil {
+		return nil, fmt.Errorf("failed to parse repository tree: %w", err)
+	}
+	return &tree, nil
+}
+
+func fetchRaw(owner, repo, branch, repoPath string) ([]byte, error) {
+	if config.IsOffline() {
+		return nil, config.ErrOffline
+	}
+	rawURL := fmt.Sprintf("%s/%s/%s/%s/%s", rawBaseURL, owner, repo, branch, repoPath)
+
+	resp, err := httpclient.Get(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", repoPath, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download of %s failed with status: %s", repoPath, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func joinRepoPath(dir, name string) string {
+	if dir == "" {
+		return name
+	}
+	return dir + "/" + name
+}
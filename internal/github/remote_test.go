@@ -0,0 +1,173 @@
+package github
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestResolveRemoteSkillFallsBackToSkillsDirectory(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/anthropics/skills/git/trees/main", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"tree":[
+			{"path":"README.md","type":"blob","size":10},
+			{"path":"skills/docx","type":"tree"},
+			{"path":"skills/docx/SKILL.md","type":"blob","size":120},
+			{"path":"skills/docx/scripts/build.py","type":"blob","size":300}
+		]}`))
+	})
+	mux.HandleFunc("/anthropics/skills/main/skills/docx/SKILL.md", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("---\nname: docx\n---\nbody"))
+	})
+	stubGitHub(t, mux)
+
+	info, err := ParseGitHubURL("anthropics/skills/docx")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	remote, err := ResolveRemoteSkill(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if remote.Path != "skills/docx" {
+		t.Fatalf("unexpected resolved path: %s", remote.Path)
+	}
+	if len(remote.Files) != 2 || remote.Files[0].Path != "SKILL.md" || remote.Files[1].Path != "scripts/build.py" {
+		t.Fatalf("unexpected files: %#v", remote.Files)
+	}
+	if remote.TotalSize() != 420 {
+		t.Fatalf("unexpected total size: %d", remote.TotalSize())
+	}
+
+	content, err := remote.ReadFile("SKILL.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "name: docx") {
+		t.Fatalf("unexpected SKILL.md content: %s", content)
+	}
+}
+
+func TestResolveRemoteSkillFallsBackToMaster(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo/git/trees/main", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("/repos/owner/repo/git/trees/master", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"tree":[{"path":"SKILL.md","type":"blob","size":5}]}`))
+	})
+	stubGitHub(t, mux)
+
+	info, err := ParseGitHubURL("owner/repo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	remote, err := ResolveRemoteSkill(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if remote.Branch != "master" || remote.SkillFile != "SKILL.md" {
+		t.Fatalf("unexpected remote skill: %#v", remote)
+	}
+}
+
+func TestResolveRemoteSkillSingleFile(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo/git/trees/main", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"tree":[{"path":".agents/review_SKILL.md","type":"blob","size":42}]}`))
+	})
+	stubGitHub(t, mux)
+
+	info, err := ParseGitHubURL("owner/repo/.agents/review_SKILL.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	remote, err := ResolveRemoteSkill(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if remote.SkillFile != ".agents/review_SKILL.md" {
+		t.Fatalf("unexpected skill file: %s", remote.SkillFile)
+	}
+	if len(remote.Files) != 1 || remote.Files[0].Path != "SKILL.md" || remote.Files[0].Size != 42 {
+		t.Fatalf("unexpected files: %#v", remote.Files)
+	}
+}
+
+func TestResolveRemoteSkillMissingSkillMd(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo/git/trees/main", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"tree":[{"path":"tools/readme.md","type":"blob","size":1}]}`))
+	})
+	stubGitHub(t, mux)
+
+	info, err := ParseGitHubURL("owner/repo/tools")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ResolveRemoteSkill(info); err == nil || !strings.Contains(err.Error(), "no SKILL.md found at path 'tools'") {
+		t.Fatalf("expected missing SKILL.md error, got %v", err)
+	}
+}
+
+func TestRefCandidatesExpandsAmbiguousTreeRef(t *testing.T) {
+	info, err := ParseGitHubURL("https://github.com/owner/repo/tree/feature/foo/skills/x")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := refCandidates(info)
+	want := []struct{ branch, path string }{
+		{"feature", "foo/skills/x"},
+		{"feature/foo/skills/x", ""},
+		{"feature/foo/skills", "x"},
+		{"feature/foo", "skills/x"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d candidates, got %d: %#v", len(want), len(got), got)
+	}
+	for i, w := range want {
+		if got[i].Branch != w.branch || got[i].Path != w.path {
+			t.Fatalf("candidate %d = %s:%s, want %s:%s", i, got[i].Branch, got[i].Path, w.branch, w.path)
+		}
+	}
+}
+
+func TestInstallCandidatesListsFallbacksInOrder(t *testing.T) {
+	info, err := ParseGitHubURL("owner/repo/docx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := InstallCandidates(info)
+	want := []string{"main:docx", "main:skills/docx", "main:skill/docx", "master:docx", "master:skills/docx", "master:skill/docx"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d candidates, got %#v", len(want), got)
+	}
+	for i, w := range want {
+		if got[i].Ref+":"+got[i].Path != w {
+			t.Fatalf("candidate %d = %s:%s, want %s", i, got[i].Ref, got[i].Path, w)
+		}
+	}
+
+	info.Commit = "0123456789abcdef0123456789abcdef01234567"
+	if got := InstallCandidates(info); len(got) != 3 || got[0].Ref != info.Commit {
+		t.Fatalf("expected a pinned commit to skip the master fallback, got %#v", got)
+	}
+}
+
+func stubGitHub(t *testing.T, handler http.Handler) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	oldAPI, oldRaw := apiBaseURL, rawBaseURL
+	apiBaseURL, rawBaseURL = server.URL, server.URL
+	t.Cleanup(func() {
+		apiBaseURL, rawBaseURL = oldAPI, oldRaw
+	})
+}
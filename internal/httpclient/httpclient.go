@@ -0,0 +1,202 @@
+// Package httpclient is the HTTP client shared by registry and GitHub
+// requests. It applies the configured connect and overall timeouts and
+// retries transient failures (connection errors, 429 and 5xx responses and
+// GitHub rate limits) with exponential backoff, honouring Retry-After.
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/majiayu000/caude-skill-manager/internal/config"
+)
+
+var (
+	// RetryBackoff is the wait before the first retry; it doubles after
+	// each attempt.
+	RetryBackoff = 500 * time.Millisecond
+	// MaxRetryWait caps how long a Retry-After or rate-limit reset is waited
+	// for. Responses asking for longer are returned without retrying.
+	MaxRetryWait = 60 * time.Second
+)
+
+var (
+	clientMu sync.Mutex
+	client   *http.Client
+	settings config.Network
+)
+
+// Client returns the shared client for the current network settings. It
+// fails when the configured CA bundle or client certificate cannot be
+// loaded.
+func Client() (*http.Client, error) {
+	network := config.GetNetwork()
+	clientMu.Lock()
+	defer clientMu.Unlock()
+	if client == nil || network != settings {
+		c, err := newClient(network)
+		if err != nil {
+			return nil, err
+		}
+		client, settings = c, network
+	}
+	return client, nil
+}
+
+func newClient(network config.Network) (*http.Client, error) {
+	tlsConfig, err := loadTLSConfig(network)
+	if err != nil {
+		return nil, err
+	}
+	connect := time.Duration(network.ConnectTimeoutSeconds) * time.Second
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	transport.DialContext = (&net.Dialer{Timeout: connect, KeepAlive: 30 * time.Second}).DialContext
+	transport.TLSHandshakeTimeout = connect
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{
+		Transport: transport,
+		Timeout:   time.Duration(network.TimeoutSeconds) * time.Second,
+	}, nil
+}
+
+// Get sends a GET request for url with Do.
+func Get(url string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return Do(req)
+}
+
+// Do sends req, retrying connection errors and 429, 5xx and rate-limited
+// responses up to the configured number of retries. The response of the
+// last attempt is returned whatever its status. req must not have a body.
+func Do(req *http.Request) (*http.Response, error) {
+	if config.IsOffline() {
+		return nil, config.ErrOffline
+	}
+	c, err := Client()
+	if err != nil {
+		return nil, err
+	}
+	retries := config.GetNetwork().Retries
+	for attempt := 0; ; attempt++ {
+		resp, err := c.Do(req.Clone(req.Context()))
+		if attempt >= retries {
+			return resp, err
+		}
+		wait, retry := retryDelay(resp, err, attempt)
+		if !retry {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			_ = resp.Body.Close()
+		}
+		if err := sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Fetch sends req with Do and reads the whole body, sending the request
+// again when the connection fails while the body is read. The returned
+// response's body is already closed.
+func Fetch(req *http.Request) (*http.Response, []byte, error) {
+	retries := config.GetNetwork().Retries
+	for attempt := 0; ; attempt++ {
+		resp, err := Do(req)
+		if err != nil {
+			return nil, nil, err
+		}
+		data, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err == nil || attempt >= retries || req.Context().Err() != nil {
+			return resp, data, err
+		}
+		if err := sleep(req.Context(), RetryBackoff<<attempt); err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+// retryDelay reports whether an attempt should be retried and how long to
+// wait first.
+func retryDelay(resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	backoff := RetryBackoff << attempt
+	if err != nil {
+		var certErr *tls.CertificateVerificationError
+		return backoff, !errors.Is(err, context.Canceled) && !errors.As(err, &certErr)
+	}
+
+	if reset, ok := RateLimitReset(resp); ok {
+		wait := time.Until(reset)
+		if wait > MaxRetryWait {
+			return 0, false
+		}
+		return max(wait, backoff), true
+	}
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusForbidden && resp.StatusCode < 500 {
+		return 0, false
+	}
+	if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+		if wait > MaxRetryWait {
+			return 0, false
+		}
+		return wait, true
+	}
+	// A 403 is only transient when the server says so.
+	return backoff, resp.StatusCode != http.StatusForbidden
+}
+
+// RateLimitReset returns when an exhausted GitHub rate limit resets, for a
+// 403 or 429 response carrying X-RateLimit-Remaining: 0.
+func RateLimitReset(resp *http.Response) (time.Time, bool) {
+	if resp == nil || (resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests) {
+		return time.Time{}, false
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") != "0" {
+		return time.Time{}, false
+	}
+	reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(reset, 0), true
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an
+// HTTP date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,125 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingServer answers the n-th request (from 1) with respond, with
+// retry backoff shortened for the test.
+func countingServer(t *testing.T, respond func(w http.ResponseWriter, n int)) (*httptest.Server, *int32) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		respond(w, int(atomic.AddInt32(&requests, 1)))
+	}))
+	t.Cleanup(server.Close)
+	backoff := RetryBackoff
+	RetryBackoff = time.Millisecond
+	t.Cleanup(func() { RetryBackoff = backoff })
+	return server, &requests
+}
+
+func TestDoRetriesServerErrors(t *testing.T) {
+	server, requests := countingServer(t, func(w http.ResponseWriter, n int) {
+		if n < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	})
+
+	resp, err := Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || *requests != 3 {
+		t.Fatalf("expected success on the third request, got %d after %d", resp.StatusCode, *requests)
+	}
+}
+
+func TestDoReturnsLastResponseAfterRetries(t *testing.T) {
+	server, requests := countingServer(t, func(w http.ResponseWriter, n int) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	resp, err := Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway || *requests != 4 {
+		t.Fatalf("expected 1 request and 3 retries, got %d requests", *requests)
+	}
+}
+
+func TestDoDoesNotRetryClientErrors(t *testing.T) {
+	server, requests := countingServer(t, func(w http.ResponseWriter, n int) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	resp, err := Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if *requests != 1 {
+		t.Fatalf("expected a single request for a 404, got %d", *requests)
+	}
+}
+
+func TestDoHonoursRetryAfterAndRateLimits(t *testing.T) {
+	server, requests := countingServer(t, func(w http.ResponseWriter, n int) {
+		switch n {
+		case 1:
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Unix(), 10))
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			_, _ = w.Write([]byte("ok"))
+		}
+	})
+
+	start := time.Now()
+	resp, err := Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || *requests != 3 {
+		t.Fatalf("expected success on the third request, got %d after %d", resp.StatusCode, *requests)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Fatalf("expected to wait for Retry-After, took %s", elapsed)
+	}
+}
+
+func TestDoGivesUpOnDistantRateLimitReset(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Unix()
+	server, requests := countingServer(t, func(w http.ResponseWriter, n int) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+		w.WriteHeader(http.StatusForbidden)
+	})
+
+	resp, err := Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if *requests != 1 {
+		t.Fatalf("expected no retry for a reset an hour away, got %d requests", *requests)
+	}
+	if at, ok := RateLimitReset(resp); !ok || at.Unix() != reset {
+		t.Fatalf("expected reset %d, got %v %v", reset, at, ok)
+	}
+}
@@ -0,0 +1,89 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/majiayu000/caude-skill-manager/internal/config"
+)
+
+// loadTLSConfig builds the TLS settings for network: the system roots plus
+// ca_bundle, and client_cert/client_key for mutual TLS. It returns nil when
+// neither is configured.
+func loadTLSConfig(network config.Network) (*tls.Config, error) {
+	if network.CABundle == "" && network.ClientCert == "" && network.ClientKey == "" {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if network.CABundle != "" {
+		pem, err := os.ReadFile(network.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("network.ca_bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("network.ca_bundle: no PEM certificates in %s", network.CABundle)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if network.ClientCert != "" || network.ClientKey != "" {
+		if network.ClientCert == "" || network.ClientKey == "" {
+			return nil, errors.New("network.client_cert and network.client_key must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(network.ClientCert, network.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("network.client_cert: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// ProxyFor returns the proxy requests to rawURL go through, or nil for a
+// direct connection, as decided by HTTPS_PROXY, HTTP_PROXY and NO_PROXY.
+func ProxyFor(rawURL string) (*url.URL, error) {
+	req, err := http.NewRequest(http.MethodHead, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	return http.ProxyFromEnvironment(req)
+}
+
+// Probe sends a single HEAD request to rawURL, without retries, and returns
+// the response status and how long it took. Any response shows the endpoint
+// is reachable with the configured proxy and TLS settings, whatever its
+// status.
+func Probe(rawURL string) (int, time.Duration, error) {
+	if config.IsOffline() {
+		return 0, 0, config.ErrOffline
+	}
+	c, err := Client()
+	if err != nil {
+		return 0, 0, err
+	}
+	start := time.Now()
+	resp, err := c.Head(rawURL)
+	if err != nil {
+		return 0, time.Since(start), err
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode, time.Since(start), nil
+}
+
+// IsUnknownAuthority reports whether err is a certificate signed by a CA
+// that is not trusted, which usually means ca_bundle needs setting.
+func IsUnknownAuthority(err error) bool {
+	var unknown x509.UnknownAuthorityError
+	return errors.As(err, &unknown)
+}
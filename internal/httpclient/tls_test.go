@@ -0,0 +1,119 @@
+package httpclient
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/majiayu000/caude-skill-manager/internal/config"
+)
+
+func writeNetworkConfig(t *testing.T, home string, network config.Network) {
+	t.Helper()
+	data, err := json.Marshal(map[string]any{"network": network})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(home, ".skrc"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writePEM(t *testing.T, path, kind string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: kind, Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCABundleTrustsPrivateCA(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	if _, err := Get(server.URL); !IsUnknownAuthority(err) {
+		t.Fatalf("expected an unknown authority error without ca_bundle, got %v", err)
+	}
+
+	bundle := filepath.Join(home, "ca.pem")
+	writePEM(t, bundle, "CERTIFICATE", server.Certificate().Raw)
+	writeNetworkConfig(t, home, config.Network{CABundle: "~/ca.pem"})
+
+	resp, err := Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected ca_bundle to be trusted: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestClientCertificateIsPresented(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sk test client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(cert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	writePEM(t, filepath.Join(home, "ca.pem"), "CERTIFICATE", server.Certificate().Raw)
+	writePEM(t, filepath.Join(home, "client.pem"), "CERTIFICATE", der)
+	writePEM(t, filepath.Join(home, "client.key"), "EC PRIVATE KEY", keyDER)
+
+	writeNetworkConfig(t, home, config.Network{CABundle: "~/ca.pem", Retries: -1})
+	if _, err := Get(server.URL); err == nil {
+		t.Fatal("expected the server to reject a request without a client certificate")
+	}
+
+	writeNetworkConfig(t, home, config.Network{CABundle: "~/ca.pem", ClientCert: "~/client.pem", ClientKey: "~/client.key"})
+	resp, err := Get(server.URL)
+	if err != nil {
+		t.Fatalf("expected mutual TLS to succeed: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestIncompleteClientCertificateIsAnError(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	writeNetworkConfig(t, home, config.Network{ClientCert: "~/client.pem"})
+
+	if _, err := Client(); err == nil {
+		t.Fatal("expected client_cert without client_key to be rejected")
+	}
+}
@@ -0,0 +1,125 @@
+// Package integrity computes content digests for skill directories.
+package integrity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Algorithm is the prefix of every digest this package produces.
+const Algorithm = "sha256"
+
+// MismatchError reports a skill tree whose digest differs from the expected one.
+type MismatchError struct {
+	Expected string
+	Actual   string
+}
+
+func (e *MismatchError) Error() string {
+	return fmt.Sprintf("content digest mismatch: expected %s, got %s", e.Expected, e.Actual)
+}
+
+// DigestDir returns the content digest of the skill tree at root.
+//
+// Each regular file contributes a line "<sha256 hex>  <slash path>\n", in
+// byte order of path, and the digest is the sha256 of those lines. This is
+// the output of `sha256sum` over the sorted relative paths, so publishers can
+// reproduce it without sk. Top-level names in exclude are skipped, files or
+// whole directories: installs exclude the install record, and registry
+// builds also exclude .git, which GitHub archives never contain.
+func DigestDir(root string, exclude ...string) (string, error) {
+	skip := make(map[string]bool, len(exclude))
+	for _, name := range exclude {
+		skip[name] = true
+	}
+
+	var files []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if skip[rel] {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return fmt.Errorf("cannot digest non-regular file: %s", rel)
+		}
+		files = append(files, rel)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(files)
+
+	sum := sha256.New()
+	for _, rel := range files {
+		fileSum, err := hashFile(filepath.Join(root, filepath.FromSlash(rel)))
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(sum, "%s  %s\n", fileSum, rel)
+	}
+	return Algorithm + ":" + hex.EncodeToString(sum.Sum(nil)), nil
+}
+
+// Verify computes the digest of root and compares it with expected.
+// It returns the computed digest, and a *MismatchError when they differ.
+func Verify(root, expected string, exclude ...string) (string, error) {
+	actual, err := DigestDir(root, exclude...)
+	if err != nil {
+		return "", err
+	}
+	if !Equal(expected, actual) {
+		return actual, &MismatchError{Expected: expected, Actual: actual}
+	}
+	return actual, nil
+}
+
+// Equal compares two digests, accepting a bare hex value for sha256.
+func Equal(a, b string) bool {
+	return normalize(a) == normalize(b)
+}
+
+func normalize(digest string) string {
+	digest = strings.ToLower(strings.TrimSpace(digest))
+	if !strings.Contains(digest, ":") {
+		digest = Algorithm + ":" + digest
+	}
+	return digest
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	sum := sha256.New()
+	if _, err := io.Copy(sum, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
@@ -0,0 +1,72 @@
+package integrity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for rel, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func sha(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestDigestDirMatchesSha256sumListing(t *testing.T) {
+	root := writeTree(t, map[string]string{
+		"SKILL.md":         "skill",
+		"scripts/run.sh":   "run",
+		".sk-meta.json":    "{}",
+		"reference/doc.md": "doc",
+	})
+
+	got, err := DigestDir(root, ".sk-meta.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listing := sha("skill") + "  SKILL.md\n" +
+		sha("doc") + "  reference/doc.md\n" +
+		sha("run") + "  scripts/run.sh\n"
+	if want := "sha256:" + sha(listing); got != want {
+		t.Fatalf("DigestDir = %s, want %s", got, want)
+	}
+}
+
+func TestVerifyReportsMismatch(t *testing.T) {
+	root := writeTree(t, map[string]string{"SKILL.md": "skill"})
+	digest, err := DigestDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bare := digest[len("sha256:"):]
+	if _, err := Verify(root, bare); err != nil {
+		t.Fatalf("expected bare hex digest to verify, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "SKILL.md"), []byte("changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = Verify(root, digest)
+	var mismatch *MismatchError
+	if !errors.As(err, &mismatch) || mismatch.Expected != digest {
+		t.Fatalf("expected MismatchError, got %v", err)
+	}
+}
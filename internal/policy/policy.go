@@ -0,0 +1,148 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/majiayu000/caude-skill-manager/internal/config"
+)
+
+// Subject is an install source checked against the policy.
+type Subject struct {
+	Owner    string
+	Repo     string // owner/repo
+	Category string // empty for direct GitHub refs and unsigned registries
+}
+
+// Source is one set of rules and where it was loaded from.
+type Source struct {
+	Origin string
+	Rules  config.Policy
+}
+
+// Policy is the effective trust policy. Every source must allow a subject.
+type Policy struct {
+	Sources []Source
+}
+
+// Violation explains which rule blocked an install.
+type Violation struct {
+	Subject Subject
+	Origin  string
+	Field   string
+	Value   string // matching entry; empty for allowlist misses
+	Reason  string
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("blocked by policy: %s (%s in %s)", v.Reason, v.Field, v.Origin)
+}
+
+// Load reads the policy from ~/.skrc and the optional org-wide policy file.
+// A ~/.skrc or configured policy file that cannot be read or parsed is an
+// error, so a broken or missing policy never silently allows everything.
+func Load() (*Policy, error) {
+	p := &Policy{}
+	cfg, err := config.LoadStrict()
+	if err != nil {
+		return nil, err
+	}
+	if !isEmpty(cfg.Policy) {
+		p.Sources = append(p.Sources, Source{Origin: config.ConfigPath(), Rules: cfg.Policy})
+	}
+
+	if file := cfg.GetPolicyFile(); file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read policy file: %w", err)
+		}
+		var rules config.Policy
+		if err := json.Unmarshal(data, &rules); err != nil {
+			return nil, fmt.Errorf("failed to parse policy file %s: %w", file, err)
+		}
+		p.Sources = append(p.Sources, Source{Origin: file, Rules: rules})
+	}
+	return p, nil
+}
+
+// Empty reports whether no rules are configured.
+func (p *Policy) Empty() bool {
+	return len(p.Sources) == 0
+}
+
+// Check returns a *Violation if any policy source blocks the subject.
+func (p *Policy) Check(s Subject) error {
+	for _, src := range p.Sources {
+		if v := checkSource(src, s); v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+func checkSource(src Source, s Subject) *Violation {
+	r := src.Rules
+	violation := func(field, value, reason string) *Violation {
+		return &Violation{Subject: s, Origin: src.Origin, Field: field, Value: value, Reason: reason}
+	}
+
+	// Denials win over allowances.
+	if m := match(r.DeniedOwners, s.Owner); m != "" {
+		return violation("denied_owners", m, fmt.Sprintf("owner '%s' is denied", s.Owner))
+	}
+	if m := match(r.DeniedRepos, s.Repo); m != "" {
+		return violation("denied_repos", m, fmt.Sprintf("repository '%s' is denied", s.Repo))
+	}
+	if s.Category != "" {
+		if m := match(r.DeniedCategories, s.Category); m != "" {
+			return violation("denied_categories", m, fmt.Sprintf("category '%s' is denied", s.Category))
+		}
+	}
+
+	// Owner and repo allowlists are alternatives: either may admit a source.
+	if len(r.AllowedOwners) > 0 || len(r.AllowedRepos) > 0 {
+		if match(r.AllowedOwners, s.Owner) == "" && match(r.AllowedRepos, s.Repo) == "" {
+			field := "allowed_owners"
+			if len(r.AllowedOwners) == 0 {
+				field = "allowed_repos"
+			} else if len(r.AllowedRepos) > 0 {
+				field = "allowed_owners/allowed_repos"
+			}
+			return violation(field, "", fmt.Sprintf("repository '%s' is not in the allowlist", s.Repo))
+		}
+	}
+
+	if len(r.AllowedCategories) > 0 {
+		if s.Category == "" {
+			return violation("allowed_categories", "", "category is unknown; it is only taken from registries with a pinned public key")
+		}
+		if match(r.AllowedCategories, s.Category) == "" {
+			return violation("allowed_categories", "", fmt.Sprintf("category '%s' is not in the allowlist", s.Category))
+		}
+	}
+	return nil
+}
+
+// match returns the first pattern that matches value, or "".
+func match(patterns []string, value string) string {
+	value = strings.ToLower(value)
+	for _, pattern := range patterns {
+		p := strings.ToLower(strings.TrimSpace(pattern))
+		if p == value {
+			return pattern
+		}
+		if ok, err := path.Match(p, value); err == nil && ok {
+			return pattern
+		}
+	}
+	return ""
+}
+
+func isEmpty(r config.Policy) bool {
+	return len(r.AllowedOwners) == 0 && len(r.DeniedOwners) == 0 &&
+		len(r.AllowedRepos) == 0 && len(r.DeniedRepos) == 0 &&
+		len(r.AllowedCategories) == 0 && len(r.DeniedCategories) == 0
+}
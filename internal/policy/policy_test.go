@@ -0,0 +1,125 @@
+package policy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/majiayu000/caude-skill-manager/internal/config"
+)
+
+func TestCheckDenyWinsOverAllow(t *testing.T) {
+	p := &Policy{Sources: []Source{{
+		Origin: "test",
+		Rules: config.Policy{
+			AllowedOwners: []string{"anthropics"},
+			DeniedRepos:   []string{"anthropics/experimental-*"},
+		},
+	}}}
+
+	err := p.Check(Subject{Owner: "anthropics", Repo: "anthropics/experimental-skills"})
+	var v *Violation
+	if !errors.As(err, &v) {
+		t.Fatalf("expected violation, got %v", err)
+	}
+	if v.Field != "denied_repos" || v.Value != "anthropics/experimental-*" {
+		t.Fatalf("unexpected violation: %#v", v)
+	}
+
+	if err := p.Check(Subject{Owner: "Anthropics", Repo: "Anthropics/skills"}); err != nil {
+		t.Fatalf("expected allowed owner to pass, got %v", err)
+	}
+}
+
+func TestCheckOwnerOrRepoAllowlist(t *testing.T) {
+	p := &Policy{Sources: []Source{{
+		Origin: "test",
+		Rules: config.Policy{
+			AllowedOwners: []string{"anthropics"},
+			AllowedRepos:  []string{"obra/superpowers"},
+		},
+	}}}
+
+	if err := p.Check(Subject{Owner: "obra", Repo: "obra/superpowers"}); err != nil {
+		t.Fatalf("expected allowed repo to pass, got %v", err)
+	}
+	err := p.Check(Subject{Owner: "someone", Repo: "someone/skills"})
+	if err == nil || !strings.Contains(err.Error(), "not in the allowlist") {
+		t.Fatalf("expected allowlist violation, got %v", err)
+	}
+}
+
+func TestCheckCategoryRules(t *testing.T) {
+	p := &Policy{Sources: []Source{{
+		Origin: "test",
+		Rules: config.Policy{
+			AllowedCategories: []string{"documents", "testing"},
+		},
+	}}}
+
+	if err := p.Check(Subject{Owner: "o", Repo: "o/r", Category: "testing"}); err != nil {
+		t.Fatalf("expected allowed category to pass, got %v", err)
+	}
+	if err := p.Check(Subject{Owner: "o", Repo: "o/r", Category: "security"}); err == nil {
+		t.Fatal("expected category outside allowlist to be blocked")
+	}
+	err := p.Check(Subject{Owner: "o", Repo: "o/r"})
+	if err == nil || !strings.Contains(err.Error(), "category is unknown") {
+		t.Fatalf("expected direct ref to be blocked by category allowlist, got %v", err)
+	}
+}
+
+func TestLoadMergesConfigAndPolicyFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	policyFile := filepath.Join(home, "org-policy.json")
+	if err := os.WriteFile(policyFile, []byte(`{"denied_owners":["evil"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	skrc := `{"policy":{"allowed_owners":["anthropics","evil"]},"policy_file":"~/org-policy.json"}`
+	if err := os.WriteFile(filepath.Join(home, ".skrc"), []byte(skrc), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Sources) != 2 {
+		t.Fatalf("expected two policy sources, got %d", len(p.Sources))
+	}
+
+	err = p.Check(Subject{Owner: "evil", Repo: "evil/skills"})
+	var v *Violation
+	if !errors.As(err, &v) || v.Origin != policyFile {
+		t.Fatalf("expected org policy violation, got %v", err)
+	}
+}
+
+func TestLoadFailsWhenPolicyFileMissing(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.WriteFile(filepath.Join(home, ".skrc"), []byte(`{"policy_file":"/does/not/exist.json"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(); err == nil {
+		t.Fatal("expected missing policy file to fail closed")
+	}
+}
+
+func TestLoadFailsWhenConfigBroken(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	skrc := `{"policy":{"denied_owners":["evil"]},}`
+	if err := os.WriteFile(filepath.Join(home, ".skrc"), []byte(skrc), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(); err == nil {
+		t.Fatal("expected a config that cannot be parsed to fail closed")
+	}
+}
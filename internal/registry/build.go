@@ -0,0 +1,435 @@
+package registry
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/majiayu000/caude-skill-manager/internal/integrity"
+	"github.com/majiayu000/caude-skill-manager/internal/skill"
+)
+
+// BuildSource is one repository scanned by sk registry build.
+type BuildSource struct {
+	Repo   string // owner/repo used for install refs
+	Branch string // defaults to "main"
+	Dir    string // local checkout or extracted archive to scan
+	Commit string // commit the tree was taken from, if known
+}
+
+// BuildOptions controls registry generation.
+type BuildOptions struct {
+	OutDir     string
+	ShardSize  int                // skills per shard or category part
+	Featured   int                // size of featured.json
+	Gzip       bool               // also write .json.gz shards
+	SigningKey ed25519.PrivateKey // signs manifests when set
+	Now        time.Time
+}
+
+// BuildResult summarises a generated registry.
+type BuildResult struct {
+	Skills     int
+	Categories int
+	Files      []string // written paths relative to OutDir
+}
+
+// defaultCategory is used for skills whose front matter has no category.
+const defaultCategory = "other"
+
+// skippedScanDirs are never descended into when scanning for SKILL.md.
+var skippedScanDirs = map[string]bool{".git": true, "node_modules": true}
+
+// ScanSkills walks src.Dir for SKILL.md files and returns a registry record
+// for each, with a content digest matching what sk install computes.
+func ScanSkills(src BuildSource) ([]Skill, error) {
+	branch := src.Branch
+	if branch == "" {
+		branch = "main"
+	}
+
+	var skills []Skill
+	err := filepath.WalkDir(src.Dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != src.Dir && skippedScanDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() != "SKILL.md" {
+			return nil
+		}
+
+		dir := filepath.Dir(p)
+		rel, err := filepath.Rel(src.Dir, dir)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			rel = ""
+		}
+
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		record, err := skillRecord(src, branch, rel, dir, skill.ParseDocument(content))
+		if err != nil {
+			return fmt.Errorf("%s: %w", p, err)
+		}
+		skills = append(skills, record)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return skills, nil
+}
+
+func skillRecord(src BuildSource, branch, rel, dir string, doc *skill.Document) (Skill, error) {
+	digest, err := integrity.DigestDir(dir, skill.MetaFile, ".git")
+	if err != nil {
+		return Skill{}, err
+	}
+
+	name := doc.Meta.Name
+	if name == "" {
+		name = path.Base(rel)
+		if rel == "" {
+			name = path.Base(src.Repo)
+		}
+	}
+	category := defaultCategory
+	if value, ok := doc.Field("category"); ok && strings.TrimSpace(value) != "" {
+		category = strings.ToLower(strings.TrimSpace(value))
+	}
+	var tags []string
+	if value, ok := doc.Field("tags"); ok {
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+
+	record := Skill{
+		Name:        name,
+		Description: doc.Meta.Description,
+		Repo:        src.Repo,
+		Path:        rel,
+		Branch:      branch,
+		Category:    category,
+		Tags:        tags,
+		Digest:      digest,
+		Commit:      src.Commit,
+	}
+	normalizeRegistrySkill(&record)
+	return record, nil
+}
+
+// Build writes registry.json, the full registry manifest and shards, and the
+// docs/ search index, categories, category index and featured list for
+// skills into opts.OutDir, in the layout the registry client reads.
+func Build(skills []Skill, opts BuildOptions) (*BuildResult, error) {
+	if opts.ShardSize <= 0 {
+		opts.ShardSize = 500
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	generatedAt := opts.Now.UTC().Format(time.RFC3339)
+	version := opts.Now.UTC().Format("2006-01-02")
+
+	skills = append([]Skill(nil), skills...)
+	sort.SliceStable(skills, func(i, j int) bool {
+		if skills[i].Name != skills[j].Name {
+			return skills[i].Name < skills[j].Name
+		}
+		return skills[i].Install < skills[j].Install
+	})
+
+	// Shards carry no timestamp, so unchanged shards keep their hash across
+	// builds and clients only download the ones that changed.
+	w := &artifactWriter{root: opts.OutDir, gzip: opts.Gzip, key: opts.SigningKey}
+
+	// Full registry.
+	var registryShards []artifactPart
+	for i, chunk := range chunkSkills(skills, opts.ShardSize) {
+		shard := fmt.Sprintf("part-%03d", i)
+		part, err := w.writePart("", "registry-shards/"+shard+".json", len(chunk), registryShard{
+			SchemaVersion: "1",
+			Shard:         shard,
+			Count:         len(chunk),
+			Skills:        chunk,
+		})
+		if err != nil {
+			return nil, err
+		}
+		registryShards = append(registryShards, part)
+	}
+	if err := w.writeManifest("registry-manifest.json", registryManifest{
+		SchemaVersion: "1",
+		GeneratedAt:   generatedAt,
+		TotalCount:    len(skills),
+		Shards:        registryShards,
+	}); err != nil {
+		return nil, err
+	}
+	if err := w.writeJSON("registry.json", Registry{
+		Version:               "1",
+		UpdatedAt:             generatedAt,
+		TotalCount:            len(skills),
+		DeprecatedFullPayload: true,
+		Manifest:              "registry-manifest.json",
+	}); err != nil {
+		return nil, err
+	}
+
+	// Compact search index.
+	var searchShards []artifactPart
+	for i, chunk := range chunkSkills(skills, opts.ShardSize) {
+		entries := make([]SearchIndexEntry, len(chunk))
+		for j, s := range chunk {
+			entries[j] = skillToEntry(s)
+		}
+		part, err := w.writePart("docs", fmt.Sprintf("search-shards/part-%03d.json", i), len(entries), searchShard{
+			Count:  len(entries),
+			Skills: entries,
+		})
+		if err != nil {
+			return nil, err
+		}
+		searchShards = append(searchShards, part)
+	}
+	if err := w.writeManifest("docs/search-index-manifest.json", searchManifest{
+		Version:    version,
+		TotalCount: len(skills),
+		Shards:     searchShards,
+	}); err != nil {
+		return nil, err
+	}
+	if err := w.writeJSON("docs/search-index.json", searchIndexPayload{
+		Version:               version,
+		TotalCount:            len(skills),
+		DeprecatedFullPayload: true,
+		Manifest:              "search-index-manifest.json",
+	}); err != nil {
+		return nil, err
+	}
+
+	// Categories.
+	byCategory := make(map[string][]Skill)
+	for _, s := range skills {
+		byCategory[s.Category] = append(byCategory[s.Category], s)
+	}
+	names := make([]string, 0, len(byCategory))
+	for name := range byCategory {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	index := CategoryIndex{UpdatedAt: generatedAt}
+	for _, name := range names {
+		slug := categorySlug(name)
+		members := byCategory[name]
+		var parts []artifactPart
+		for i, chunk := range chunkSkills(members, opts.ShardSize) {
+			part, err := w.writePart("docs", fmt.Sprintf("categories/%s/part-%03d.json", slug, i), len(chunk), Category{
+				Category: name,
+				Code:     categoryCode(name),
+				Count:    len(chunk),
+				Skills:   chunk,
+			})
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, part)
+		}
+		manifestPath := fmt.Sprintf("categories/%s/manifest.json", slug)
+		if err := w.writeManifest("docs/"+manifestPath, categoryManifest{
+			Category:  name,
+			Code:      categoryCode(name),
+			UpdatedAt: generatedAt,
+			Count:     len(members),
+			Parts:     parts,
+		}); err != nil {
+			return nil, err
+		}
+		if err := w.writeJSON(fmt.Sprintf("docs/categories/%s.json", slug), Category{
+			Category:              name,
+			Code:                  categoryCode(name),
+			UpdatedAt:             generatedAt,
+			Count:                 len(members),
+			DeprecatedFullPayload: true,
+			Manifest:              manifestPath,
+		}); err != nil {
+			return nil, err
+		}
+		index.Categories = append(index.Categories, CategoryIndexEntry{Name: slug, Code: categoryCode(name), Count: len(members)})
+	}
+	if err := w.writeJSON("docs/categories/index.json", index); err != nil {
+		return nil, err
+	}
+
+	// Featured: most-starred skills, then by name.
+	featured := append([]Skill(nil), skills...)
+	sort.SliceStable(featured, func(i, j int) bool { return featured[i].Stars > featured[j].Stars })
+	if opts.Featured > 0 && len(featured) > opts.Featured {
+		featured = featured[:opts.Featured]
+	}
+	if err := w.writeJSON("docs/featured.json", Featured{
+		UpdatedAt: generatedAt,
+		Count:     len(featured),
+		Skills:    featured,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &BuildResult{Skills: len(skills), Categories: len(names), Files: w.files}, nil
+}
+
+// skillToEntry is the inverse of entryToSkill.
+func skillToEntry(s Skill) SearchIndexEntry {
+	return SearchIndexEntry{
+		Name:        s.Name,
+		Description: s.Description,
+		Category:    categoryCode(s.Category),
+		Tags:        s.Tags,
+		Stars:       s.Stars,
+		Install:     s.Install,
+		Branch:      s.Branch,
+		Digest:      s.Digest,
+		Commit:      s.Commit,
+	}
+}
+
+// categoryCode returns the short code for a known category, or the name
+// itself, which entryToSkill passes through unchanged.
+func categoryCode(name string) string {
+	for code, full := range categoryCodeToName {
+		if full == name {
+			return code
+		}
+	}
+	return name
+}
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+func categorySlug(name string) string {
+	slug := strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if slug == "" {
+		return defaultCategory
+	}
+	return slug
+}
+
+func chunkSkills(skills []Skill, size int) [][]Skill {
+	var chunks [][]Skill
+	for start := 0; start < len(skills); start += size {
+		end := start + size
+		if end > len(skills) {
+			end = len(skills)
+		}
+		chunks = append(chunks, skills[start:end])
+	}
+	return chunks
+}
+
+// artifactWriter writes registry artifacts under root and records their
+// paths. Parts get sha256 hashes so manifests can be signed over them.
+type artifactWriter struct {
+	root  string
+	gzip  bool
+	key   ed25519.PrivateKey
+	files []string
+}
+
+func (w *artifactWriter) write(rel string, data []byte) error {
+	target := filepath.Join(w.root, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(target, data, 0644); err != nil {
+		return err
+	}
+	w.files = append(w.files, rel)
+	return nil
+}
+
+func (w *artifactWriter) writeJSON(rel string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return w.write(rel, data)
+}
+
+// writePart writes a shard under base and returns its manifest entry, with
+// paths relative to base.
+func (w *artifactWriter) writePart(base, rel string, count int, v any) (artifactPart, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return artifactPart{}, err
+	}
+	if err := w.write(path.Join(base, rel), data); err != nil {
+		return artifactPart{}, err
+	}
+	part := artifactPart{Path: rel, Count: count, SHA256: sha256Hex(data)}
+
+	if w.gzip {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		if _, err := gz.Write(data); err != nil {
+			return artifactPart{}, err
+		}
+		if err := gz.Close(); err != nil {
+			return artifactPart{}, err
+		}
+		if err := w.write(path.Join(base, rel+".gz"), buf.Bytes()); err != nil {
+			return artifactPart{}, err
+		}
+		part.GzipPath = rel + ".gz"
+		part.GzipSHA256 = sha256Hex(buf.Bytes())
+	}
+	return part, nil
+}
+
+// writeManifest writes a manifest and, when a signing key is set, its
+// detached signature.
+func (w *artifactWriter) writeManifest(rel string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := w.write(rel, data); err != nil {
+		return err
+	}
+	if w.key == nil {
+		return nil
+	}
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(w.key, data))
+	return w.write(rel+SignatureSuffix, []byte(sig+"\n"))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,132 @@
+package registry
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/majiayu000/caude-skill-manager/internal/integrity"
+)
+
+func writeSkillTree(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"pdf/SKILL.md":        "---\nname: pdf\ndescription: Work with PDFs\ncategory: documents\ntags: [pdf, forms]\n---\n# PDF\n",
+		"pdf/reference.md":    "reference\n",
+		"tools/lint/SKILL.md": "---\ndescription: Lint things\n---\n# Lint\n",
+		".git/SKILL.md":       "ignored\n",
+	}
+	for rel, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestScanSkills(t *testing.T) {
+	root := writeSkillTree(t)
+
+	skills, err := ScanSkills(BuildSource{Repo: "corp/skills", Dir: root, Commit: "abc123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(skills) != 2 {
+		t.Fatalf("expected 2 skills, got %#v", skills)
+	}
+
+	byName := map[string]Skill{}
+	for _, s := range skills {
+		byName[s.Name] = s
+	}
+	pdf := byName["pdf"]
+	if pdf.Install != "corp/skills/pdf" || pdf.Category != "documents" || len(pdf.Tags) != 2 || pdf.Commit != "abc123" {
+		t.Fatalf("unexpected pdf record: %#v", pdf)
+	}
+	want, err := integrity.DigestDir(filepath.Join(root, "pdf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pdf.Digest != want {
+		t.Fatalf("digest = %s, want %s", pdf.Digest, want)
+	}
+	lint := byName["lint"]
+	if lint.Install != "corp/skills/tools/lint" || lint.Category != defaultCategory {
+		t.Fatalf("unexpected lint record: %#v", lint)
+	}
+}
+
+func TestBuildRoundTripsThroughSignedLocalRegistry(t *testing.T) {
+	skills, err := ScanSkills(BuildSource{Repo: "corp/skills", Dir: writeSkillTree(t)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := t.TempDir()
+	result, err := Build(skills, BuildOptions{
+		OutDir:     out,
+		ShardSize:  1,
+		Gzip:       true,
+		SigningKey: priv,
+		Now:        time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Skills != 2 || result.Categories != 2 {
+		t.Fatalf("unexpected result: %#v", result)
+	}
+
+	writeRegistriesConfig(t, `[{"name":"built","url":"`+out+`","public_key":"`+base64.StdEncoding.EncodeToString(pub)+`"}]`)
+
+	skill, _, err := Lookup("pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if skill.Install != "corp/skills/pdf" || skill.Category != "documents" || skill.Digest != skills[0].Digest {
+		t.Fatalf("unexpected lookup: %#v", skill)
+	}
+
+	registry, _, err := FetchRegistryWithSource()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(registry.Skills) != 2 {
+		t.Fatalf("expected 2 registry skills, got %d", len(registry.Skills))
+	}
+
+	category, err := FetchCategory("documents")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(category.Skills) != 1 || category.Skills[0].Name != "pdf" {
+		t.Fatalf("unexpected category: %#v", category)
+	}
+}
+
+func TestParsePrivateKeyAcceptsSeed(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	key, err := ParsePrivateKey(base64.StdEncoding.EncodeToString(seed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(key) != ed25519.PrivateKeySize {
+		t.Fatalf("unexpected key length %d", len(key))
+	}
+	if _, err := ParsePrivateKey("c2hvcnQ="); err == nil {
+		t.Fatal("expected short key to be rejected")
+	}
+}
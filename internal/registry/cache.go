@@ -0,0 +1,351 @@
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/majiayu000/caude-skill-manager/internal/config"
+)
+
+func registryTTL() time.Duration {
+	return time.Duration(config.GetRegistryTTL()) * time.Hour
+}
+
+// withCachePolicy applies the cache policy shared by remote registry
+// artifacts: a cache within the TTL is used as is; otherwise the artifact is
+// fetched and cached, unless network access is disabled; if that fails, an
+// expired cache is served as RegistrySourceStale rather than failing. A
+// fetch that fails verification is returned as is: the registry served
+// something the pinned key rejects, which a stale cache must not hide.
+func withCachePolicy[T any](loadFresh, loadStale, fetch func() (*T, error), save func(*T) error) (*T, RegistrySource, error) {
+	if cached, err := loadFresh(); err == nil {
+		return cached, RegistrySourceCache, nil
+	}
+
+	fetchErr := config.ErrOffline
+	if !config.IsOffline() {
+		value, err := fetch()
+		if err == nil {
+			_ = save(value)
+			return value, RegistrySourceRemote, nil
+		}
+		fetchErr = err
+		var verifyErr *VerificationError
+		if errors.As(err, &verifyErr) {
+			return nil, "", err
+		}
+	}
+
+	if stale, err := loadStale(); err == nil {
+		return stale, RegistrySourceStale, nil
+	}
+	return nil, "", fetchErr
+}
+
+// cachedForSource reads an artifact of src through the cache file at path
+// under withCachePolicy. Local registries, and artifacts without a cache
+// path, are fetched directly. check rejects unusable cached payloads.
+func cachedForSource[T any](src Source, path string, check func(*T) error, fetch func(*artifactFetcher) (*T, error)) (*T, RegistrySource, error) {
+	load := func() (*T, error) {
+		fetcher, err := src.fetcher()
+		if err != nil {
+			return nil, err
+		}
+		return fetch(fetcher)
+	}
+	if src.Local() || path == "" {
+		value, err := load()
+		if err != nil {
+			return nil, "", err
+		}
+		if src.Local() {
+			return value, RegistrySourceLocal, nil
+		}
+		return value, RegistrySourceRemote, nil
+	}
+
+	stamp := src.cacheStamp()
+	return withCachePolicy(
+		func() (*T, error) { return readCacheFile(path, registryTTL(), stamp, check) },
+		func() (*T, error) { return readCacheFile(path, 0, stamp, check) },
+		load,
+		func(value *T) error { return writeCacheFile(path, stamp, value) },
+	)
+}
+
+// cacheStampKey is the top-level key under which cache files record their
+// cacheStamp, beside the artifact's own fields.
+const cacheStampKey = "sk_cache_source"
+
+// cacheStamp records where a cache file came from; see Source.cacheStamp.
+type cacheStamp struct {
+	URL string `json:"url"`
+	Key string `json:"key,omitempty"` // fingerprint of the pinned key
+}
+
+// checkCacheStamp fails unless the cache file content data records stamp.
+// Files written before stamps were recorded fail too and are refetched.
+func checkCacheStamp(path string, data []byte, stamp cacheStamp) error {
+	var stamped struct {
+		Stamp *cacheStamp `json:"sk_cache_source"`
+	}
+	if err := json.Unmarshal(data, &stamped); err != nil {
+		return err
+	}
+	if stamped.Stamp == nil || *stamped.Stamp != stamp {
+		return fmt.Errorf("%s cache was written for another registry URL or key", filepath.Base(path))
+	}
+	return nil
+}
+
+// marshalStamped encodes value, a JSON object, with stamp added under
+// cacheStampKey.
+func marshalStamped(value any, stamp cacheStamp) ([]byte, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < 2 || data[0] != '{' {
+		return nil, fmt.Errorf("cannot cache non-object %T", value)
+	}
+	stampData, err := json.Marshal(stamp)
+	if err != nil {
+		return nil, err
+	}
+	stamped := append([]byte(`{"`+cacheStampKey+`":`), stampData...)
+	if len(data) > 2 {
+		stamped = append(stamped, ',')
+	}
+	return append(stamped, data[1:]...), nil
+}
+
+// readCacheFile decodes the cache file at path if it is younger than maxAge
+// and records stamp; a zero maxAge accepts any age.
+func readCacheFile[T any](path string, maxAge time.Duration, stamp cacheStamp, check func(*T) error) (*T, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if maxAge > 0 && time.Since(info.ModTime()) > maxAge {
+		return nil, fmt.Errorf("%s cache expired", filepath.Base(path))
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCacheStamp(path, data, stamp); err != nil {
+		return nil, err
+	}
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	if check != nil {
+		if err := check(&value); err != nil {
+			return nil, err
+		}
+	}
+	return &value, nil
+}
+
+func writeCacheFile(path string, stamp cacheStamp, value any) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := marshalStamped(value, stamp)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// CacheEntry is one cached artifact of a remote registry, as shown by
+// sk cache list.
+type CacheEntry struct {
+	Label   string // "Full registry", "Search index", ...
+	Path    string
+	URL     string // artifact the entry caches; empty for stores
+	Store   bool   // a directory of entries rather than one file
+	Exists  bool
+	Size    int64
+	Files   int
+	ModTime time.Time
+}
+
+// Expired reports whether a TTL-bound cache file is older than the registry
+// TTL. Stores are pruned by age instead and never expire as a whole.
+func (e CacheEntry) Expired() bool {
+	return !e.Store && e.Exists && time.Since(e.ModTime) > registryTTL()
+}
+
+// CacheEntries lists the cached artifacts of src. Local registries are never
+// cached and have none.
+func CacheEntries(src Source) []CacheEntry {
+	if src.Local() {
+		return nil
+	}
+	dir := config.RegistryCacheDir(src.Name)
+	entries := []CacheEntry{
+		{Label: "Full registry", Path: config.RegistryCachePathFor(src.Name), URL: artifactURL(src.BaseURL, "registry.json")},
+		{Label: "Search index", Path: config.SearchIndexCachePathFor(src.Name), URL: artifactURL(src.docsBaseURL(), "search-index.json")},
+		{Label: "Search lookup", Path: searchIndexBinaryPath(config.SearchIndexCachePathFor(src.Name))},
+		{Label: "Featured", Path: config.FeaturedCachePathFor(src.Name), URL: artifactURL(src.docsBaseURL(), "featured.json")},
+		{Label: "Category index", Path: config.CategoryIndexCachePathFor(src.Name), URL: artifactURL(src.docsBaseURL(), "categories/index.json")},
+		{Label: "Categories", Path: config.CategoryCacheDirFor(src.Name), Store: true},
+		{Label: "Shards", Path: filepath.Join(dir, "shards"), Store: true},
+		{Label: "HTTP validators", Path: filepath.Join(dir, "http"), Store: true},
+	}
+	for i := range entries {
+		entries[i].stat()
+	}
+	return entries
+}
+
+func (e *CacheEntry) stat() {
+	info, err := os.Stat(e.Path)
+	if err != nil {
+		return
+	}
+	e.Exists = true
+	e.ModTime = info.ModTime()
+	if !e.Store {
+		e.Size = info.Size()
+		e.Files = 1
+		return
+	}
+	_ = filepath.WalkDir(e.Path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			e.Size += info.Size()
+			e.Files++
+			if info.ModTime().After(e.ModTime) {
+				e.ModTime = info.ModTime()
+			}
+		}
+		return nil
+	})
+}
+
+// Refresh fetches the full registry and search index of src, and the
+// featured list, category index and categories already cached, and replaces
+// their caches regardless of age. Unchanged shards and artifacts are still
+// reused through the shard store and conditional requests.
+func Refresh(src Source) error {
+	if src.Local() {
+		return nil
+	}
+	if config.IsOffline() {
+		return config.ErrOffline
+	}
+	fetcher, err := src.fetcher()
+	if err != nil {
+		return err
+	}
+	idx, err := fetchSearchIndexFromBaseURL(src.docsBaseURL(), fetcher)
+	if err != nil {
+		return err
+	}
+	if err := saveSearchIndexCache(src, idx); err != nil {
+		return err
+	}
+	registry, err := fetchRegistryFromBaseURL(src.BaseURL, fetcher)
+	if err != nil {
+		return fmt.Errorf("failed to fetch registry: %w", err)
+	}
+	if err := saveRegistryCache(src, registry); err != nil {
+		return err
+	}
+
+	stamp := src.cacheStamp()
+	if err := refreshCacheFile(config.FeaturedCachePathFor(src.Name), stamp, fetcher, fetchFeaturedFor(src)); err != nil {
+		return err
+	}
+	if err := refreshCacheFile(config.CategoryIndexCachePathFor(src.Name), stamp, fetcher, fetchCategoryIndexFor(src)); err != nil {
+		return err
+	}
+	cached, _ := filepath.Glob(filepath.Join(config.CategoryCacheDirFor(src.Name), "*.json"))
+	for _, path := range cached {
+		category := strings.TrimSuffix(filepath.Base(path), ".json")
+		if err := refreshCacheFile(path, stamp, fetcher, fetchCategoryFor(src, category)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// refreshCacheFile re-fetches the artifact cached at path, if it is cached.
+func refreshCacheFile[T any](path string, stamp cacheStamp, fetcher *artifactFetcher, fetch func(*artifactFetcher) (*T, error)) error {
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	value, err := fetch(fetcher)
+	if err != nil {
+		return err
+	}
+	return writeCacheFile(path, stamp, value)
+}
+
+// ClearCache removes every cached artifact of src and returns the bytes
+// freed.
+func ClearCache(src Source) (int64, error) {
+	var freed int64
+	for _, entry := range CacheEntries(src) {
+		if !entry.Exists {
+			continue
+		}
+		if err := os.RemoveAll(entry.Path); err != nil {
+			return freed, err
+		}
+		freed += entry.Size
+	}
+	if src.Name != config.DefaultRegistryName {
+		// Only succeeds once the registry's directory is empty.
+		_ = os.Remove(config.RegistryCacheDir(src.Name))
+	}
+	return freed, nil
+}
+
+// PruneCaches removes cache data no configured registry needs: shards and
+// HTTP validators unused for 30 days, and the cache directories of registries
+// no longer in the config. Expired registry and search index caches are kept
+// as the offline fallback. It returns the bytes freed.
+func PruneCaches(sources []Source) (int64, error) {
+	var freed int64
+	configured := make(map[string]bool, len(sources))
+	for _, src := range sources {
+		configured[src.Name] = true
+		if src.Local() {
+			continue
+		}
+		dir := config.RegistryCacheDir(src.Name)
+		freed += (&shardStore{dir: filepath.Join(dir, "shards")}).prune(shardStoreMaxAge)
+		freed += (&shardStore{dir: filepath.Join(dir, "shards", "manifests")}).prune(shardStoreMaxAge)
+		freed += (&shardStore{dir: filepath.Join(dir, "http")}).prune(shardStoreMaxAge)
+	}
+
+	registriesDir := filepath.Join(config.CacheDir(), "registries")
+	dirs, err := os.ReadDir(registriesDir)
+	if err != nil && !os.IsNotExist(err) {
+		return freed, err
+	}
+	for _, d := range dirs {
+		if !d.IsDir() || configured[d.Name()] {
+			continue
+		}
+		orphan := CacheEntry{Path: filepath.Join(registriesDir, d.Name()), Store: true}
+		orphan.stat()
+		if err := os.RemoveAll(orphan.Path); err != nil {
+			return freed, err
+		}
+		freed += orphan.Size
+	}
+	return freed, nil
+}
@@ -0,0 +1,296 @@
+package registry
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/majiayu000/caude-skill-manager/internal/config"
+)
+
+func expireFile(t *testing.T, path string) {
+	t.Helper()
+	old := time.Now().Add(-72 * time.Hour)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func setOffline(t *testing.T) {
+	t.Helper()
+	config.SetOffline(true)
+	t.Cleanup(func() { config.SetOffline(false) })
+}
+
+func TestExpiredSearchIndexServedStaleWhenRegistryUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusBadGateway)
+	}))
+	t.Cleanup(server.Close)
+	writeConfigForRegistryTest(t, server.URL)
+
+	if err := saveSearchIndexCache(defaultSource(t), &SearchIndex{TotalCount: 1, Skills: []SearchIndexEntry{{Name: "pdf", Install: "owner/repo/pdf"}}}); err != nil {
+		t.Fatal(err)
+	}
+	expireFile(t, config.SearchIndexCachePath())
+
+	skill, source, err := Lookup("pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source != RegistrySourceStale || skill.Install != "owner/repo/pdf" {
+		t.Fatalf("expected stale cached lookup, got %s %#v", source, skill)
+	}
+}
+
+func TestOfflineNeverTouchesTheNetwork(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request for %s", r.URL.Path)
+	}))
+	t.Cleanup(server.Close)
+	writeConfigForRegistryTest(t, server.URL)
+	setOffline(t)
+
+	if _, _, err := FetchRegistryWithSource(); !errors.Is(err, config.ErrOffline) {
+		t.Fatalf("expected ErrOffline without a cache, got %v", err)
+	}
+
+	if err := saveRegistryCache(defaultSource(t), &Registry{TotalCount: 1, Skills: []Skill{{Name: "pdf", Install: "owner/repo/pdf", Category: "documents"}}}); err != nil {
+		t.Fatal(err)
+	}
+	expireFile(t, config.RegistryCachePath())
+
+	registry, source, err := FetchRegistryWithSource()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source != RegistrySourceStale || len(registry.Skills) != 1 {
+		t.Fatalf("expected stale cached registry, got %s %#v", source, registry)
+	}
+
+	// Categories fall back to the cached full registry.
+	skills, _, err := GetByCategoryWithSource("documents")
+	if err != nil || len(skills) != 1 {
+		t.Fatalf("unexpected category result %#v, %v", skills, err)
+	}
+}
+
+func TestCacheForAnotherURLOrKeyIsNotServed(t *testing.T) {
+	setOffline(t)
+	writeRegistriesConfig(t, `[{"name":"default","url":"https://old.example.com"}]`)
+	if err := saveSearchIndexCache(defaultSource(t), &SearchIndex{TotalCount: 1, Skills: []SearchIndexEntry{{Name: "pdf", Install: "old/repo/pdf"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, source, err := Lookup("pdf"); err != nil || source != RegistrySourceCache {
+		t.Fatalf("expected the cache for the configured URL to be served, got %s, %v", source, err)
+	}
+
+	home := os.Getenv("HOME")
+	for _, registries := range []string{
+		`[{"name":"default","url":"https://new.example.com"}]`,
+		`[{"name":"default","url":"https://old.example.com","public_key":"` + base64.StdEncoding.EncodeToString(make([]byte, ed25519.PublicKeySize)) + `"}]`,
+	} {
+		body := []byte(`{"registry_ttl_hours":24,"registries":` + registries + `}`)
+		if err := os.WriteFile(filepath.Join(home, ".skrc"), body, 0644); err != nil {
+			t.Fatal(err)
+		}
+		if skill, source, err := Lookup("pdf"); err == nil {
+			t.Fatalf("%s: expected the cache to be rejected, served %s %#v", registries, source, skill)
+		}
+	}
+}
+
+func TestVerificationFailureIsNotServedStale(t *testing.T) {
+	verifier, priv := pinnedVerifier(t)
+	tampered := signedSearchServer(t, priv, `{"v":"1","count":1,"s":[{"n":"pdf","i":"owner/repo/pdf"}]}`, func(manifest string) string {
+		return strings.Replace(manifest, `"total_count":1`, `"total_count":2`, 1)
+	})
+	server := httptest.NewServer(http.StripPrefix("/docs", tampered.Config.Handler))
+	t.Cleanup(server.Close)
+	key := base64.StdEncoding.EncodeToString(verifier.key)
+	writeRegistriesConfig(t, `[{"name":"default","url":"`+server.URL+`","public_key":"`+key+`"}]`)
+
+	if err := saveSearchIndexCache(defaultSource(t), &SearchIndex{TotalCount: 1, Skills: []SearchIndexEntry{{Name: "pdf", Install: "owner/repo/pdf"}}}); err != nil {
+		t.Fatal(err)
+	}
+	expireFile(t, config.SearchIndexCachePath())
+
+	_, _, err := Lookup("pdf")
+	var verr *VerificationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected the verification failure, got %v", err)
+	}
+}
+
+func cacheEntry(t *testing.T, entries []CacheEntry, label string) CacheEntry {
+	t.Helper()
+	for _, entry := range entries {
+		if entry.Label == label {
+			return entry
+		}
+	}
+	t.Fatalf("no %s cache entry in %#v", label, entries)
+	return CacheEntry{}
+}
+
+func TestClearCacheAndPruneCaches(t *testing.T) {
+	writeConfigForRegistryTest(t, "https://registry.example.com")
+	src := Source{Name: config.DefaultRegistryName, BaseURL: "https://registry.example.com"}
+
+	if err := saveRegistryCache(src, &Registry{TotalCount: 1, Skills: []Skill{{Name: "pdf", Install: "owner/repo/pdf"}}}); err != nil {
+		t.Fatal(err)
+	}
+	store := &shardStore{dir: filepath.Join(config.RegistryCacheDir(src.Name), "shards")}
+	store.put("old", []byte("unused shard"))
+	store.put("recent", []byte("used shard"))
+	old := time.Now().Add(-2 * shardStoreMaxAge)
+	if err := os.Chtimes(store.path("old"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	orphan := filepath.Join(config.CacheDir(), "registries", "removed")
+	if err := os.MkdirAll(orphan, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(orphan, "registry.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := CacheEntries(src)
+	if full := cacheEntry(t, entries, "Full registry"); !full.Exists || full.Expired() {
+		t.Fatalf("expected a fresh registry cache, got %#v", full)
+	}
+	if search := cacheEntry(t, entries, "Search index"); search.Exists {
+		t.Fatalf("expected no search index cache, got %#v", search)
+	}
+	if shards := cacheEntry(t, entries, "Shards"); !shards.Store || shards.Files != 2 {
+		t.Fatalf("expected two stored shards, got %#v", shards)
+	}
+
+	freed, err := PruneCaches([]Source{src})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := int64(len("unused shard") + len("{}")); freed != want {
+		t.Fatalf("expected %d bytes pruned, got %d", want, freed)
+	}
+	if _, err := os.Stat(orphan); !os.IsNotExist(err) {
+		t.Fatalf("expected unconfigured registry cache removed, got %v", err)
+	}
+	if _, ok := store.get("recent", ""); !ok {
+		t.Fatal("expected recently used shard kept")
+	}
+	if _, err := loadStaleRegistryCache(src); err != nil {
+		t.Fatalf("prune must keep the registry cache: %v", err)
+	}
+
+	if _, err := ClearCache(src); err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range CacheEntries(src) {
+		if entry.Exists {
+			t.Fatalf("expected %s cleared", entry.Path)
+		}
+	}
+}
+
+func TestRefreshReplacesFreshCache(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/registry.json":
+			_, _ = w.Write([]byte(`{"total_count":1,"skills":[{"name":"docx","install":"owner/repo/docx"}]}`))
+		case "/docs/search-index.json":
+			_, _ = w.Write([]byte(`{"total_count":1,"skills":[{"name":"docx","install":"owner/repo/docx"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	writeConfigForRegistryTest(t, server.URL)
+
+	if err := saveRegistryCache(defaultSource(t), &Registry{TotalCount: 1, Skills: []Skill{{Name: "pdf", Install: "owner/repo/pdf"}}}); err != nil {
+		t.Fatal(err)
+	}
+	src, err := SourceByName(config.DefaultRegistryName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Refresh(src); err != nil {
+		t.Fatal(err)
+	}
+
+	registry, err := loadRegistryCache(src)
+	if err != nil || len(registry.Skills) != 1 || registry.Skills[0].Name != "docx" {
+		t.Fatalf("expected refreshed registry cache, got %#v, %v", registry, err)
+	}
+	if _, err := loadSearchIndexCache(src); err != nil {
+		t.Fatalf("expected search index cached: %v", err)
+	}
+}
+
+func TestCategoryAndFeaturedAreCached(t *testing.T) {
+	down := false
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		if down {
+			http.Error(w, "down", http.StatusBadGateway)
+			return
+		}
+		switch r.URL.Path {
+		case "/docs/categories/documents.json":
+			_, _ = w.Write([]byte(`{"category":"documents","count":1,"skills":[{"name":"pdf","install":"owner/repo/pdf"}]}`))
+		case "/docs/featured.json":
+			_, _ = w.Write([]byte(`{"count":1,"skills":[{"name":"pdf","install":"owner/repo/pdf"}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	writeConfigForRegistryTest(t, server.URL)
+
+	for i, want := range []RegistrySource{RegistrySourceRemote, RegistrySourceCache} {
+		skills, source, err := GetByCategoryWithSource("documents")
+		if err != nil || len(skills) != 1 || source != want {
+			t.Fatalf("call %d: got %#v from %s, %v; want %s", i, skills, source, err, want)
+		}
+		if _, source, err := FetchFeaturedWithSource(); err != nil || source != want {
+			t.Fatalf("call %d: featured from %s, %v; want %s", i, source, err, want)
+		}
+	}
+	if requests["/docs/categories/documents.json"] != 1 || requests["/docs/featured.json"] != 1 {
+		t.Fatalf("expected one fetch of each artifact, got %v", requests)
+	}
+
+	down = true
+	expireFile(t, categoryCachePath(config.DefaultRegistryName, "documents"))
+	skills, source, err := GetByCategoryWithSource("documents")
+	if err != nil || len(skills) != 1 || source != RegistrySourceStale {
+		t.Fatalf("expected stale category, got %#v from %s, %v", skills, source, err)
+	}
+	if skills[0].Registry != config.DefaultRegistryName {
+		t.Fatalf("expected cached skills tagged with their registry, got %q", skills[0].Registry)
+	}
+}
+
+func TestCategoryCachePathRejectsUnsafeNames(t *testing.T) {
+	if path := categoryCachePath("default", "../escape"); path != "" {
+		t.Fatalf("expected no cache path, got %s", path)
+	}
+}
+
+// defaultSource returns the default registry as configured for the test.
+func defaultSource(t testing.TB) Source {
+	t.Helper()
+	src, err := SourceByName(config.DefaultRegistryName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return src
+}
@@ -0,0 +1,108 @@
+package registry
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/majiayu000/caude-skill-manager/internal/httpclient"
+)
+
+// validatorCache persists the ETag and Last-Modified of registry artifacts,
+// with their bodies, so later fetches can be conditional requests.
+type validatorCache struct {
+	dir string
+}
+
+type artifactValidators struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+func (c *validatorCache) paths(url string) (meta, body string) {
+	key := sha256Hex([]byte(url))
+	return filepath.Join(c.dir, key+".json"), filepath.Join(c.dir, key+".body")
+}
+
+// load returns the stored validators for url, if its body is stored too.
+func (c *validatorCache) load(url string) (*artifactValidators, []byte) {
+	metaPath, bodyPath := c.paths(url)
+	data, err := os.ReadFile(metaPath)
+	if err != nil {
+		return nil, nil
+	}
+	var v artifactValidators
+	if err := json.Unmarshal(data, &v); err != nil || v.URL != url {
+		return nil, nil
+	}
+	body, err := os.ReadFile(bodyPath)
+	if err != nil {
+		return nil, nil
+	}
+	return &v, body
+}
+
+// save records the response validators and body; responses without
+// validators clear any stored entry.
+func (c *validatorCache) save(url string, header http.Header, body []byte) {
+	metaPath, bodyPath := c.paths(url)
+	v := artifactValidators{URL: url, ETag: header.Get("ETag"), LastModified: header.Get("Last-Modified")}
+	if v.ETag == "" && v.LastModified == "" {
+		_ = os.Remove(metaPath)
+		_ = os.Remove(bodyPath)
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil || os.MkdirAll(c.dir, 0755) != nil {
+		return
+	}
+	if os.WriteFile(bodyPath, body, 0644) == nil {
+		_ = os.WriteFile(metaPath, data, 0644)
+	}
+}
+
+// touch marks the stored entry for url as used, so sk cache prune keeps it.
+func (c *validatorCache) touch(url string) {
+	now := time.Now()
+	metaPath, bodyPath := c.paths(url)
+	_ = os.Chtimes(metaPath, now, now)
+	_ = os.Chtimes(bodyPath, now, now)
+}
+
+// get fetches url, sending If-None-Match and If-Modified-Since when
+// validators are stored. A 304 returns the stored body; notModified reports
+// whether that happened.
+func (c *validatorCache) get(url string) (data []byte, notModified bool, err error) {
+	stored, body := c.load(url)
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	if stored != nil {
+		if stored.ETag != "" {
+			req.Header.Set("If-None-Match", stored.ETag)
+		}
+		if stored.LastModified != "" {
+			req.Header.Set("If-Modified-Since", stored.LastModified)
+		}
+	}
+
+	resp, data, err := httpclient.Fetch(req)
+	if err != nil {
+		return nil, false, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && stored != nil:
+		c.touch(url)
+		return body, true, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, false, &statusError{Code: resp.StatusCode}
+	}
+	c.save(url, resp.Header, data)
+	return data, false, nil
+}
@@ -0,0 +1,92 @@
+package registry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/majiayu000/caude-skill-manager/internal/config"
+)
+
+func TestExpiredSearchIndexRevalidatesWithConditionalRequests(t *testing.T) {
+	const lastModified = "Mon, 05 Jan 2026 10:00:00 GMT"
+	var mu sync.Mutex
+	notModified := map[string]int{}
+	full := map[string]int{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/docs/search-index.json", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified[r.URL.Path]++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full[r.URL.Path]++
+		w.Header().Set("ETag", `"v1"`)
+		writeJSON(t, w, `{"deprecated_full_payload":true,"manifest":"search-index-manifest.json"}`)
+	})
+	mux.HandleFunc("/docs/search-index-manifest.json", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Header.Get("If-Modified-Since") == lastModified {
+			notModified[r.URL.Path]++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full[r.URL.Path]++
+		w.Header().Set("Last-Modified", lastModified)
+		writeJSON(t, w, `{"v":"1","total_count":1,"shards":[{"path":"search-shards/00.json"}]}`)
+	})
+	mux.HandleFunc("/docs/search-shards/00.json", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		full[r.URL.Path]++
+		mu.Unlock()
+		writeJSON(t, w, `{"v":"1","count":1,"s":[{"n":"pdf","i":"owner/repo/pdf"}]}`)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	writeConfigForRegistryTest(t, server.URL)
+
+	if _, source, err := FetchSearchIndex(); err != nil || source != RegistrySourceRemote {
+		t.Fatalf("first fetch: source %s, err %v", source, err)
+	}
+
+	// Expire the cache; the refresh must revalidate instead of re-downloading.
+	cachePath := config.SearchIndexCachePath()
+	expired := time.Now().Add(-48 * time.Hour)
+	if err := os.Chtimes(cachePath, expired, expired); err != nil {
+		t.Fatal(err)
+	}
+
+	idx, source, err := FetchSearchIndex()
+	if err != nil || source != RegistrySourceRemote {
+		t.Fatalf("refresh: source %s, err %v", source, err)
+	}
+	if len(idx.Skills) != 1 || idx.Skills[0].Name != "pdf" {
+		t.Fatalf("unexpected index: %#v", idx)
+	}
+	if notModified["/docs/search-index.json"] != 1 || notModified["/docs/search-index-manifest.json"] != 1 {
+		t.Fatalf("expected conditional requests, got 304s %v", notModified)
+	}
+	for path, n := range full {
+		if n != 1 {
+			t.Fatalf("%s downloaded %d times", path, n)
+		}
+	}
+
+	info, err := os.Stat(cachePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(info.ModTime()) > time.Hour {
+		t.Fatalf("expected the refresh to bump the cache TTL, mtime %s", info.ModTime())
+	}
+	if _, source, _ := FetchSearchIndex(); source != RegistrySourceCache {
+		t.Fatalf("expected the bumped cache to be used, got %s", source)
+	}
+}
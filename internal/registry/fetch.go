@@ -0,0 +1,422 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/majiayu000/caude-skill-manager/internal/config"
+)
+
+// Shard downloads run shardConcurrency at a time.
+var shardConcurrency = 4
+
+// shardStoreMaxAge is how long an unused stored shard is kept.
+const shardStoreMaxAge = 30 * 24 * time.Hour
+
+// statusError reports a non-200 response for a registry artifact.
+type statusError struct {
+	Code int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("returned status %d", e.Code)
+}
+
+// ShardProgress describes shard downloads for one manifest.
+type ShardProgress struct {
+	Manifest string
+	Done     int
+	Total    int
+	Reused   int   // shards taken from disk instead of downloaded
+	Bytes    int64 // downloaded so far; reused shards add nothing
+}
+
+var (
+	progressMu      sync.Mutex
+	progressHandler func(ShardProgress)
+)
+
+// SetProgressHandler installs fn to be called as manifest shards complete,
+// ending with a call where Done equals Total. Calls are serialised. A nil fn
+// stops reporting.
+func SetProgressHandler(fn func(ShardProgress)) {
+	progressMu.Lock()
+	defer progressMu.Unlock()
+	progressHandler = fn
+}
+
+func reportProgress(p ShardProgress) {
+	progressMu.Lock()
+	defer progressMu.Unlock()
+	if progressHandler != nil {
+		progressHandler(p)
+	}
+}
+
+// artifactFetcher fetches the artifacts of one registry. Pointers and
+// manifests are conditional requests against validators; the parts a
+// manifest lists are fetched concurrently, with retries, checked by verifier,
+// and resumable through store. A nil fetcher fetches without verification or
+// caching.
+type artifactFetcher struct {
+	verifier   *artifactVerifier
+	store      *shardStore     // nil for local registries
+	validators *validatorCache // nil for local registries
+}
+
+// get fetches a pointer, manifest or signature, conditionally when the
+// registry keeps validators.
+func (f *artifactFetcher) get(url string) ([]byte, error) {
+	if f == nil || f.validators == nil {
+		return fetchBytes(url)
+	}
+	data, _, err := f.validators.get(url)
+	return data, err
+}
+
+func (f *artifactFetcher) getJSON(url string, target any) error {
+	data, err := f.get(url)
+	if err != nil {
+		return err
+	}
+	return decodeJSON(url, data, target)
+}
+
+// fetchManifest fetches a manifest and, when a key is pinned, its detached
+// signature, and decodes the manifest only after the signature verifies. It
+// returns the manifest bytes as served.
+func (f *artifactFetcher) fetchManifest(baseURL, manifestPath string, target any) ([]byte, error) {
+	data, err := f.get(artifactURL(baseURL, manifestPath))
+	if err != nil {
+		return nil, err
+	}
+	if v := f.artifactVerifier(); v != nil {
+		sigData, err := f.get(artifactURL(baseURL, manifestPath+SignatureSuffix))
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch manifest signature %s%s: %w", manifestPath, SignatureSuffix, err)
+		}
+		if err := v.checkSignature(manifestPath, data, sigData); err != nil {
+			return nil, err
+		}
+	}
+	if err := decodeJSON(manifestPath, data, target); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (f *artifactFetcher) artifactVerifier() *artifactVerifier {
+	if f == nil {
+		return nil
+	}
+	return f.verifier
+}
+
+func (f *artifactFetcher) shardStore() *shardStore {
+	if f == nil {
+		return nil
+	}
+	return f.store
+}
+
+// fetcher returns the artifact fetcher for the registry. Remote registries
+// keep downloaded shards under their cache directory so an interrupted fetch
+// resumes where it stopped, and artifact validators for conditional requests.
+func (s Source) fetcher() (*artifactFetcher, error) {
+	verifier, err := s.verifier()
+	if err != nil {
+		return nil, err
+	}
+	f := &artifactFetcher{verifier: verifier}
+	if !s.Local() {
+		f.store = &shardStore{dir: filepath.Join(config.RegistryCacheDir(s.Name), "shards")}
+		f.validators = &validatorCache{dir: filepath.Join(config.RegistryCacheDir(s.Name), "http")}
+	}
+	return f, nil
+}
+
+// fetchParts fetches every part a manifest lists and calls decode with each
+// part's index and the bytes of the first variant that decodes. Parts that
+// are unchanged since the last manifest fetched from the same URL are read
+// from the store, so a refresh downloads only changed shards. kind names a
+// part in errors, such as "registry shard".
+func (f *artifactFetcher) fetchParts(baseURL, kind, manifestPath string, manifestData []byte, parts []artifactPart, variants func(artifactPart) []string, decode func(i int, path string, data []byte) error) error {
+	owner, noun, _ := strings.Cut(kind, " ")
+	for _, part := range parts {
+		if len(variants(part)) == 0 {
+			return fmt.Errorf("%s manifest contains empty %s path", owner, noun)
+		}
+	}
+
+	manifestURL := artifactURL(baseURL, manifestPath)
+	current := manifestSnapshot{Digest: sha256Hex(manifestData)}
+	_ = decodeJSON(manifestPath, manifestData, &current.Manifest)
+	previous := f.shardStore().manifest(manifestURL)
+
+	progress := ShardProgress{Manifest: manifestPath, Total: len(parts)}
+	var progressLock sync.Mutex
+	reportProgress(progress)
+
+	errs := make([]error, len(parts))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < shardConcurrency && w < len(parts); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fetched := f.fetchPart(baseURL, kind, current, previous, parts[i], variants(parts[i]), func(path string, data []byte) error {
+					return decode(i, path, data)
+				})
+				errs[i] = fetched.err
+
+				progressLock.Lock()
+				progress.Done++
+				progress.Bytes += fetched.downloaded
+				if fetched.reused {
+					progress.Reused++
+				}
+				reportProgress(progress)
+				progressLock.Unlock()
+			}
+		}()
+	}
+	for i := range parts {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	f.shardStore().putManifest(manifestURL, manifestData)
+	f.shardStore().prune(shardStoreMaxAge)
+	return nil
+}
+
+type partResult struct {
+	downloaded int64
+	reused     bool
+	err        error
+}
+
+// fetchPart tries each variant of a part in order, from the store first and
+// then over the network.
+func (f *artifactFetcher) fetchPart(baseURL, kind string, current manifestSnapshot, previous *manifestSnapshot, part artifactPart, variants []string, decode func(path string, data []byte) error) partResult {
+	var result partResult
+	for _, path := range variants {
+		url := artifactURL(baseURL, path)
+		want := part.hashFor(path)
+		key := shardKey(want, url, current.Digest)
+
+		keys := []string{key}
+		if previous.unchanged(current, part, path) {
+			keys = append(keys, shardKey("", url, previous.Digest))
+		}
+		for _, storedKey := range keys {
+			data, ok := f.shardStore().get(storedKey, want)
+			if !ok {
+				continue
+			}
+			// A shard stored before a key was pinned has no listed hash to
+			// match; it gets the same check as a fresh download.
+			if f.artifactVerifier().checkShard(part, path, data) != nil || decode(path, data) != nil {
+				f.shardStore().remove(storedKey)
+				continue
+			}
+			if storedKey != key {
+				f.shardStore().put(key, data)
+			}
+			result.reused = true
+			return result
+		}
+
+		data, err := fetchBytes(url)
+		if err == nil {
+			result.downloaded += int64(len(data))
+			err = f.artifactVerifier().checkShard(part, path, data)
+		}
+		if err == nil {
+			err = decode(path, data)
+		}
+		if err != nil {
+			result.err = fmt.Errorf("failed to fetch %s %s: %w", kind, path, err)
+			continue
+		}
+		result.err = nil
+		if want == "" || hashMatches(want, data) {
+			f.shardStore().put(key, data)
+		}
+		return result
+	}
+	return result
+}
+
+// manifestSnapshot is a fetched manifest as far as incremental updates need
+// it: its digest, timestamp and parts. Registry and search manifests list
+// "shards", category manifests "parts".
+type manifestSnapshot struct {
+	Digest   string
+	Manifest struct {
+		GeneratedAt string         `json:"generated_at"`
+		UpdatedAt   string         `json:"updated_at"`
+		Version     string         `json:"v"`
+		Shards      []artifactPart `json:"shards"`
+		Parts       []artifactPart `json:"parts"`
+	}
+}
+
+func (m *manifestSnapshot) stamp() string {
+	switch {
+	case m.Manifest.GeneratedAt != "":
+		return m.Manifest.GeneratedAt
+	case m.Manifest.UpdatedAt != "":
+		return m.Manifest.UpdatedAt
+	}
+	return m.Manifest.Version
+}
+
+// unchanged reports whether a part without a hash can be taken from the
+// previous manifest's download: the manifest timestamp is the same and the
+// previous manifest listed the same path with the same count. Parts with
+// hashes need no such guess; the store is keyed by hash.
+func (m *manifestSnapshot) unchanged(current manifestSnapshot, part artifactPart, path string) bool {
+	if m == nil || part.hashFor(path) != "" || m.Digest == current.Digest {
+		return false
+	}
+	if stamp := current.stamp(); stamp == "" || stamp != m.stamp() {
+		return false
+	}
+	for _, list := range [][]artifactPart{m.Manifest.Shards, m.Manifest.Parts} {
+		for _, prev := range list {
+			if (prev.Path == path || prev.GzipPath == path) && prev.hashFor(path) == "" {
+				return prev.Count == part.Count && part.Count > 0
+			}
+		}
+	}
+	return false
+}
+
+// shardStore keeps downloaded shards on disk. Shards with a listed sha256 are
+// stored under that hash, so unchanged shards survive manifest updates;
+// others are keyed by URL and manifest bytes and change with the manifest.
+type shardStore struct {
+	dir string
+}
+
+func shardKey(hash, url, manifestDigest string) string {
+	if hash != "" {
+		return strings.ToLower(strings.TrimPrefix(hash, "sha256:"))
+	}
+	return sha256Hex([]byte(url + "\n" + manifestDigest))
+}
+
+func (s *shardStore) path(key string) string {
+	return filepath.Join(s.dir, key)
+}
+
+// get returns a stored shard, checking it against hash when one is listed.
+func (s *shardStore) get(key, hash string) ([]byte, bool) {
+	if s == nil {
+		return nil, false
+	}
+	data, err := os.ReadFile(s.path(key))
+	if err != nil {
+		return nil, false
+	}
+	if hash != "" && !hashMatches(hash, data) {
+		s.remove(key)
+		return nil, false
+	}
+	now := time.Now()
+	_ = os.Chtimes(s.path(key), now, now)
+	return data, true
+}
+
+// put stores a shard atomically; failures only cost a later re-download.
+func (s *shardStore) put(key string, data []byte) {
+	if s == nil {
+		return
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return
+	}
+	_, writeErr := tmp.Write(data)
+	closeErr := tmp.Close()
+	if writeErr != nil || closeErr != nil || os.Rename(tmp.Name(), s.path(key)) != nil {
+		_ = os.Remove(tmp.Name())
+	}
+}
+
+// manifest returns the last manifest stored for url, if any.
+func (s *shardStore) manifest(url string) *manifestSnapshot {
+	if s == nil {
+		return nil
+	}
+	data, err := os.ReadFile(s.manifestPath(url))
+	if err != nil {
+		return nil
+	}
+	snapshot := &manifestSnapshot{Digest: sha256Hex(data)}
+	if err := json.Unmarshal(data, &snapshot.Manifest); err != nil {
+		return nil
+	}
+	return snapshot
+}
+
+// putManifest records the manifest whose parts were all fetched from url.
+func (s *shardStore) putManifest(url string, data []byte) {
+	if s == nil {
+		return
+	}
+	path := s.manifestPath(url)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	_ = os.WriteFile(path, data, 0644)
+}
+
+func (s *shardStore) manifestPath(url string) string {
+	return filepath.Join(s.dir, "manifests", sha256Hex([]byte(url))+".json")
+}
+
+func (s *shardStore) remove(key string) {
+	if s != nil {
+		_ = os.Remove(s.path(key))
+	}
+}
+
+// prune removes shards not used within maxAge and returns the bytes freed.
+func (s *shardStore) prune(maxAge time.Duration) int64 {
+	if s == nil {
+		return 0
+	}
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return 0
+	}
+	var freed int64
+	cutoff := time.Now().Add(-maxAge)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err == nil && info.ModTime().Before(cutoff) && os.Remove(filepath.Join(s.dir, entry.Name())) == nil {
+			freed += info.Size()
+		}
+	}
+	return freed
+}
@@ -0,0 +1,246 @@
+package registry
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/majiayu000/caude-skill-manager/internal/httpclient"
+)
+
+func TestMain(m *testing.M) {
+	// Failing test servers would otherwise be retried with real backoff.
+	httpclient.RetryBackoff = time.Millisecond
+	os.Exit(m.Run())
+}
+
+// shardedRegistryServer serves a registry with n plain shards and counts
+// requests per shard. fail, when set, decides whether a shard request fails.
+func shardedRegistryServer(t *testing.T, n int, fail func(shard, request int) int) (*httptest.Server, func(int) int) {
+	t.Helper()
+	var mu sync.Mutex
+	requests := make(map[int]int)
+
+	manifest := `{"total_count":` + fmt.Sprint(n) + `,"shards":[`
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			manifest += ","
+		}
+		manifest += fmt.Sprintf(`{"path":"registry-shards/%02d.json","count":1}`, i)
+	}
+	manifest += `]}`
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/registry.json", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, `{"deprecated_full_payload":true,"manifest":"registry-manifest.json"}`)
+	})
+	mux.HandleFunc("/registry-manifest.json", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, manifest)
+	})
+	for i := 0; i < n; i++ {
+		i := i
+		mux.HandleFunc(fmt.Sprintf("/registry-shards/%02d.json", i), func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			requests[i]++
+			count := requests[i]
+			mu.Unlock()
+			if fail != nil {
+				if status := fail(i, count); status != 0 {
+					http.Error(w, "failed", status)
+					return
+				}
+			}
+			writeJSON(t, w, fmt.Sprintf(`{"skills":[{"name":"skill-%02d","install":"owner/repo/skill-%02d"}]}`, i, i))
+		})
+	}
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, func(i int) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests[i]
+	}
+}
+
+func TestFetchRegistryResumesFromStoredShards(t *testing.T) {
+	server, requests := shardedRegistryServer(t, 6, func(shard, request int) int {
+		if shard == 4 && request == 1 {
+			return http.StatusNotFound
+		}
+		return 0
+	})
+	fetcher := &artifactFetcher{store: &shardStore{dir: t.TempDir()}}
+
+	if _, err := fetchRegistryFromBaseURL(server.URL, fetcher); err == nil {
+		t.Fatal("expected the first fetch to fail")
+	}
+	registry, err := fetchRegistryFromBaseURL(server.URL, fetcher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(registry.Skills) != 6 || registry.Skills[5].Name != "skill-05" {
+		t.Fatalf("unexpected skills: %#v", registry.Skills)
+	}
+	for i := 0; i < 6; i++ {
+		want := 1
+		if i == 4 {
+			want = 2
+		}
+		if got := requests(i); got != want {
+			t.Fatalf("shard %d requested %d times, want %d", i, got, want)
+		}
+	}
+}
+
+func TestFetchRegistryRetriesTransientShardFailures(t *testing.T) {
+	server, requests := shardedRegistryServer(t, 2, func(shard, request int) int {
+		if shard == 1 && request == 1 {
+			return http.StatusServiceUnavailable
+		}
+		return 0
+	})
+
+	registry, err := fetchRegistryFromBaseURL(server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(registry.Skills) != 2 || requests(1) != 2 {
+		t.Fatalf("expected a retried second shard, got %d skills and %d requests", len(registry.Skills), requests(1))
+	}
+}
+
+func TestFetchPartsReportsProgress(t *testing.T) {
+	server, _ := shardedRegistryServer(t, 3, nil)
+
+	var calls []ShardProgress
+	SetProgressHandler(func(p ShardProgress) { calls = append(calls, p) })
+	defer SetProgressHandler(nil)
+
+	if _, err := fetchRegistryFromBaseURL(server.URL, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 4 {
+		t.Fatalf("expected 4 progress calls, got %#v", calls)
+	}
+	last := calls[len(calls)-1]
+	if last.Manifest != "registry-manifest.json" || last.Done != 3 || last.Total != 3 || last.Bytes == 0 {
+		t.Fatalf("unexpected final progress: %#v", last)
+	}
+}
+
+// countingFileServer serves dir and counts requests per path.
+func countingFileServer(t *testing.T, dir string) (*httptest.Server, func(string) int) {
+	t.Helper()
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	files := http.FileServer(http.Dir(dir))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+		files.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server, func(path string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests[path]
+	}
+}
+
+func TestRefreshDownloadsOnlyChangedShards(t *testing.T) {
+	tree := writeSkillTree(t)
+	out := t.TempDir()
+	build := func(now time.Time) {
+		t.Helper()
+		skills, err := ScanSkills(BuildSource{Repo: "corp/skills", Dir: tree})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Build(skills, BuildOptions{OutDir: out, ShardSize: 1, Now: now}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	build(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	server, requests := countingFileServer(t, out)
+	fetcher := &artifactFetcher{store: &shardStore{dir: t.TempDir()}}
+	if _, err := fetchRegistryFromBaseURL(server.URL, fetcher); err != nil {
+		t.Fatal(err)
+	}
+
+	// Change only the lint skill (sorted first, so part-000) and rebuild a
+	// day later.
+	if err := os.WriteFile(filepath.Join(tree, "tools", "lint", "SKILL.md"), []byte("---\ndescription: Lint more things\n---\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	build(time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC))
+
+	var progress []ShardProgress
+	SetProgressHandler(func(p ShardProgress) { progress = append(progress, p) })
+	defer SetProgressHandler(nil)
+
+	registry, err := fetchRegistryFromBaseURL(server.URL, fetcher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if registry.Skills[0].Description != "Lint more things" {
+		t.Fatalf("expected the refreshed description, got %#v", registry.Skills[0])
+	}
+	if got := requests("/registry-shards/part-000.json"); got != 2 {
+		t.Fatalf("changed shard requested %d times, want 2", got)
+	}
+	if got := requests("/registry-shards/part-001.json"); got != 1 {
+		t.Fatalf("unchanged shard requested %d times, want 1", got)
+	}
+	if last := progress[len(progress)-1]; last.Reused != 1 {
+		t.Fatalf("expected one reused shard, got %#v", last)
+	}
+}
+
+func TestRefreshReusesUnhashedShardsWhenManifestStampMatches(t *testing.T) {
+	var mu sync.Mutex
+	manifest := `{"generated_at":"A","shards":[{"path":"00.json","count":1}]}`
+	requests := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests[r.URL.Path]++
+		switch r.URL.Path {
+		case "/registry.json":
+			writeJSON(t, w, `{"deprecated_full_payload":true,"manifest":"registry-manifest.json"}`)
+		case "/registry-manifest.json":
+			writeJSON(t, w, manifest)
+		default:
+			name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/"), ".json")
+			writeJSON(t, w, `{"skills":[{"name":"skill-`+name+`","install":"owner/repo/`+name+`"}]}`)
+		}
+	}))
+	t.Cleanup(server.Close)
+	fetcher := &artifactFetcher{store: &shardStore{dir: t.TempDir()}}
+
+	fetch := func(next string) {
+		t.Helper()
+		mu.Lock()
+		manifest = next
+		mu.Unlock()
+		if _, err := fetchRegistryFromBaseURL(server.URL, fetcher); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fetch(manifest)
+	fetch(`{"generated_at":"A","shards":[{"path":"00.json","count":1},{"path":"01.json","count":1}]}`)
+	if requests["/00.json"] != 1 || requests["/01.json"] != 1 {
+		t.Fatalf("same stamp: unexpected requests %v", requests)
+	}
+	fetch(`{"generated_at":"B","shards":[{"path":"00.json","count":1},{"path":"01.json","count":1}]}`)
+	if requests["/00.json"] != 2 || requests["/01.json"] != 2 {
+		t.Fatalf("new stamp: unexpected requests %v", requests)
+	}
+}
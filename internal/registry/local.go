@@ -0,0 +1,40 @@
+package registry
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// localArtifactPath returns the filesystem path for a file:// URL or a plain
+// path, and false for network URLs.
+func localArtifactPath(rawURL string) (string, bool) {
+	if strings.HasPrefix(rawURL, "file://") {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return "", false
+		}
+		return filepath.FromSlash(u.Path), true
+	}
+	if strings.Contains(rawURL, "://") {
+		return "", false
+	}
+	return filepath.FromSlash(rawURL), true
+}
+
+// readLocalArtifact reads a registry artifact from disk.
+func readLocalArtifact(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, &os.PathError{Op: "registry artifact not found", Path: path, Err: os.ErrNotExist}
+	}
+	return data, err
+}
+
+// Local reports whether the registry is read from disk. Local registries are
+// never cached, so edits to them show up immediately.
+func (s Source) Local() bool {
+	_, ok := localArtifactPath(s.BaseURL)
+	return ok
+}
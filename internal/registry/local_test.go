@@ -0,0 +1,90 @@
+package registry
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeLocalRegistry lays out a pointer/manifest/gzip-shard registry on disk.
+func writeLocalRegistry(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"registry.json":                           `{"deprecated_full_payload":true,"manifest":"registry-manifest.json"}`,
+		"registry-manifest.json":                  `{"schema_version":1,"total_count":1,"shards":[{"path":"registry-shards/part-000.json"}]}`,
+		"registry-shards/part-000.json":           `{"skills":[{"name":"pdf","repo":"corp/skills","path":"pdf","category":"documents"}]}`,
+		"docs/search-index.json":                  `{"deprecated_full_payload":true,"manifest":"search-index-manifest.json"}`,
+		"docs/search-index-manifest.json":         `{"v":"1","total_count":1,"shards":[{"gzip_path":"search-shards/part-000.json.gz"}]}`,
+		"docs/categories/documents.json":          `{"category":"documents","skills":[{"name":"pdf","install":"corp/skills/pdf"}]}`,
+		"docs/search-shards/part-000.json.gz.src": `{"v":"1","count":1,"s":[{"n":"pdf","c":"doc","i":"corp/skills/pdf"}]}`,
+	}
+	for rel, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		data := []byte(content)
+		if filepath.Ext(rel) == ".src" {
+			path = path[:len(path)-len(".src")]
+			var b bytes.Buffer
+			gz := gzip.NewWriter(&b)
+			_, _ = gz.Write(data)
+			_ = gz.Close()
+			data = b.Bytes()
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestLocalDirectoryRegistry(t *testing.T) {
+	root := writeLocalRegistry(t)
+	writeConfigForRegistryTest(t, root)
+
+	skill, source, err := Lookup("pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source != RegistrySourceLocal || skill.Install != "corp/skills/pdf" || skill.Category != "documents" {
+		t.Fatalf("unexpected lookup: %s %#v", source, skill)
+	}
+
+	registry, _, err := FetchRegistryWithSource()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(registry.Skills) != 1 || registry.Skills[0].Install != "corp/skills/pdf" {
+		t.Fatalf("unexpected registry: %#v", registry.Skills)
+	}
+
+	skills, _, err := GetByCategoryWithSource("documents")
+	if err != nil || len(skills) != 1 {
+		t.Fatalf("unexpected category result: %#v, %v", skills, err)
+	}
+
+	if _, err := os.Stat(configRegistryCachePathForTest(t)); !os.IsNotExist(err) {
+		t.Fatalf("expected local registry not to be cached, stat err = %v", err)
+	}
+}
+
+func TestFileURLRegistry(t *testing.T) {
+	root := writeLocalRegistry(t)
+	writeConfigForRegistryTest(t, "file://"+filepath.ToSlash(root))
+
+	idx, source, err := FetchSearchIndex()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source != RegistrySourceLocal || len(idx.Skills) != 1 {
+		t.Fatalf("unexpected index: %s %#v", source, idx)
+	}
+
+	if _, err := fetchBytes("file://" + filepath.ToSlash(root) + "/missing.json"); !os.IsNotExist(err) {
+		t.Fatalf("expected not-exist error, got %v", err)
+	}
+}
@@ -1,10 +1,8 @@
 package registry
 
 import (
-	"compress/gzip"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -12,6 +10,7 @@ import (
 	"time"
 
 	"github.com/majiayu000/caude-skill-manager/internal/config"
+	"github.com/majiayu000/caude-skill-manager/internal/httpclient"
 )
 
 const (
@@ -35,6 +34,10 @@ type RegistrySource string
 const (
 	RegistrySourceRemote RegistrySource = "remote"
 	RegistrySourceCache  RegistrySource = "cache"
+	RegistrySourceLocal  RegistrySource = "local" // file:// or directory registry
+	// RegistrySourceStale is cached data past its TTL, served because the
+	// registry could not be reached or network access is disabled.
+	RegistrySourceStale RegistrySource = "stale"
 )
 
 // Skill represents a skill in the registry
@@ -50,6 +53,9 @@ type Skill struct {
 	Source      string   `json:"source"`
 	Stars       int      `json:"stars"`
 	Featured    bool     `json:"featured"`
+	Digest      string   `json:"digest,omitempty"`   // content digest of the skill tree, see internal/integrity
+	Commit      string   `json:"commit,omitempty"`   // commit the digest was computed at
+	Registry    string   `json:"registry,omitempty"` // name of the configured registry the record came from
 }
 
 // GitHubURL returns the GitHub URL for viewing this skill's SKILL.md
@@ -62,7 +68,11 @@ func (s *Skill) GitHubURL() string {
 		branch = "main"
 	}
 	if s.Path != "" {
-		return fmt.Sprintf("https://github.com/%s/blob/%s/%s/SKILL.md", s.Repo, branch, s.Path)
+		path := strings.Trim(s.Path, "/")
+		if strings.HasSuffix(strings.ToLower(path), ".md") {
+			return fmt.Sprintf("https://github.com/%s/blob/%s/%s", s.Repo, branch, path)
+		}
+		return fmt.Sprintf("https://github.com/%s/blob/%s/%s/SKILL.md", s.Repo, branch, path)
 	}
 	return fmt.Sprintf("https://github.com/%s/blob/%s/SKILL.md", s.Repo, branch)
 }
@@ -107,6 +117,9 @@ type SearchIndexEntry struct {
 	Stars       int      `json:"r"`
 	Install     string   `json:"i"`
 	Branch      string   `json:"b"`
+	Digest      string   `json:"h,omitempty"`
+	Commit      string   `json:"m,omitempty"`
+	Registry    string   `json:"-"` // set when loaded from a configured registry
 }
 
 // SearchIndex represents the compact search index
@@ -114,12 +127,27 @@ type SearchIndex struct {
 	Version    string             `json:"v"`
 	TotalCount int                `json:"t"`
 	Skills     []SearchIndexEntry `json:"s"`
+
+	lookup *searchLookup // set when loaded from or saved to the cache
 }
 
 type artifactPart struct {
-	Path     string `json:"path"`
-	GzipPath string `json:"gzip_path"`
-	Count    int    `json:"count"`
+	Path       string `json:"path"`
+	GzipPath   string `json:"gzip_path"`
+	Count      int    `json:"count"`
+	SHA256     string `json:"sha256,omitempty"`      // hash of the bytes at Path
+	GzipSHA256 string `json:"gzip_sha256,omitempty"` // hash of the bytes at GzipPath
+}
+
+// hashFor returns the listed sha256 for one of the part's paths.
+func (p artifactPart) hashFor(path string) string {
+	if path == p.GzipPath && p.GzipSHA256 != "" {
+		return p.GzipSHA256
+	}
+	if path == p.Path {
+		return p.SHA256
+	}
+	return ""
 }
 
 type searchIndexPayload struct {
@@ -137,7 +165,7 @@ type searchManifest struct {
 }
 
 type searchShard struct {
-	Version string             `json:"v"`
+	Version string             `json:"v,omitempty"`
 	Count   int                `json:"count"`
 	Skills  []SearchIndexEntry `json:"s"`
 }
@@ -150,51 +178,37 @@ type categoryManifest struct {
 	Parts     []artifactPart `json:"parts"`
 }
 
-var httpClient = &http.Client{
-	Timeout: 10 * time.Second,
-}
-
-func registryBaseURL() string {
-	base := config.GetRegistryBaseURL()
-	if base == "" || base == "github" {
-		return DefaultRegistryURL
-	}
-	return strings.TrimRight(base, "/")
-}
-
-func docsBaseURL() string {
-	return registryBaseURL() + "/docs"
-}
-
 func artifactURL(baseURL, path string) string {
 	return strings.TrimRight(baseURL, "/") + "/" + strings.TrimLeft(path, "/")
 }
 
 func fetchJSON(url string, target any) error {
-	resp, err := httpClient.Get(url)
+	data, err := fetchBytes(url)
 	if err != nil {
 		return err
 	}
-	defer func() { _ = resp.Body.Close() }()
+	return decodeJSON(url, data, target)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("returned status %d", resp.StatusCode)
+// fetchBytes returns the raw body of a registry artifact, reading file://
+// URLs and plain paths from disk.
+func fetchBytes(url string) ([]byte, error) {
+	if path, ok := localArtifactPath(url); ok {
+		return readLocalArtifact(path)
 	}
 
-	var reader io.Reader = resp.Body
-	if strings.HasSuffix(url, ".gz") {
-		gzReader, err := gzip.NewReader(resp.Body)
-		if err != nil {
-			return err
-		}
-		defer func() { _ = gzReader.Close() }()
-		reader = gzReader
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
 	}
-
-	if err := json.NewDecoder(reader).Decode(target); err != nil {
-		return err
+	resp, data, err := httpclient.Fetch(req)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+	if resp.StatusCode != http.StatusOK {
+		return nil, &statusError{Code: resp.StatusCode}
+	}
+	return data, nil
 }
 
 // FetchRegistry fetches the full registry
@@ -204,70 +218,168 @@ func FetchRegistry() (*Registry, error) {
 }
 
 // FetchRegistryWithSource fetches the full registry and indicates data source.
+// With several registries configured, their skills are concatenated in
+// priority order and a *PartialError reports any that failed.
 func FetchRegistryWithSource() (*Registry, RegistrySource, error) {
-	if cached, cacheErr := loadRegistryCache(); cacheErr == nil {
-		return cached, RegistrySourceCache, nil
+	sources, err := Sources()
+	if err != nil {
+		return nil, "", err
 	}
 
-	registry, err := fetchRegistryFromBaseURL(registryBaseURL())
-	if err != nil {
-		if cached, cacheErr := loadRegistryCache(); cacheErr == nil {
-			return cached, RegistrySourceCache, nil
+	var merged *Registry
+	source, err := eachSource(sources, func(src Source) (RegistrySource, error) {
+		registry, source, err := fetchRegistryForSource(src)
+		if err != nil {
+			return "", err
+		}
+		if merged == nil {
+			merged = registry
+			return source, nil
 		}
+		merged.Skills = append(merged.Skills, registry.Skills...)
+		merged.TotalCount += registry.TotalCount
+		return source, nil
+	})
+	if merged == nil {
+		return nil, "", err
+	}
+	return merged, source, err
+}
+
+func fetchRegistryForSource(src Source) (*Registry, RegistrySource, error) {
+	if src.Local() {
+		return fetchLocalRegistry(src)
+	}
+
+	registry, source, err := withCachePolicy(
+		func() (*Registry, error) { return loadRegistryCache(src) },
+		func() (*Registry, error) { return loadStaleRegistryCache(src) },
+		func() (*Registry, error) {
+			fetcher, err := src.fetcher()
+			if err != nil {
+				return nil, err
+			}
+			return fetchRegistryFromBaseURL(src.BaseURL, fetcher)
+		},
+		func(registry *Registry) error { return saveRegistryCache(src, registry) },
+	)
+	if err != nil {
 		return nil, "", fmt.Errorf("failed to fetch registry: %w", err)
 	}
+	tagRegistrySkills(registry.Skills, src.Name)
+	return registry, source, nil
+}
 
-	if err := saveRegistryCache(registry); err != nil {
-		return registry, RegistrySourceRemote, nil
+func fetchLocalRegistry(src Source) (*Registry, RegistrySource, error) {
+	fetcher, err := src.fetcher()
+	if err != nil {
+		return nil, "", err
+	}
+	registry, err := fetchRegistryFromBaseURL(src.BaseURL, fetcher)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read local registry: %w", err)
+	}
+	tagRegistrySkills(registry.Skills, src.Name)
+	return registry, RegistrySourceLocal, nil
+}
+
+func tagRegistrySkills(skills []Skill, name string) {
+	for i := range skills {
+		skills[i].Registry = name
 	}
-	return registry, RegistrySourceRemote, nil
 }
 
 // FetchFeatured fetches the top featured skills (52KB vs 44MB full registry)
+// from the highest-priority registry.
 func FetchFeatured() (*Featured, error) {
-	url := docsBaseURL() + "/featured.json"
+	featured, _, err := FetchFeaturedWithSource()
+	return featured, err
+}
 
-	resp, err := httpClient.Get(url)
+// FetchFeaturedWithSource fetches the featured skills and indicates data
+// source.
+func FetchFeaturedWithSource() (*Featured, RegistrySource, error) {
+	src, err := primarySource()
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch featured: %w", err)
+		return nil, "", err
 	}
-	defer resp.Body.Close()
+	return cachedForSource(src, config.FeaturedCachePathFor(src.Name), nil, fetchFeaturedFor(src))
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("featured returned status %d", resp.StatusCode)
+func fetchFeaturedFor(src Source) func(*artifactFetcher) (*Featured, error) {
+	return func(fetcher *artifactFetcher) (*Featured, error) {
+		var featured Featured
+		if err := fetcher.getJSON(artifactURL(src.docsBaseURL(), "featured.json"), &featured); err != nil {
+			return nil, fmt.Errorf("failed to fetch featured: %w", err)
+		}
+		return &featured, nil
+	}
+}
+
+// FetchCategory fetches skills for a specific category from the docs of the
+// highest-priority registry.
+func FetchCategory(category string) (*Category, error) {
+	src, err := primarySource()
+	if err != nil {
+		return nil, err
 	}
+	cat, _, err := fetchCategoryForSource(src, category)
+	return cat, err
+}
 
-	var featured Featured
-	if err := json.NewDecoder(resp.Body).Decode(&featured); err != nil {
-		return nil, fmt.Errorf("failed to parse featured: %w", err)
+func fetchCategoryForSource(src Source, category string) (*Category, RegistrySource, error) {
+	cat, source, err := cachedForSource(src, categoryCachePath(src.Name, category), checkCategoryCache, fetchCategoryFor(src, category))
+	if err != nil {
+		return nil, "", err
 	}
+	tagRegistrySkills(cat.Skills, src.Name)
+	return cat, source, nil
+}
 
-	return &featured, nil
+func fetchCategoryFor(src Source, category string) func(*artifactFetcher) (*Category, error) {
+	return func(fetcher *artifactFetcher) (*Category, error) {
+		return fetchCategoryFromBaseURL(src.docsBaseURL(), category, fetcher)
+	}
 }
 
-// FetchCategory fetches skills for a specific category from docs
-func FetchCategory(category string) (*Category, error) {
-	return fetchCategoryFromBaseURL(docsBaseURL(), category)
+// categoryCachePath returns the cache file for a category, or "" when the
+// name cannot safely be used as a file name.
+func categoryCachePath(registryName, category string) string {
+	if !registryNamePattern.MatchString(category) {
+		return ""
+	}
+	return filepath.Join(config.CategoryCacheDirFor(registryName), category+".json")
+}
+
+func checkCategoryCache(cat *Category) error {
+	if cat.DeprecatedFullPayload && len(cat.Skills) == 0 {
+		return fmt.Errorf("category cache contains pointer without skills")
+	}
+	return nil
 }
 
-func fetchCategoryFromBaseURL(baseURL, category string) (*Category, error) {
+func fetchCategoryFromBaseURL(baseURL, category string, fetcher *artifactFetcher) (*Category, error) {
 	var cat Category
 	url := artifactURL(baseURL, fmt.Sprintf("categories/%s.json", category))
-	if err := fetchJSON(url, &cat); err != nil {
+	if err := fetcher.getJSON(url, &cat); err != nil {
 		return nil, fmt.Errorf("failed to fetch category: %w", err)
 	}
 
 	if cat.DeprecatedFullPayload && cat.Manifest != "" {
-		return fetchCategoryFromManifest(baseURL, cat.Manifest)
+		return fetchCategoryFromManifest(baseURL, cat.Manifest, fetcher)
+	}
+	if err := fetcher.artifactVerifier().requireSignedManifest(fmt.Sprintf("categories/%s.json", category)); err != nil {
+		return nil, fmt.Errorf("failed to fetch category: %w", err)
 	}
 
 	normalizeRegistrySkills(cat.Skills)
 	return &cat, nil
 }
 
-func fetchCategoryFromManifest(baseURL, manifestPath string) (*Category, error) {
+func fetchCategoryFromManifest(baseURL, manifestPath string, fetcher *artifactFetcher) (*Category, error) {
 	var manifest categoryManifest
-	if err := fetchJSON(artifactURL(baseURL, manifestPath), &manifest); err != nil {
+	manifestData, err := fetcher.fetchManifest(baseURL, manifestPath, &manifest)
+	if err != nil {
 		return nil, fmt.Errorf("failed to fetch category manifest: %w", err)
 	}
 
@@ -278,19 +390,14 @@ func fetchCategoryFromManifest(baseURL, manifestPath string) (*Category, error)
 		Count:     manifest.Count,
 	}
 
-	for _, part := range manifest.Parts {
-		partPath := part.GzipPath
-		if partPath == "" {
-			partPath = part.Path
-		}
-		if partPath == "" {
-			return nil, fmt.Errorf("category manifest contains empty part path")
-		}
-
-		var payload Category
-		if err := fetchJSON(artifactURL(baseURL, partPath), &payload); err != nil {
-			return nil, fmt.Errorf("failed to fetch category part %s: %w", partPath, err)
-		}
+	payloads := make([]Category, len(manifest.Parts))
+	err = fetcher.fetchParts(baseURL, "category part", manifestPath, manifestData, manifest.Parts, preferredPartPath, func(i int, path string, data []byte) error {
+		return decodeJSON(path, data, &payloads[i])
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, payload := range payloads {
 		normalizeRegistrySkills(payload.Skills)
 		category.Skills = append(category.Skills, payload.Skills...)
 	}
@@ -301,64 +408,134 @@ func fetchCategoryFromManifest(baseURL, manifestPath string) (*Category, error)
 	return category, nil
 }
 
-// FetchCategoryIndex fetches the category index listing all available categories
+// preferredPartPath returns the gzip path of a part, or its plain path.
+func preferredPartPath(part artifactPart) []string {
+	if part.GzipPath != "" {
+		return []string{part.GzipPath}
+	}
+	if part.Path != "" {
+		return []string{part.Path}
+	}
+	return nil
+}
+
+// FetchCategoryIndex fetches the category index listing all available
+// categories in the highest-priority registry.
 func FetchCategoryIndex() (*CategoryIndex, error) {
-	url := docsBaseURL() + "/categories/index.json"
+	idx, _, err := FetchCategoryIndexWithSource()
+	return idx, err
+}
 
-	resp, err := httpClient.Get(url)
+// FetchCategoryIndexWithSource fetches the category index and indicates data
+// source.
+func FetchCategoryIndexWithSource() (*CategoryIndex, RegistrySource, error) {
+	src, err := primarySource()
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch category index: %w", err)
+		return nil, "", err
 	}
-	defer resp.Body.Close()
+	return cachedForSource(src, config.CategoryIndexCachePathFor(src.Name), nil, fetchCategoryIndexFor(src))
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("category index returned status %d", resp.StatusCode)
+func fetchCategoryIndexFor(src Source) func(*artifactFetcher) (*CategoryIndex, error) {
+	return func(fetcher *artifactFetcher) (*CategoryIndex, error) {
+		var idx CategoryIndex
+		if err := fetcher.getJSON(artifactURL(src.docsBaseURL(), "categories/index.json"), &idx); err != nil {
+			return nil, fmt.Errorf("failed to fetch category index: %w", err)
+		}
+		return &idx, nil
 	}
+}
 
-	var idx CategoryIndex
-	if err := json.NewDecoder(resp.Body).Decode(&idx); err != nil {
-		return nil, fmt.Errorf("failed to parse category index: %w", err)
+// FetchSearchIndex fetches the search index, following the registry shard
+// manifest when the compatibility entry point is a pointer. Entries from all
+// configured registries are merged in priority order.
+func FetchSearchIndex() (*SearchIndex, RegistrySource, error) {
+	sources, err := Sources()
+	if err != nil {
+		return nil, "", err
 	}
+	return fetchSearchIndexFrom(sources)
+}
 
-	return &idx, nil
+func fetchSearchIndexFrom(sources []Source) (*SearchIndex, RegistrySource, error) {
+	var merged *SearchIndex
+	source, err := eachSource(sources, func(src Source) (RegistrySource, error) {
+		idx, source, err := fetchSearchIndexForSource(src)
+		if err != nil {
+			return "", err
+		}
+		if merged == nil {
+			merged = idx
+			return source, nil
+		}
+		merged.merge(idx)
+		return source, nil
+	})
+	if merged == nil {
+		return nil, "", err
+	}
+	return merged, source, err
 }
 
-// FetchSearchIndex fetches the search index, following the registry shard
-// manifest when the compatibility entry point is a pointer.
-func FetchSearchIndex() (*SearchIndex, RegistrySource, error) {
-	if cached, err := loadSearchIndexCache(); err == nil {
-		return cached, RegistrySourceCache, nil
+func fetchSearchIndexForSource(src Source) (*SearchIndex, RegistrySource, error) {
+	fetch := func() (*SearchIndex, error) {
+		fetcher, err := src.fetcher()
+		if err != nil {
+			return nil, err
+		}
+		return fetchSearchIndexFromBaseURL(src.docsBaseURL(), fetcher)
+	}
+	if src.Local() {
+		idx, err := fetch()
+		if err != nil {
+			return nil, "", err
+		}
+		tagSearchEntries(idx.Skills, src.Name)
+		return idx, RegistrySourceLocal, nil
 	}
 
-	idx, err := fetchSearchIndexFromBaseURL(docsBaseURL())
+	idx, source, err := withCachePolicy(
+		func() (*SearchIndex, error) { return loadSearchIndexCache(src) },
+		func() (*SearchIndex, error) { return loadStaleSearchIndexCache(src) },
+		fetch,
+		func(idx *SearchIndex) error { return saveSearchIndexCache(src, idx) },
+	)
 	if err != nil {
 		return nil, "", err
 	}
+	tagSearchEntries(idx.Skills, src.Name)
+	return idx, source, nil
+}
 
-	_ = saveSearchIndexCache(idx)
-	return idx, RegistrySourceRemote, nil
+func tagSearchEntries(entries []SearchIndexEntry, name string) {
+	for i := range entries {
+		entries[i].Registry = name
+	}
 }
 
-func fetchSearchIndexFromBaseURL(baseURL string) (*SearchIndex, error) {
-	idx, err := fetchSearchIndexFromPath(baseURL, "search-index.json")
+func fetchSearchIndexFromBaseURL(baseURL string, fetcher *artifactFetcher) (*SearchIndex, error) {
+	idx, err := fetchSearchIndexFromPath(baseURL, "search-index.json", fetcher)
 	if err == nil {
 		return idx, nil
 	}
-	idx, gzipErr := fetchSearchIndexFromPath(baseURL, "search-index.json.gz")
+	idx, gzipErr := fetchSearchIndexFromPath(baseURL, "search-index.json.gz", fetcher)
 	if gzipErr == nil {
 		return idx, nil
 	}
 	return nil, fmt.Errorf("failed to fetch search index: %w", err)
 }
 
-func fetchSearchIndexFromPath(baseURL, indexPath string) (*SearchIndex, error) {
+func fetchSearchIndexFromPath(baseURL, indexPath string, fetcher *artifactFetcher) (*SearchIndex, error) {
 	var payload searchIndexPayload
-	if err := fetchJSON(artifactURL(baseURL, indexPath), &payload); err != nil {
+	if err := fetcher.getJSON(artifactURL(baseURL, indexPath), &payload); err != nil {
 		return nil, err
 	}
 
 	if payload.DeprecatedFullPayload && payload.Manifest != "" {
-		return fetchSearchIndexFromManifest(baseURL, payload.Manifest, payload.Version, payload.TotalCount)
+		return fetchSearchIndexFromManifest(baseURL, payload.Manifest, payload.Version, payload.TotalCount, fetcher)
+	}
+	if err := fetcher.artifactVerifier().requireSignedManifest(indexPath); err != nil {
+		return nil, err
 	}
 
 	return &SearchIndex{
@@ -368,9 +545,10 @@ func fetchSearchIndexFromPath(baseURL, indexPath string) (*SearchIndex, error) {
 	}, nil
 }
 
-func fetchSearchIndexFromManifest(baseURL, manifestPath, fallbackVersion string, fallbackTotal int) (*SearchIndex, error) {
+func fetchSearchIndexFromManifest(baseURL, manifestPath, fallbackVersion string, fallbackTotal int, fetcher *artifactFetcher) (*SearchIndex, error) {
 	var manifest searchManifest
-	if err := fetchJSON(artifactURL(baseURL, manifestPath), &manifest); err != nil {
+	manifestData, err := fetcher.fetchManifest(baseURL, manifestPath, &manifest)
+	if err != nil {
 		return nil, fmt.Errorf("failed to fetch search manifest: %w", err)
 	}
 
@@ -385,19 +563,14 @@ func fetchSearchIndexFromManifest(baseURL, manifestPath, fallbackVersion string,
 		idx.TotalCount = fallbackTotal
 	}
 
-	for _, shard := range manifest.Shards {
-		shardPath := shard.GzipPath
-		if shardPath == "" {
-			shardPath = shard.Path
-		}
-		if shardPath == "" {
-			return nil, fmt.Errorf("search manifest contains empty shard path")
-		}
-
-		var payload searchShard
-		if err := fetchJSON(artifactURL(baseURL, shardPath), &payload); err != nil {
-			return nil, fmt.Errorf("failed to fetch search shard %s: %w", shardPath, err)
-		}
+	payloads := make([]searchShard, len(manifest.Shards))
+	err = fetcher.fetchParts(baseURL, "search shard", manifestPath, manifestData, manifest.Shards, preferredPartPath, func(i int, path string, data []byte) error {
+		return decodeJSON(path, data, &payloads[i])
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, payload := range payloads {
 		idx.Skills = append(idx.Skills, payload.Skills...)
 	}
 
@@ -438,10 +611,14 @@ func entryToSkill(e SearchIndexEntry) Skill {
 		Description: e.Description,
 		Install:     install,
 		Repo:        repoFromInstallRef(install),
+		Path:        pathFromInstallRef(install),
 		Branch:      e.Branch,
 		Category:    cat,
 		Tags:        e.Tags,
 		Stars:       e.Stars,
+		Digest:      e.Digest,
+		Commit:      e.Commit,
+		Registry:    e.Registry,
 	}
 }
 
@@ -451,17 +628,25 @@ func Search(keyword string) ([]Skill, error) {
 	return skills, err
 }
 
-// SearchWithSource searches using the compact search index (9MB gzip vs 44MB full registry)
+// SearchWithSource searches using the compact search index (9MB gzip vs 44MB full registry).
+// Results from all configured registries are returned in priority order; a
+// *PartialError alongside results reports registries that could not be read.
 func SearchWithSource(keyword string) ([]Skill, RegistrySource, error) {
 	idx, source, err := FetchSearchIndex()
-	if err != nil {
+	if idx == nil {
 		return nil, "", err
 	}
 
+	return searchInIndex(idx, keyword), source, err
+}
+
+// searchInIndex returns the installable entries whose name, description or
+// a tag contains keyword, case-insensitively.
+func searchInIndex(idx *SearchIndex, keyword string) []Skill {
 	keyword = strings.ToLower(keyword)
 	var results []Skill
 
-	for _, entry := range idx.Skills {
+	for _, entry := range idx.candidateEntries(idx.keywordCandidates(keyword)) {
 		if !isInstallableSkillRef(entry.Install) {
 			continue
 		}
@@ -484,7 +669,7 @@ func SearchWithSource(keyword string) ([]Skill, RegistrySource, error) {
 		}
 	}
 
-	return dedupeSkills(results), source, nil
+	return dedupeSkills(results)
 }
 
 // GetByCategory returns skills in a category
@@ -493,15 +678,37 @@ func GetByCategory(category string) ([]Skill, error) {
 	return skills, err
 }
 
-// GetByCategoryWithSource returns skills in a category and indicates data source.
+// GetByCategoryWithSource returns skills in a category from all configured
+// registries and indicates data source.
 func GetByCategoryWithSource(category string) ([]Skill, RegistrySource, error) {
-	cat, err := FetchCategory(category)
+	sources, err := Sources()
+	if err != nil {
+		return nil, "", err
+	}
+
+	var results []Skill
+	source, err := eachSource(sources, func(src Source) (RegistrySource, error) {
+		skills, source, err := categorySkillsForSource(src, category)
+		if err != nil {
+			return "", err
+		}
+		results = append(results, skills...)
+		return source, nil
+	})
+	if source == "" {
+		return nil, "", err
+	}
+	return dedupeSkills(results), source, err
+}
+
+func categorySkillsForSource(src Source, category string) ([]Skill, RegistrySource, error) {
+	cat, source, err := fetchCategoryForSource(src, category)
 	if err == nil {
-		return dedupeSkills(cat.Skills), RegistrySourceRemote, nil
+		return cat.Skills, source, nil
 	}
 
 	// Fallback to filtering from full registry.
-	registry, source, err := FetchRegistryWithSource()
+	registry, source, err := fetchRegistryForSource(src)
 	if err != nil {
 		return nil, "", err
 	}
@@ -512,42 +719,94 @@ func GetByCategoryWithSource(category string) ([]Skill, RegistrySource, error) {
 			results = append(results, skill)
 		}
 	}
-	return dedupeSkills(results), source, nil
+	return results, source, nil
 }
 
 // ResolveInstall finds a skill by name and returns its install string.
 func ResolveInstall(name string) (string, RegistrySource, error) {
-	idx, source, err := FetchSearchIndex()
-	if err != nil {
+	skill, source, err := Lookup(name)
+	if skill == nil {
 		return "", "", err
 	}
+	return skill.Install, source, err
+}
+
+// Lookup finds a skill by name and returns its registry record. Registries
+// are searched in priority order; "registry:name" searches only the named
+// registry. A *PartialError alongside a result reports registries that could
+// not be read.
+func Lookup(ref string) (*Skill, RegistrySource, error) {
+	sources, err := Sources()
+	if err != nil {
+		return nil, "", err
+	}
+	name := ref
+	if registryName, skillName, ok := SplitRegistryRef(ref); ok {
+		src, err := SourceByName(registryName)
+		if err != nil {
+			return nil, "", err
+		}
+		sources, name = []Source{src}, skillName
+	}
 
-	install, err := resolveInstallFromIndex(idx, name)
-	return install, source, err
+	idx, source, fetchErr := fetchSearchIndexFrom(sources)
+	if idx == nil {
+		return nil, "", fetchErr
+	}
+
+	skill, err := lookupInIndex(idx, name)
+	if err != nil {
+		if fetchErr != nil {
+			// The skill may be in a registry that could not be read.
+			return nil, "", fmt.Errorf("%w (%v)", err, fetchErr)
+		}
+		return nil, "", err
+	}
+	return skill, source, fetchErr
 }
 
 func resolveInstallFromIndex(idx *SearchIndex, name string) (string, error) {
-	for _, skill := range idx.Skills {
-		install := normalizeInstallForBranch(skill.Install, skill.Branch)
+	skill, err := lookupInIndex(idx, name)
+	if err != nil {
+		return "", err
+	}
+	return skill.Install, nil
+}
+
+func lookupInIndex(idx *SearchIndex, name string) (*Skill, error) {
+	for _, entry := range idx.candidateEntries(idx.nameCandidates(name)) {
+		install := normalizeInstallForBranch(entry.Install, entry.Branch)
 		if !isInstallableSkillRef(install) {
 			continue
 		}
-		if strings.EqualFold(skill.Name, name) {
-			return install, nil
+		if strings.EqualFold(entry.Name, name) {
+			skill := entryToSkill(entry)
+			return &skill, nil
 		}
 	}
 
-	return "", fmt.Errorf("no skill named %q in registry", name)
+	return nil, fmt.Errorf("no skill named %q in registry", name)
+}
+
+func loadRegistryCache(src Source) (*Registry, error) {
+	return readRegistryCache(src, registryTTL())
+}
+
+// loadStaleRegistryCache loads the registry cache regardless of its age.
+func loadStaleRegistryCache(src Source) (*Registry, error) {
+	return readRegistryCache(src, 0)
 }
 
-func loadRegistryCache() (*Registry, error) {
-	path := config.RegistryCachePath()
+// readRegistryCache loads the registry cache of src if it is younger than
+// maxAge and was written for src's URL and key; a zero maxAge accepts any
+// age.
+func readRegistryCache(src Source, maxAge time.Duration) (*Registry, error) {
+	path := config.RegistryCachePathFor(src.Name)
 	info, err := os.Stat(path)
 	if err != nil {
 		return nil, err
 	}
-	ttl := time.Duration(config.GetRegistryTTL()) * time.Hour
-	if time.Since(info.ModTime()) > ttl {
+	if maxAge > 0 && time.Since(info.ModTime()) > maxAge {
 		return nil, fmt.Errorf("registry cache expired")
 	}
 
@@ -555,6 +814,9 @@ func loadRegistryCache() (*Registry, error) {
 	if err != nil {
 		return nil, err
 	}
+	if err := checkCacheStamp(path, data, src.cacheStamp()); err != nil {
+		return nil, err
+	}
 
 	var registry Registry
 	if err := json.Unmarshal(data, &registry); err != nil {
@@ -568,43 +830,46 @@ func loadRegistryCache() (*Registry, error) {
 	return &registry, nil
 }
 
-func saveRegistryCache(registry *Registry) error {
+func saveRegistryCache(src Source, registry *Registry) error {
 	if registry.DeprecatedFullPayload && len(registry.Skills) == 0 {
 		return fmt.Errorf("registry cache cannot save pointer without skills")
 	}
+	return writeCacheFile(config.RegistryCachePathFor(src.Name), src.cacheStamp(), registry)
+}
 
-	path := config.RegistryCachePath()
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
-		return err
-	}
-
-	data, err := json.MarshalIndent(registry, "", "  ")
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(path, data, 0644)
+func loadSearchIndexCache(src Source) (*SearchIndex, error) {
+	return readSearchIndexCache(src, registryTTL())
 }
 
-func searchIndexCachePath() string {
-	return config.SearchIndexCachePath()
+// loadStaleSearchIndexCache loads the search index cache regardless of its age.
+func loadStaleSearchIndexCache(src Source) (*SearchIndex, error) {
+	return readSearchIndexCache(src, 0)
 }
 
-func loadSearchIndexCache() (*SearchIndex, error) {
-	path := searchIndexCachePath()
+// readSearchIndexCache loads the search index cache of src if it is younger
+// than maxAge and was written for src's URL and key; a zero maxAge accepts
+// any age.
+func readSearchIndexCache(src Source, maxAge time.Duration) (*SearchIndex, error) {
+	path := config.SearchIndexCachePathFor(src.Name)
+	stamp := src.cacheStamp()
 	info, err := os.Stat(path)
 	if err != nil {
 		return nil, err
 	}
-	ttl := time.Duration(config.GetRegistryTTL()) * time.Hour
-	if time.Since(info.ModTime()) > ttl {
+	if maxAge > 0 && time.Since(info.ModTime()) > maxAge {
 		return nil, fmt.Errorf("search index cache expired")
 	}
+	if idx, ok := readSearchIndexBinary(path, info, stamp); ok {
+		return idx, nil
+	}
 
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
+	if err := checkCacheStamp(path, data, stamp); err != nil {
+		return nil, err
+	}
 
 	var idx SearchIndex
 	if err := json.Unmarshal(data, &idx); err != nil {
@@ -614,21 +879,18 @@ func loadSearchIndexCache() (*SearchIndex, error) {
 		return nil, fmt.Errorf("search index cache contains pointer without skills")
 	}
 
+	// Rebuild a missing or outdated binary cache for the next load.
+	_ = writeSearchIndexBinary(path, stamp, &idx)
 	return &idx, nil
 }
 
-func saveSearchIndexCache(idx *SearchIndex) error {
-	path := searchIndexCachePath()
-	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+func saveSearchIndexCache(src Source, idx *SearchIndex) error {
+	path := config.SearchIndexCachePathFor(src.Name)
+	stamp := src.cacheStamp()
+	if err := writeCacheFile(path, stamp, idx); err != nil {
 		return err
 	}
-
-	data, err := json.Marshal(idx)
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(path, data, 0644)
+	return writeSearchIndexBinary(path, stamp, idx)
 }
 
 func dedupeSkills(skills []Skill) []Skill {
@@ -18,7 +18,7 @@ type registryManifest struct {
 
 type registryShard struct {
 	SchemaVersion registrySchemaVersion `json:"schema_version"`
-	GeneratedAt   string                `json:"generated_at"`
+	GeneratedAt   string                `json:"generated_at,omitempty"`
 	Shard         string                `json:"shard"`
 	Count         int                   `json:"count"`
 	Skills        []Skill               `json:"skills"`
@@ -40,9 +40,9 @@ func (v *registrySchemaVersion) UnmarshalJSON(data []byte) error {
 	return fmt.Errorf("invalid schema_version %s", strings.TrimSpace(string(data)))
 }
 
-func fetchRegistryFromBaseURL(baseURL string) (*Registry, error) {
+func fetchRegistryFromBaseURL(baseURL string, fetcher *artifactFetcher) (*Registry, error) {
 	var registry Registry
-	if err := fetchJSON(artifactURL(baseURL, "registry.json"), &registry); err != nil {
+	if err := fetcher.getJSON(artifactURL(baseURL, "registry.json"), &registry); err != nil {
 		return nil, err
 	}
 
@@ -50,7 +50,11 @@ func fetchRegistryFromBaseURL(baseURL string) (*Registry, error) {
 		if registry.Manifest == "" {
 			return nil, fmt.Errorf("registry pointer is missing manifest")
 		}
-		return fetchRegistryFromManifest(baseURL, registry.Manifest, &registry)
+		return fetchRegistryFromManifest(baseURL, registry.Manifest, &registry, fetcher)
+	}
+
+	if err := fetcher.artifactVerifier().requireSignedManifest("registry.json"); err != nil {
+		return nil, err
 	}
 
 	for i := range registry.Skills {
@@ -59,9 +63,10 @@ func fetchRegistryFromBaseURL(baseURL string) (*Registry, error) {
 	return &registry, nil
 }
 
-func fetchRegistryFromManifest(baseURL, manifestPath string, pointer *Registry) (*Registry, error) {
+func fetchRegistryFromManifest(baseURL, manifestPath string, pointer *Registry, fetcher *artifactFetcher) (*Registry, error) {
 	var manifest registryManifest
-	if err := fetchJSON(artifactURL(baseURL, manifestPath), &manifest); err != nil {
+	manifestData, err := fetcher.fetchManifest(baseURL, manifestPath, &manifest)
+	if err != nil {
 		return nil, fmt.Errorf("failed to fetch registry manifest %s: %w", manifestPath, err)
 	}
 
@@ -82,22 +87,15 @@ func fetchRegistryFromManifest(baseURL, manifestPath string, pointer *Registry)
 		}
 	}
 
-	for _, shard := range manifest.Shards {
-		shardPaths := registryShardPaths(shard)
-		if len(shardPaths) == 0 {
-			return nil, fmt.Errorf("registry manifest contains empty shard path")
-		}
-
-		var payload registryShard
-		for i, shardPath := range shardPaths {
-			if err := fetchJSON(artifactURL(baseURL, shardPath), &payload); err != nil {
-				if i == len(shardPaths)-1 {
-					return nil, fmt.Errorf("failed to fetch registry shard %s: %w", shardPath, err)
-				}
-				continue
-			}
-			break
-		}
+	payloads := make([]registryShard, len(manifest.Shards))
+	err = fetcher.fetchParts(baseURL, "registry shard", manifestPath, manifestData, manifest.Shards, registryShardPaths, func(i int, path string, data []byte) error {
+		payloads[i] = registryShard{}
+		return decodeJSON(path, data, &payloads[i])
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, payload := range payloads {
 		for i := range payload.Skills {
 			normalizeRegistrySkill(&payload.Skills[i])
 			registry.Skills = append(registry.Skills, payload.Skills[i])
@@ -197,3 +195,17 @@ func repoFromInstallRef(install string) string {
 	}
 	return parts[0] + "/" + parts[1]
 }
+
+func pathFromInstallRef(install string) string {
+	ref := strings.TrimSpace(install)
+	ref = strings.TrimPrefix(ref, "https://github.com/")
+	parts := strings.Split(strings.Trim(ref, "/"), "/")
+	if len(parts) >= 4 && parts[2] == "tree" {
+		// owner/repo/tree/<escaped-branch>/path
+		return strings.Join(parts[4:], "/")
+	}
+	if len(parts) <= 2 {
+		return ""
+	}
+	return strings.Join(parts[2:], "/")
+}
@@ -3,6 +3,7 @@ package registry
 import (
 	"bytes"
 	"compress/gzip"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -29,7 +30,7 @@ func TestFetchRegistryFollowsManifestShards(t *testing.T) {
 	server := httptest.NewServer(mux)
 	defer server.Close()
 
-	registry, err := fetchRegistryFromBaseURL(server.URL)
+	registry, err := fetchRegistryFromBaseURL(server.URL, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -74,7 +75,7 @@ func TestFetchRegistryFallsBackToPlainShardWhenGzipFails(t *testing.T) {
 	server := httptest.NewServer(mux)
 	defer server.Close()
 
-	registry, err := fetchRegistryFromBaseURL(server.URL)
+	registry, err := fetchRegistryFromBaseURL(server.URL, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -101,7 +102,7 @@ func TestFetchRegistryPointerWithoutManifestFails(t *testing.T) {
 	server := httptest.NewServer(mux)
 	defer server.Close()
 
-	_, err := fetchRegistryFromBaseURL(server.URL)
+	_, err := fetchRegistryFromBaseURL(server.URL, nil)
 	if err == nil || !strings.Contains(err.Error(), "missing manifest") {
 		t.Fatalf("expected missing manifest error, got %v", err)
 	}
@@ -122,7 +123,7 @@ func TestFetchRegistrySynthesizesNonMainInstallAsTreeURL(t *testing.T) {
 	server := httptest.NewServer(mux)
 	defer server.Close()
 
-	registry, err := fetchRegistryFromBaseURL(server.URL)
+	registry, err := fetchRegistryFromBaseURL(server.URL, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -147,7 +148,7 @@ func TestFetchRegistryShardFailureIncludesPath(t *testing.T) {
 	server := httptest.NewServer(mux)
 	defer server.Close()
 
-	_, err := fetchRegistryFromBaseURL(server.URL)
+	_, err := fetchRegistryFromBaseURL(server.URL, nil)
 	if err == nil || !strings.Contains(err.Error(), "registry-shards/missing.json") {
 		t.Fatalf("expected shard path in error, got %v", err)
 	}
@@ -165,7 +166,7 @@ func TestFetchRegistryEmptyShardPathErrors(t *testing.T) {
 	server := httptest.NewServer(mux)
 	defer server.Close()
 
-	_, err := fetchRegistryFromBaseURL(server.URL)
+	_, err := fetchRegistryFromBaseURL(server.URL, nil)
 	if err == nil || !strings.Contains(err.Error(), "empty shard path") {
 		t.Fatalf("expected empty shard path error, got %v", err)
 	}
@@ -237,14 +238,14 @@ func TestRegistryCacheRejectsPointerOnlyPayload(t *testing.T) {
 	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		t.Fatal(err)
 	}
-	if err := os.WriteFile(path, []byte(`{"deprecated_full_payload":true,"manifest":"registry-manifest.json"}`), 0644); err != nil {
+	if err := os.WriteFile(path, stampedCacheJSON(t, `{"deprecated_full_payload":true,"manifest":"registry-manifest.json"}`), 0644); err != nil {
 		t.Fatal(err)
 	}
 
-	if _, err := loadRegistryCache(); err == nil {
+	if _, err := loadRegistryCache(defaultSource(t)); err == nil {
 		t.Fatal("expected pointer-only registry cache to be rejected")
 	}
-	if err := saveRegistryCache(&Registry{DeprecatedFullPayload: true, Manifest: "registry-manifest.json"}); err == nil {
+	if err := saveRegistryCache(defaultSource(t), &Registry{DeprecatedFullPayload: true, Manifest: "registry-manifest.json"}); err == nil {
 		t.Fatal("expected pointer-only registry cache save to be rejected")
 	}
 }
@@ -257,7 +258,7 @@ func TestFetchRegistryWithSourceUsesValidCacheBeforeRemote(t *testing.T) {
 	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
 		t.Fatal(err)
 	}
-	if err := os.WriteFile(path, []byte(`{"version":"cached","total_count":1,"skills":[{"name":"cached-skill","install":"owner/repo/.claude/skills/cached/SKILL.md","branch":"master"}]}`), 0644); err != nil {
+	if err := os.WriteFile(path, stampedCacheJSON(t, `{"version":"cached","total_count":1,"skills":[{"name":"cached-skill","install":"owner/repo/.claude/skills/cached/SKILL.md","branch":"master"}]}`), 0644); err != nil {
 		t.Fatal(err)
 	}
 
@@ -292,7 +293,7 @@ func TestFetchSearchIndexFollowsManifestShards(t *testing.T) {
 	server := httptest.NewServer(mux)
 	defer server.Close()
 
-	idx, err := fetchSearchIndexFromBaseURL(server.URL)
+	idx, err := fetchSearchIndexFromBaseURL(server.URL, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -319,7 +320,7 @@ func TestFetchCategoryFollowsManifestParts(t *testing.T) {
 	server := httptest.NewServer(mux)
 	defer server.Close()
 
-	category, err := fetchCategoryFromBaseURL(server.URL, "testing")
+	category, err := fetchCategoryFromBaseURL(server.URL, "testing", nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -450,6 +451,17 @@ func configRegistryCachePathForTest(t *testing.T) string {
 	return filepath.Join(cacheDir, "sk", "registry.json")
 }
 
+// stampedCacheJSON returns body, a JSON object, as written to the cache of
+// the default registry.
+func stampedCacheJSON(t *testing.T, body string) []byte {
+	t.Helper()
+	data, err := marshalStamped(json.RawMessage(body), defaultSource(t).cacheStamp())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
 func writeConfigForRegistryTest(t *testing.T, registryURL string) {
 	t.Helper()
 	home := t.TempDir()
@@ -460,3 +472,42 @@ func writeConfigForRegistryTest(t *testing.T, registryURL string) {
 		t.Fatal(err)
 	}
 }
+
+func TestLookupInIndexReturnsRegistryRecord(t *testing.T) {
+	idx := &SearchIndex{
+		Skills: []SearchIndexEntry{
+			{Name: "frontend-testing", Description: "testing skill", Category: "tst", Tags: []string{"test"}, Stars: 10, Install: "owner/repo/.agents/skills/frontend-testing/SKILL.md", Branch: "master"},
+		},
+	}
+
+	skill, err := lookupInIndex(idx, "Frontend-Testing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if skill.Category != "testing" || skill.Stars != 10 || skill.Repo != "owner/repo" {
+		t.Fatalf("unexpected skill: %#v", skill)
+	}
+	if skill.Path != ".agents/skills/frontend-testing/SKILL.md" {
+		t.Fatalf("unexpected path: %s", skill.Path)
+	}
+	want := "https://github.com/owner/repo/blob/master/.agents/skills/frontend-testing/SKILL.md"
+	if got := skill.GitHubURL(); got != want {
+		t.Fatalf("unexpected GitHub URL: got %s want %s", got, want)
+	}
+}
+
+func TestSearchIndexEntryCarriesIntegrityFields(t *testing.T) {
+	var idx SearchIndex
+	data := `{"v":"1","t":1,"s":[{"n":"pdf","i":"owner/repo/pdf","h":"sha256:abc","m":"0123456789abcdef"}]}`
+	if err := json.Unmarshal([]byte(data), &idx); err != nil {
+		t.Fatal(err)
+	}
+
+	skill, err := lookupInIndex(&idx, "pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if skill.Digest != "sha256:abc" || skill.Commit != "0123456789abcdef" {
+		t.Fatalf("unexpected integrity fields: %#v", skill)
+	}
+}
@@ -0,0 +1,258 @@
+package registry
+
+import (
+	"bufio"
+	"encoding/gob"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// searchIndexFormat versions the binary search index cache; files written
+// with another format are rebuilt from the JSON cache.
+const searchIndexFormat = 1
+
+// searchLookup holds lookup tables over a search index's entries: positions
+// by lowercased name, and by lowercased token of the name, description and
+// tags.
+type searchLookup struct {
+	Names  postingList
+	Tokens postingList
+}
+
+// postingList maps keys to entry positions in index order. It is stored as
+// sorted, flattened slices rather than a map because slices encode and
+// decode several times faster, which dominates loading the binary cache.
+type postingList struct {
+	Keys      []string // sorted
+	Starts    []int32  // Keys[i] has Positions[Starts[i]:Starts[i+1]]
+	Positions []int32
+}
+
+// searchIndexFile is the binary search index cache written beside the JSON
+// cache, so loading skips JSON decoding and rebuilding the lookup tables.
+type searchIndexFile struct {
+	Format int
+	Source cacheStamp // as recorded in the JSON cache
+	Index  SearchIndex
+	Lookup searchLookup
+}
+
+func buildSearchLookup(entries []SearchIndexEntry) *searchLookup {
+	names := make(map[string][]int32, len(entries))
+	tokens := make(map[string][]int32)
+	for i, entry := range entries {
+		pos := int32(i)
+		name := strings.ToLower(entry.Name)
+		names[name] = append(names[name], pos)
+
+		seen := make(map[string]bool)
+		add := func(text string) {
+			for _, token := range searchTokens(text) {
+				if !seen[token] {
+					seen[token] = true
+					tokens[token] = append(tokens[token], pos)
+				}
+			}
+		}
+		add(entry.Name)
+		add(entry.Description)
+		for _, tag := range entry.Tags {
+			add(tag)
+		}
+	}
+	return &searchLookup{Names: newPostingList(names), Tokens: newPostingList(tokens)}
+}
+
+func newPostingList(m map[string][]int32) postingList {
+	p := postingList{Keys: make([]string, 0, len(m)), Starts: make([]int32, 0, len(m)+1)}
+	for key := range m {
+		p.Keys = append(p.Keys, key)
+	}
+	sort.Strings(p.Keys)
+	for _, key := range p.Keys {
+		p.Starts = append(p.Starts, int32(len(p.Positions)))
+		p.Positions = append(p.Positions, m[key]...)
+	}
+	p.Starts = append(p.Starts, int32(len(p.Positions)))
+	return p
+}
+
+// at returns the positions of the i-th key.
+func (p *postingList) at(i int) []int32 {
+	return p.Positions[p.Starts[i]:p.Starts[i+1]]
+}
+
+// get returns the positions of key.
+func (p *postingList) get(key string) []int32 {
+	i := sort.SearchStrings(p.Keys, key)
+	if i < len(p.Keys) && p.Keys[i] == key {
+		return p.at(i)
+	}
+	return nil
+}
+
+// toMap expands the list, shifting positions by offset.
+func (p *postingList) toMap(m map[string][]int32, offset int32) {
+	for i, key := range p.Keys {
+		for _, pos := range p.at(i) {
+			m[key] = append(m[key], pos+offset)
+		}
+	}
+}
+
+// valid reports whether the slices are consistent, so a damaged cache
+// cannot cause out-of-range lookups.
+func (p *postingList) valid(entries int) bool {
+	if len(p.Starts) != len(p.Keys)+1 || p.Starts[0] != 0 || p.Starts[len(p.Keys)] != int32(len(p.Positions)) {
+		return false
+	}
+	for i := 1; i < len(p.Starts); i++ {
+		if p.Starts[i] < p.Starts[i-1] {
+			return false
+		}
+	}
+	for _, pos := range p.Positions {
+		if pos < 0 || int(pos) >= entries {
+			return false
+		}
+	}
+	return true
+}
+
+// searchTokens splits lowercased text into runs of letters and digits.
+func searchTokens(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// nameCandidates returns the positions of entries that may be named name,
+// and false when the index has no lookup tables and must be scanned.
+func (idx *SearchIndex) nameCandidates(name string) ([]int32, bool) {
+	if idx.lookup == nil {
+		return nil, false
+	}
+	return idx.lookup.Names.get(strings.ToLower(name)), true
+}
+
+// keywordCandidates returns the positions, in index order, of entries whose
+// name, description or tags may contain keyword, and false when they must be
+// scanned. A keyword of letters and digits is a substring of some text only
+// if it is a substring of one of its tokens, so matching the token
+// vocabulary finds every candidate.
+func (idx *SearchIndex) keywordCandidates(keyword string) ([]int32, bool) {
+	if idx.lookup == nil {
+		return nil, false
+	}
+	if tokens := searchTokens(keyword); len(tokens) != 1 || tokens[0] != keyword {
+		return nil, false
+	}
+	seen := make(map[int32]bool)
+	var positions []int32
+	tokens := &idx.lookup.Tokens
+	for i, token := range tokens.Keys {
+		if !strings.Contains(token, keyword) {
+			continue
+		}
+		for _, pos := range tokens.at(i) {
+			if !seen[pos] {
+				seen[pos] = true
+				positions = append(positions, pos)
+			}
+		}
+	}
+	sort.Slice(positions, func(i, j int) bool { return positions[i] < positions[j] })
+	return positions, true
+}
+
+// candidateEntries returns the entries at positions, or every entry when ok
+// is false because the index has no lookup tables to narrow them.
+func (idx *SearchIndex) candidateEntries(positions []int32, ok bool) []SearchIndexEntry {
+	if !ok {
+		return idx.Skills
+	}
+	entries := make([]SearchIndexEntry, len(positions))
+	for i, pos := range positions {
+		entries[i] = idx.Skills[pos]
+	}
+	return entries
+}
+
+// merge appends the entries of other, keeping the lookup tables when both
+// indexes have them.
+func (idx *SearchIndex) merge(other *SearchIndex) {
+	offset := int32(len(idx.Skills))
+	idx.Skills = append(idx.Skills, other.Skills...)
+	idx.TotalCount += other.TotalCount
+	if idx.lookup == nil || other.lookup == nil {
+		idx.lookup = nil
+		return
+	}
+	names, tokens := make(map[string][]int32), make(map[string][]int32)
+	idx.lookup.Names.toMap(names, 0)
+	idx.lookup.Tokens.toMap(tokens, 0)
+	other.lookup.Names.toMap(names, offset)
+	other.lookup.Tokens.toMap(tokens, offset)
+	idx.lookup = &searchLookup{Names: newPostingList(names), Tokens: newPostingList(tokens)}
+}
+
+func searchIndexBinaryPath(jsonPath string) string {
+	return strings.TrimSuffix(jsonPath, filepath.Ext(jsonPath)) + ".gob"
+}
+
+// readSearchIndexBinary loads the binary cache beside jsonPath, provided it
+// was written no earlier than the JSON cache, in the current format and for
+// stamp.
+func readSearchIndexBinary(jsonPath string, jsonInfo os.FileInfo, stamp cacheStamp) (*SearchIndex, bool) {
+	path := searchIndexBinaryPath(jsonPath)
+	info, err := os.Stat(path)
+	if err != nil || info.ModTime().Before(jsonInfo.ModTime()) {
+		return nil, false
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, false
+	}
+	defer func() { _ = file.Close() }()
+
+	var cached searchIndexFile
+	if err := gob.NewDecoder(bufio.NewReader(file)).Decode(&cached); err != nil || cached.Format != searchIndexFormat || cached.Source != stamp {
+		return nil, false
+	}
+	idx := cached.Index
+	if !cached.Lookup.Names.valid(len(idx.Skills)) || !cached.Lookup.Tokens.valid(len(idx.Skills)) {
+		return nil, false
+	}
+	idx.lookup = &cached.Lookup
+	return &idx, true
+}
+
+// writeSearchIndexBinary builds the lookup tables for idx and writes the
+// binary cache beside jsonPath.
+func writeSearchIndexBinary(jsonPath string, stamp cacheStamp, idx *SearchIndex) error {
+	if idx.lookup == nil {
+		idx.lookup = buildSearchLookup(idx.Skills)
+	}
+	path := searchIndexBinaryPath(jsonPath)
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".search-index-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	w := bufio.NewWriter(tmp)
+	err = gob.NewEncoder(w).Encode(searchIndexFile{Format: searchIndexFormat, Source: stamp, Index: *idx, Lookup: *idx.lookup})
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
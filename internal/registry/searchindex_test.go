@@ -0,0 +1,194 @@
+package registry
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/majiayu000/caude-skill-manager/internal/config"
+)
+
+// syntheticSearchIndex returns an index of n entries with overlapping names,
+// descriptions and tags, sized like the public registry when n is large.
+func syntheticSearchIndex(n int) *SearchIndex {
+	words := []string{"pdf", "docx", "testing", "frontend", "react", "deploy", "lint", "design", "data", "agent"}
+	idx := &SearchIndex{Version: "v1", TotalCount: n}
+	for i := 0; i < n; i++ {
+		a, b := words[i%len(words)], words[(i/len(words))%len(words)]
+		idx.Skills = append(idx.Skills, SearchIndexEntry{
+			Name:        fmt.Sprintf("%s-%s-%d", a, b, i),
+			Description: fmt.Sprintf("Helps with %s and %s work, variant %d", a, b, i),
+			Category:    "development",
+			Tags:        []string{a, b + "-tools"},
+			Stars:       i % 500,
+			Install:     fmt.Sprintf("owner%d/repo/%s", i%97, a),
+		})
+	}
+	return idx
+}
+
+func TestIndexedSearchMatchesScan(t *testing.T) {
+	scanned := syntheticSearchIndex(500)
+	indexed := syntheticSearchIndex(500)
+	indexed.lookup = buildSearchLookup(indexed.Skills)
+
+	for _, keyword := range []string{"pdf", "PDF", "ront", "12", "docx-react", "with pdf", "tools", "missing", "-"} {
+		want := searchInIndex(scanned, keyword)
+		got := searchInIndex(indexed, keyword)
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("keyword %q: indexed search returned %d results, scan %d", keyword, len(got), len(want))
+		}
+	}
+
+	for _, name := range []string{"pdf-docx-1", "PDF-DOCX-1", "nope"} {
+		want, wantErr := lookupInIndex(scanned, name)
+		got, gotErr := lookupInIndex(indexed, name)
+		if !reflect.DeepEqual(got, want) || (gotErr == nil) != (wantErr == nil) {
+			t.Fatalf("name %q: indexed lookup %#v, %v; scan %#v, %v", name, got, gotErr, want, wantErr)
+		}
+	}
+}
+
+func TestSearchIndexCacheWritesBinaryLookup(t *testing.T) {
+	writeConfigForRegistryTest(t, "https://registry.example.com")
+	if err := saveSearchIndexCache(defaultSource(t), syntheticSearchIndex(20)); err != nil {
+		t.Fatal(err)
+	}
+	jsonPath := config.SearchIndexCachePath()
+	if _, err := os.Stat(searchIndexBinaryPath(jsonPath)); err != nil {
+		t.Fatalf("expected binary search index beside the JSON cache: %v", err)
+	}
+
+	idx, err := loadSearchIndexCache(defaultSource(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if idx.lookup == nil || len(idx.Skills) != 20 {
+		t.Fatalf("expected index loaded with lookup tables, got %d entries, lookup %v", len(idx.Skills), idx.lookup != nil)
+	}
+
+	// A missing binary cache is rebuilt from the JSON cache.
+	if err := os.Remove(searchIndexBinaryPath(jsonPath)); err != nil {
+		t.Fatal(err)
+	}
+	if idx, err := loadSearchIndexCache(defaultSource(t)); err != nil || len(idx.Skills) != 20 {
+		t.Fatalf("expected JSON fallback, got %v", err)
+	}
+	if _, err := os.Stat(searchIndexBinaryPath(jsonPath)); err != nil {
+		t.Fatalf("expected binary search index rebuilt: %v", err)
+	}
+}
+
+func TestMergedSearchIndexKeepsLookup(t *testing.T) {
+	first := &SearchIndex{Skills: []SearchIndexEntry{{Name: "pdf", Install: "a/repo/pdf"}}}
+	second := &SearchIndex{Skills: []SearchIndexEntry{{Name: "docx", Install: "b/repo/docx"}, {Name: "pdf", Install: "b/repo/pdf"}}}
+	first.lookup = buildSearchLookup(first.Skills)
+	second.lookup = buildSearchLookup(second.Skills)
+
+	first.merge(second)
+
+	if got, ok := first.nameCandidates("PDF"); !ok || !reflect.DeepEqual(got, []int32{0, 2}) {
+		t.Fatalf("expected pdf at 0 and 2 after merge, got %v", got)
+	}
+	skill, err := lookupInIndex(first, "docx")
+	if err != nil || skill.Install != "b/repo/docx" {
+		t.Fatalf("unexpected lookup after merge: %#v, %v", skill, err)
+	}
+}
+
+func benchmarkSearchIndexCache(b *testing.B, n int) string {
+	b.Helper()
+	b.Setenv("HOME", b.TempDir())
+	b.Setenv("XDG_CACHE_HOME", b.TempDir())
+	if err := saveSearchIndexCache(defaultSource(b), syntheticSearchIndex(n)); err != nil {
+		b.Fatal(err)
+	}
+	return config.SearchIndexCachePath()
+}
+
+func BenchmarkLoadSearchIndexJSON(b *testing.B) {
+	path := benchmarkSearchIndexCache(b, 30000)
+	_ = os.Remove(searchIndexBinaryPath(path))
+	data, err := os.ReadFile(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	tmp := filepath.Join(b.TempDir(), "search-index.json")
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := readCacheFile[SearchIndex](tmp, 0, defaultSource(b).cacheStamp(), nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLoadSearchIndexBinary(b *testing.B) {
+	path := benchmarkSearchIndexCache(b, 30000)
+	info, err := os.Stat(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, ok := readSearchIndexBinary(path, info, defaultSource(b).cacheStamp()); !ok {
+			b.Fatal("binary search index not loaded")
+		}
+	}
+}
+
+func BenchmarkLookupScan(b *testing.B) {
+	idx := syntheticSearchIndex(30000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := lookupInIndex(idx, "agent-agent-29999"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLookupIndexed(b *testing.B) {
+	idx := syntheticSearchIndex(30000)
+	idx.lookup = buildSearchLookup(idx.Skills)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := lookupInIndex(idx, "agent-agent-29999"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSearchScan(b *testing.B) {
+	idx := syntheticSearchIndex(30000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		searchInIndex(idx, "2999")
+	}
+}
+
+func BenchmarkSearchIndexed(b *testing.B) {
+	idx := syntheticSearchIndex(30000)
+	idx.lookup = buildSearchLookup(idx.Skills)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		searchInIndex(idx, "2999")
+	}
+}
+
+func TestEmptySearchIndexBinaryRoundTrips(t *testing.T) {
+	writeConfigForRegistryTest(t, "https://registry.example.com")
+	if err := saveSearchIndexCache(defaultSource(t), &SearchIndex{}); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(config.SearchIndexCachePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := readSearchIndexBinary(config.SearchIndexCachePath(), info, defaultSource(t).cacheStamp()); !ok {
+		t.Fatal("expected empty binary search index to load")
+	}
+}
@@ -0,0 +1,133 @@
+package registry
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SignatureSuffix is appended to a manifest path to locate its detached
+// signature: base64 of an ed25519 signature over the manifest bytes.
+const SignatureSuffix = ".sig"
+
+// ErrUnsignedPayload is returned when a public key is pinned but the registry
+// serves a full payload that no signed manifest covers.
+var ErrUnsignedPayload = errors.New("registry payload is not covered by a signed manifest")
+
+// VerificationError is a registry artifact that failed the checks of the
+// pinned key: a bad signature, a shard hash mismatch or an unsigned payload.
+// Unlike a network failure it is never answered with a stale cache.
+type VerificationError struct {
+	Err error
+}
+
+func (e *VerificationError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *VerificationError) Unwrap() error {
+	return e.Err
+}
+
+// artifactVerifier checks manifests against the pinned registry public key
+// and shards against the hashes their signed manifest lists. A nil verifier
+// accepts everything, matching registries configured without a key.
+type artifactVerifier struct {
+	key ed25519.PublicKey
+}
+
+// ParsePublicKey decodes a base64 ed25519 public key as used by
+// registry_public_key and registries[].public_key in .skrc.
+func ParsePublicKey(encoded string) (ed25519.PublicKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("invalid registry public key: %w", err)
+	}
+	if len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid registry public key: want %d bytes, got %d", ed25519.PublicKeySize, len(raw))
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// ParsePrivateKey decodes a base64 ed25519 signing key, either the 32-byte
+// seed or the 64-byte private key, as used by sk registry build --sign-key.
+func ParsePrivateKey(encoded string) (ed25519.PrivateKey, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("invalid registry signing key: %w", err)
+	}
+	switch len(raw) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(raw), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(raw), nil
+	}
+	return nil, fmt.Errorf("invalid registry signing key: want %d or %d bytes, got %d", ed25519.SeedSize, ed25519.PrivateKeySize, len(raw))
+}
+
+// requireSignedManifest fails when a key is pinned, since the full payload
+// at path was not reached through a signed manifest.
+func (v *artifactVerifier) requireSignedManifest(path string) error {
+	if v == nil {
+		return nil
+	}
+	return &VerificationError{fmt.Errorf("%w: %s", ErrUnsignedPayload, path)}
+}
+
+// checkSignature verifies the detached signature sigData over the manifest
+// bytes data.
+func (v *artifactVerifier) checkSignature(manifestPath string, data, sigData []byte) error {
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sigData)))
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return &VerificationError{fmt.Errorf("malformed manifest signature %s%s", manifestPath, SignatureSuffix)}
+	}
+	if !ed25519.Verify(v.key, data, sig) {
+		return &VerificationError{fmt.Errorf("manifest signature verification failed for %s", manifestPath)}
+	}
+	return nil
+}
+
+// checkShard checks a shard listed in a manifest against its sha256 when a
+// key is pinned. Shards without a hash in a signed manifest are rejected.
+func (v *artifactVerifier) checkShard(part artifactPart, shardPath string, data []byte) error {
+	if v == nil {
+		return nil
+	}
+	want := part.hashFor(shardPath)
+	if want == "" {
+		return &VerificationError{fmt.Errorf("signed manifest has no sha256 for %s", shardPath)}
+	}
+	if !hashMatches(want, data) {
+		return &VerificationError{fmt.Errorf("sha256 mismatch for %s: manifest lists %s, got %s", shardPath, want, sha256Hex(data))}
+	}
+	return nil
+}
+
+// hashMatches reports whether data has the listed sha256, given as hex with
+// an optional "sha256:" prefix.
+func hashMatches(want string, data []byte) bool {
+	sum := sha256.Sum256(data)
+	return strings.EqualFold(hex.EncodeToString(sum[:]), strings.TrimPrefix(want, "sha256:"))
+}
+
+// decodeJSON decodes fetched artifact bytes, gunzipping .gz paths.
+func decodeJSON(path string, data []byte, target any) error {
+	var reader io.Reader = bytes.NewReader(data)
+	if strings.HasSuffix(path, ".gz") {
+		gzReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = gzReader.Close() }()
+		reader = gzReader
+	}
+	return json.NewDecoder(reader).Decode(target)
+}
@@ -0,0 +1,137 @@
+package registry
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// pinnedVerifier returns a verifier for a fresh key and its private half.
+func pinnedVerifier(t *testing.T) (*artifactVerifier, ed25519.PrivateKey) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ParsePublicKey(base64.StdEncoding.EncodeToString(pub))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &artifactVerifier{key: key}, priv
+}
+
+func signedSearchServer(t *testing.T, priv ed25519.PrivateKey, shard string, tamper func(manifest string) string) *httptest.Server {
+	t.Helper()
+	sum := sha256.Sum256([]byte(shard))
+	manifest := `{"v":"1","total_count":1,"shards":[{"path":"search-shards/part-0001.json","sha256":"` + hex.EncodeToString(sum[:]) + `"}]}`
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, []byte(manifest)))
+	if tamper != nil {
+		manifest = tamper(manifest)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search-index.json", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"deprecated_full_payload":true,"manifest":"search-index-manifest.json"}`))
+	})
+	mux.HandleFunc("/search-index-manifest.json", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(manifest))
+	})
+	mux.HandleFunc("/search-index-manifest.json.sig", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(sig + "\n"))
+	})
+	mux.HandleFunc("/search-shards/part-0001.json", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"v":"1","count":1,"s":[{"n":"pdf","i":"owner/repo/pdf"}]}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSignedSearchManifestVerifies(t *testing.T) {
+	verifier, priv := pinnedVerifier(t)
+	shard := `{"v":"1","count":1,"s":[{"n":"pdf","i":"owner/repo/pdf"}]}`
+	server := signedSearchServer(t, priv, shard, nil)
+
+	idx, err := fetchSearchIndexFromBaseURL(server.URL, &artifactFetcher{verifier: verifier})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(idx.Skills) != 1 || idx.Skills[0].Name != "pdf" {
+		t.Fatalf("unexpected index: %#v", idx)
+	}
+}
+
+func TestSignedSearchManifestRejectsTampering(t *testing.T) {
+	verifier, priv := pinnedVerifier(t)
+
+	t.Run("manifest", func(t *testing.T) {
+		shard := `{"v":"1","count":1,"s":[{"n":"pdf","i":"owner/repo/pdf"}]}`
+		server := signedSearchServer(t, priv, shard, func(manifest string) string {
+			return strings.Replace(manifest, `"total_count":1`, `"total_count":2`, 1)
+		})
+		_, err := fetchSearchIndexFromBaseURL(server.URL, &artifactFetcher{verifier: verifier})
+		if err == nil || !strings.Contains(err.Error(), "signature verification failed") {
+			t.Fatalf("expected signature failure, got %v", err)
+		}
+	})
+
+	t.Run("shard", func(t *testing.T) {
+		// The manifest is signed over a different shard than the one served.
+		server := signedSearchServer(t, priv, `{"v":"1","count":1,"s":[{"n":"pdf","i":"evil/repo/pdf"}]}`, nil)
+		_, err := fetchSearchIndexFromBaseURL(server.URL, &artifactFetcher{verifier: verifier})
+		if err == nil || !strings.Contains(err.Error(), "sha256 mismatch") {
+			t.Fatalf("expected shard hash mismatch, got %v", err)
+		}
+	})
+}
+
+func TestPinnedKeyRejectsUnsignedFullPayload(t *testing.T) {
+	verifier, _ := pinnedVerifier(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"version":"1","skills":[{"name":"pdf","install":"owner/repo/pdf"}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	_, err := fetchRegistryFromBaseURL(server.URL, &artifactFetcher{verifier: verifier})
+	if !errors.Is(err, ErrUnsignedPayload) {
+		t.Fatalf("expected ErrUnsignedPayload, got %v", err)
+	}
+}
+
+func TestPinnedKeyRechecksShardsStoredBeforePinning(t *testing.T) {
+	verifier, priv := pinnedVerifier(t)
+	// Signed, but lists no sha256 for its shard.
+	manifest := `{"total_count":1,"shards":[{"path":"registry-shards/00.json","count":1}]}`
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(priv, []byte(manifest)))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/registry.json", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"deprecated_full_payload":true,"manifest":"registry-manifest.json"}`))
+	})
+	mux.HandleFunc("/registry-manifest.json", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(manifest))
+	})
+	mux.HandleFunc("/registry-manifest.json.sig", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(sig + "\n"))
+	})
+	mux.HandleFunc("/registry-shards/00.json", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"skills":[{"name":"pdf","install":"owner/repo/pdf"}]}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	store := &shardStore{dir: t.TempDir()}
+	if _, err := fetchRegistryFromBaseURL(server.URL, &artifactFetcher{store: store}); err != nil {
+		t.Fatal(err)
+	}
+	_, err := fetchRegistryFromBaseURL(server.URL, &artifactFetcher{store: store, verifier: verifier})
+	if err == nil || !strings.Contains(err.Error(), "no sha256") {
+		t.Fatalf("expected the stored shard to be rejected once a key is pinned, got %v", err)
+	}
+}
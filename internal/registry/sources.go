@@ -0,0 +1,166 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/majiayu000/caude-skill-manager/internal/config"
+)
+
+// Source is one configured registry.
+type Source struct {
+	Name      string
+	BaseURL   string
+	PublicKey string // base64 ed25519 key manifests must be signed with, if set
+}
+
+var registryNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Sources returns the configured registries in priority order.
+func Sources() ([]Source, error) {
+	configured, err := config.GetRegistries()
+	if err != nil {
+		return nil, err
+	}
+	sources := make([]Source, 0, len(configured))
+	seen := make(map[string]bool, len(configured))
+	for _, r := range configured {
+		if !registryNamePattern.MatchString(r.Name) {
+			return nil, fmt.Errorf("invalid registry name %q in %s: use letters, digits, '.', '_' or '-'", r.Name, config.ConfigPath())
+		}
+		if seen[strings.ToLower(r.Name)] {
+			return nil, fmt.Errorf("duplicate registry name %q in %s", r.Name, config.ConfigPath())
+		}
+		seen[strings.ToLower(r.Name)] = true
+		sources = append(sources, Source{
+			Name:      r.Name,
+			BaseURL:   strings.TrimRight(r.URL, "/"),
+			PublicKey: r.PublicKey,
+		})
+	}
+	return sources, nil
+}
+
+// SourceByName returns the configured registry with the given name.
+func SourceByName(name string) (Source, error) {
+	sources, err := Sources()
+	if err != nil {
+		return Source{}, err
+	}
+	for _, src := range sources {
+		if strings.EqualFold(src.Name, name) {
+			return src, nil
+		}
+	}
+	return Source{}, fmt.Errorf("unknown registry %q", name)
+}
+
+// primarySource returns the highest-priority registry, which serves the
+// display-only featured list and category index.
+func primarySource() (Source, error) {
+	sources, err := Sources()
+	if err != nil {
+		return Source{}, err
+	}
+	return sources[0], nil
+}
+
+func (s Source) docsBaseURL() string {
+	return s.BaseURL + "/docs"
+}
+
+func (s Source) verifier() (*artifactVerifier, error) {
+	if s.PublicKey == "" {
+		return nil, nil
+	}
+	key, err := ParsePublicKey(s.PublicKey)
+	if err != nil {
+		return nil, &VerificationError{fmt.Errorf("registry %s: %w", s.Name, err)}
+	}
+	return &artifactVerifier{key: key}, nil
+}
+
+// cacheStamp identifies what a cache file of s was fetched from: the
+// registry URL and the fingerprint of the key it was verified with. A cache
+// whose stamp differs, written for another URL or before a key was pinned,
+// is not used.
+func (s Source) cacheStamp() cacheStamp {
+	stamp := cacheStamp{URL: s.BaseURL}
+	if s.PublicKey != "" {
+		sum := sha256.Sum256([]byte(strings.TrimSpace(s.PublicKey)))
+		stamp.Key = "sha256:" + hex.EncodeToString(sum[:])
+	}
+	return stamp
+}
+
+// SplitRegistryRef splits a "registry:skill" reference. ok is false when ref
+// has no registry prefix.
+func SplitRegistryRef(ref string) (registryName, skillName string, ok bool) {
+	registryName, skillName, ok = strings.Cut(ref, ":")
+	if !ok || registryName == "" || skillName == "" || strings.Contains(registryName, "/") {
+		return "", ref, false
+	}
+	return registryName, skillName, true
+}
+
+// SourceFailure records a registry that could not be read.
+type SourceFailure struct {
+	Registry string
+	Err      error
+}
+
+// PartialError is returned alongside results when some registries failed
+// but at least one succeeded.
+type PartialError struct {
+	Failures []SourceFailure
+}
+
+func (e *PartialError) Error() string {
+	parts := make([]string, len(e.Failures))
+	for i, f := range e.Failures {
+		parts[i] = fmt.Sprintf("registry %s: %v", f.Registry, f.Err)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// eachSource calls fn for each registry in priority order. It returns the
+// combined data source, a *PartialError if only some registries failed, and
+// the underlying error if all of them did.
+func eachSource(sources []Source, fn func(Source) (RegistrySource, error)) (RegistrySource, error) {
+	var combined RegistrySource
+	var failures []SourceFailure
+	for _, src := range sources {
+		source, err := fn(src)
+		if err != nil {
+			failures = append(failures, SourceFailure{Registry: src.Name, Err: err})
+			continue
+		}
+		combined = combineRegistrySources(combined, source)
+	}
+
+	switch {
+	case len(failures) == 0:
+		return combined, nil
+	case len(sources) == 1:
+		return "", failures[0].Err
+	case len(failures) == len(sources):
+		// Not wrapped: a PartialError would tell callers results are usable.
+		return "", fmt.Errorf("all registries failed: %v", &PartialError{Failures: failures})
+	default:
+		return combined, &PartialError{Failures: failures}
+	}
+}
+
+// combineRegistrySources reports the freshest source used: remote, then
+// local, then cache.
+func combineRegistrySources(a, b RegistrySource) RegistrySource {
+	// Stale data outranks everything so its warning is always shown.
+	rank := map[RegistrySource]int{RegistrySourceCache: 1, RegistrySourceLocal: 2, RegistrySourceRemote: 3, RegistrySourceStale: 4}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,93 @@
+package registry
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/majiayu000/caude-skill-manager/internal/config"
+)
+
+func searchIndexServer(t *testing.T, body string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/docs/search-index.json" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func writeRegistriesConfig(t *testing.T, registries string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	body := []byte(`{"registry_ttl_hours":24,"registries":` + registries + `}`)
+	if err := os.WriteFile(filepath.Join(home, ".skrc"), body, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLookupMergesRegistriesInPriorityOrder(t *testing.T) {
+	internal := searchIndexServer(t, `{"v":"1","t":1,"s":[{"n":"pdf","i":"corp/skills/pdf"}]}`)
+	public := searchIndexServer(t, `{"v":"1","t":2,"s":[{"n":"pdf","i":"anthropics/skills/pdf"},{"n":"docx","i":"anthropics/skills/docx"}]}`)
+	writeRegistriesConfig(t, `[{"name":"internal","url":"`+internal.URL+`"},{"name":"public","url":"`+public.URL+`"}]`)
+
+	skill, _, err := Lookup("pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if skill.Install != "corp/skills/pdf" || skill.Registry != "internal" {
+		t.Fatalf("expected internal registry to win, got %#v", skill)
+	}
+
+	skill, _, err = Lookup("public:pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if skill.Install != "anthropics/skills/pdf" || skill.Registry != "public" {
+		t.Fatalf("expected public registry record, got %#v", skill)
+	}
+
+	if _, _, err := Lookup("internal:docx"); err == nil {
+		t.Fatal("expected docx to be missing from the internal registry")
+	}
+	if _, _, err := Lookup("unknown:pdf"); err == nil {
+		t.Fatal("expected unknown registry to be rejected")
+	}
+
+	cache := config.SearchIndexCachePathFor("internal")
+	if _, err := os.Stat(cache); err != nil {
+		t.Fatalf("expected per-registry cache at %s: %v", cache, err)
+	}
+}
+
+func TestSearchReportsPartialRegistryFailure(t *testing.T) {
+	public := searchIndexServer(t, `{"v":"1","t":1,"s":[{"n":"pdf","i":"anthropics/skills/pdf"}]}`)
+	down := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(down.Close)
+	writeRegistriesConfig(t, `[{"name":"internal","url":"`+down.URL+`"},{"name":"public","url":"`+public.URL+`"}]`)
+
+	skills, _, err := SearchWithSource("pdf")
+	var partial *PartialError
+	if !errors.As(err, &partial) || len(partial.Failures) != 1 || partial.Failures[0].Registry != "internal" {
+		t.Fatalf("expected partial failure for internal registry, got %v", err)
+	}
+	if len(skills) != 1 || skills[0].Registry != "public" {
+		t.Fatalf("unexpected results: %#v", skills)
+	}
+}
+
+func TestSourcesRejectsDuplicateNames(t *testing.T) {
+	writeRegistriesConfig(t, `[{"name":"a","url":"https://a"},{"name":"A","url":"https://b"}]`)
+	if _, err := Sources(); err == nil {
+		t.Fatal("expected duplicate registry names to be rejected")
+	}
+}
@@ -0,0 +1,437 @@
+package registry
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Validation issue levels.
+const (
+	LevelError   = "error"
+	LevelWarning = "warning"
+)
+
+// ValidationIssue is one problem found in a registry artifact.
+type ValidationIssue struct {
+	Level    string
+	Artifact string // path relative to the registry base
+	Message  string
+}
+
+// ValidationReport is the result of Validate.
+type ValidationReport struct {
+	BaseURL       string
+	Artifacts     int // artifacts fetched
+	Skills        int // records in the full registry
+	SearchEntries int
+	Categories    int
+	Issues        []ValidationIssue
+}
+
+// Errors returns the number of error-level issues.
+func (r *ValidationReport) Errors() int {
+	n := 0
+	for _, issue := range r.Issues {
+		if issue.Level == LevelError {
+			n++
+		}
+	}
+	return n
+}
+
+// Warnings returns the number of warning-level issues.
+func (r *ValidationReport) Warnings() int {
+	return len(r.Issues) - r.Errors()
+}
+
+var (
+	digestPattern = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
+	commitPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+	hashPattern   = regexp.MustCompile(`^(sha256:)?[0-9a-fA-F]{64}$`)
+)
+
+// Validate walks the registry at baseURL (an HTTP(S) URL, file:// URL or
+// directory) the way the client reads it and reports every inconsistency it
+// finds instead of stopping at the first. With key set, manifests must carry
+// valid signatures and parts must list hashes, as for a pinned registry.
+func Validate(baseURL string, key ed25519.PublicKey) *ValidationReport {
+	v := &validator{baseURL: baseURL, key: key, report: &ValidationReport{BaseURL: baseURL}}
+
+	registrySkills := v.validateRegistry()
+	entries := v.validateSearchIndex()
+	categories := v.validateCategories()
+	v.validateFeatured()
+
+	v.report.Skills = len(registrySkills)
+	v.report.SearchEntries = len(entries)
+	v.report.Categories = len(categories)
+
+	v.checkSearchCategoryCodes(entries, categories)
+	if registrySkills != nil && entries != nil && len(registrySkills) != len(entries) {
+		v.warnf("docs/search-index.json", "search index lists %d skills but the full registry lists %d", len(entries), len(registrySkills))
+	}
+	return v.report
+}
+
+type validator struct {
+	baseURL string
+	key     ed25519.PublicKey
+	report  *ValidationReport
+}
+
+func (v *validator) errorf(artifact, format string, args ...any) {
+	v.report.Issues = append(v.report.Issues, ValidationIssue{LevelError, artifact, fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) warnf(artifact, format string, args ...any) {
+	v.report.Issues = append(v.report.Issues, ValidationIssue{LevelWarning, artifact, fmt.Sprintf(format, args...)})
+}
+
+// fetch reads an artifact, reporting a failure against it.
+func (v *validator) fetch(artifact string) ([]byte, bool) {
+	v.report.Artifacts++
+	data, err := fetchBytes(artifactURL(v.baseURL, artifact))
+	if err != nil {
+		v.errorf(artifact, "cannot be fetched: %v", err)
+		return nil, false
+	}
+	return data, true
+}
+
+// decode fetches and decodes an artifact, gunzipping .gz paths.
+func (v *validator) decode(artifact string, target any) ([]byte, bool) {
+	data, ok := v.fetch(artifact)
+	if !ok {
+		return nil, false
+	}
+	if err := decodeJSON(artifact, data, target); err != nil {
+		if strings.HasSuffix(artifact, ".gz") {
+			v.errorf(artifact, "is not valid gzipped JSON: %v", err)
+		} else {
+			v.errorf(artifact, "is not valid JSON: %v", err)
+		}
+		return nil, false
+	}
+	return data, true
+}
+
+// manifest decodes a manifest and, with a key, checks its signature.
+func (v *validator) manifest(artifact string, target any) bool {
+	data, ok := v.decode(artifact, target)
+	if !ok {
+		return false
+	}
+	if v.key == nil {
+		return true
+	}
+
+	sigPath := artifact + SignatureSuffix
+	sigData, ok := v.fetch(sigPath)
+	if !ok {
+		return true
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sigData)))
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		v.errorf(sigPath, "is not a base64 ed25519 signature")
+	} else if !ed25519.Verify(v.key, data, sig) {
+		v.errorf(sigPath, "does not verify against the public key")
+	}
+	return true
+}
+
+// unsignedPayload reports a full payload a pinned key would reject.
+func (v *validator) unsignedPayload(artifact string) {
+	if v.key != nil {
+		v.errorf(artifact, "is a full payload; clients with a pinned key reject it without a signed manifest")
+	} else {
+		v.warnf(artifact, "is a deprecated full payload; publish a manifest and shards instead")
+	}
+}
+
+// checkParts fetches every variant of every part a manifest lists, checking
+// hashes, gzip and counts. It returns the decoded payload of each part,
+// preferring the gzip variant as the client does.
+func checkParts[T any](v *validator, base, manifestPath string, parts []artifactPart, count func(*T) int) []*T {
+	var payloads []*T
+	for i, part := range parts {
+		paths := registryShardPaths(part)
+		if len(paths) == 0 {
+			v.errorf(manifestPath, "part %d has neither path nor gzip_path", i)
+			continue
+		}
+
+		var chosen *T
+		for _, p := range paths {
+			artifact := path.Join(base, p)
+			want := part.hashFor(p)
+			payload := new(T)
+			data, ok := v.decode(artifact, payload)
+			if !ok {
+				continue
+			}
+			switch {
+			case want == "" && v.key != nil:
+				v.errorf(manifestPath, "lists no sha256 for %s; clients with a pinned key reject it", p)
+			case want != "" && !hashPattern.MatchString(want):
+				v.errorf(manifestPath, "lists malformed sha256 %q for %s", want, p)
+			case want != "" && !strings.EqualFold(strings.TrimPrefix(want, "sha256:"), sha256Hex(data)):
+				v.errorf(artifact, "sha256 is %s but %s lists %s", sha256Hex(data), manifestPath, want)
+			}
+			if part.Count > 0 && count(payload) != part.Count {
+				v.errorf(artifact, "holds %d skills but %s lists count %d", count(payload), manifestPath, part.Count)
+			}
+			if chosen == nil {
+				chosen = payload
+			}
+		}
+		if chosen != nil {
+			payloads = append(payloads, chosen)
+		}
+	}
+	return payloads
+}
+
+func (v *validator) validateRegistry() []Skill {
+	const pointerPath = "registry.json"
+	var pointer Registry
+	if _, ok := v.decode(pointerPath, &pointer); !ok {
+		return nil
+	}
+	if !pointer.DeprecatedFullPayload || len(pointer.Skills) > 0 {
+		v.unsignedPayload(pointerPath)
+		v.checkSkills(pointerPath, pointer.Skills)
+		return pointer.Skills
+	}
+	if pointer.Manifest == "" {
+		v.errorf(pointerPath, "is a pointer (deprecated_full_payload) but has no manifest")
+		return nil
+	}
+
+	var manifest registryManifest
+	if !v.manifest(pointer.Manifest, &manifest) {
+		return nil
+	}
+	switch manifest.SchemaVersion {
+	case "1":
+	case "":
+		v.warnf(pointer.Manifest, "has no schema_version")
+	default:
+		v.warnf(pointer.Manifest, "has schema_version %q; this client reads version 1", manifest.SchemaVersion)
+	}
+	if len(manifest.Shards) == 0 {
+		v.errorf(pointer.Manifest, "lists no shards")
+	}
+
+	shards := checkParts(v, "", pointer.Manifest, manifest.Shards, func(s *registryShard) int { return len(s.Skills) })
+	var skills []Skill
+	for i, shard := range shards {
+		if shard.Count != 0 && shard.Count != len(shard.Skills) {
+			v.errorf(pointer.Manifest, "shard %d declares count %d but holds %d skills", i, shard.Count, len(shard.Skills))
+		}
+		skills = append(skills, shard.Skills...)
+	}
+	normalizeRegistrySkills(skills)
+
+	if manifest.TotalCount != 0 && manifest.TotalCount != len(skills) {
+		v.errorf(pointer.Manifest, "total_count is %d but shards hold %d skills", manifest.TotalCount, len(skills))
+	}
+	if pointer.TotalCount != 0 && manifest.TotalCount != 0 && pointer.TotalCount != manifest.TotalCount {
+		v.warnf(pointerPath, "total_count is %d but %s lists %d", pointer.TotalCount, pointer.Manifest, manifest.TotalCount)
+	}
+	v.checkSkills(pointer.Manifest+" shards", skills)
+	return skills
+}
+
+func (v *validator) validateSearchIndex() []SearchIndexEntry {
+	const pointerPath = "docs/search-index.json"
+	var pointer searchIndexPayload
+	if _, ok := v.decode(pointerPath, &pointer); !ok {
+		return nil
+	}
+	if !pointer.DeprecatedFullPayload || pointer.Manifest == "" {
+		if pointer.DeprecatedFullPayload {
+			v.errorf(pointerPath, "is a pointer (deprecated_full_payload) but has no manifest")
+			return nil
+		}
+		v.unsignedPayload(pointerPath)
+		v.checkEntries(pointerPath, pointer.Skills)
+		return pointer.Skills
+	}
+
+	manifestPath := path.Join("docs", pointer.Manifest)
+	var manifest searchManifest
+	if !v.manifest(manifestPath, &manifest) {
+		return nil
+	}
+	if len(manifest.Shards) == 0 {
+		v.errorf(manifestPath, "lists no shards")
+	}
+
+	shards := checkParts(v, "docs", manifestPath, manifest.Shards, func(s *searchShard) int { return len(s.Skills) })
+	var entries []SearchIndexEntry
+	for i, shard := range shards {
+		if shard.Count != 0 && shard.Count != len(shard.Skills) {
+			v.errorf(manifestPath, "shard %d declares count %d but holds %d entries", i, shard.Count, len(shard.Skills))
+		}
+		entries = append(entries, shard.Skills...)
+	}
+	if manifest.TotalCount != 0 && manifest.TotalCount != len(entries) {
+		v.errorf(manifestPath, "total_count is %d but shards hold %d entries", manifest.TotalCount, len(entries))
+	}
+	v.checkEntries(manifestPath+" shards", entries)
+	return entries
+}
+
+func (v *validator) validateCategories() map[string]bool {
+	const indexPath = "docs/categories/index.json"
+	var index CategoryIndex
+	if _, ok := v.decode(indexPath, &index); !ok {
+		return nil
+	}
+
+	names := make(map[string]bool, len(index.Categories))
+	for _, entry := range index.Categories {
+		if entry.Name == "" {
+			v.errorf(indexPath, "lists a category with no name")
+			continue
+		}
+		names[entry.Name] = true
+		if full, ok := categoryCodeToName[entry.Code]; ok && full != entry.Name {
+			v.errorf(indexPath, "category %q uses code %q, which the client maps to %q", entry.Name, entry.Code, full)
+		}
+
+		count, ok := v.validateCategory(entry.Name)
+		if ok && entry.Count != 0 && entry.Count != count {
+			v.errorf(indexPath, "lists %d skills for %q but the category holds %d", entry.Count, entry.Name, count)
+		}
+	}
+	return names
+}
+
+func (v *validator) validateCategory(name string) (int, bool) {
+	pointerPath := "docs/categories/" + name + ".json"
+	var pointer Category
+	if _, ok := v.decode(pointerPath, &pointer); !ok {
+		return 0, false
+	}
+	if !pointer.DeprecatedFullPayload || len(pointer.Skills) > 0 {
+		v.unsignedPayload(pointerPath)
+		v.checkSkills(pointerPath, pointer.Skills)
+		return len(pointer.Skills), true
+	}
+	if pointer.Manifest == "" {
+		v.errorf(pointerPath, "is a pointer (deprecated_full_payload) but has no manifest")
+		return 0, false
+	}
+
+	manifestPath := path.Join("docs", pointer.Manifest)
+	var manifest categoryManifest
+	if !v.manifest(manifestPath, &manifest) {
+		return 0, false
+	}
+	parts := checkParts(v, "docs", manifestPath, manifest.Parts, func(c *Category) int { return len(c.Skills) })
+	var skills []Skill
+	for _, part := range parts {
+		skills = append(skills, part.Skills...)
+	}
+	normalizeRegistrySkills(skills)
+	if manifest.Count != 0 && manifest.Count != len(skills) {
+		v.errorf(manifestPath, "count is %d but parts hold %d skills", manifest.Count, len(skills))
+	}
+	v.checkSkills(manifestPath+" parts", skills)
+	return len(skills), true
+}
+
+func (v *validator) validateFeatured() {
+	const featuredPath = "docs/featured.json"
+	v.report.Artifacts++
+	data, err := fetchBytes(artifactURL(v.baseURL, featuredPath))
+	if err != nil {
+		v.warnf(featuredPath, "cannot be fetched (%v); sk search shows its built-in list", err)
+		return
+	}
+	var featured Featured
+	if err := decodeJSON(featuredPath, data, &featured); err != nil {
+		v.errorf(featuredPath, "is not valid JSON: %v", err)
+		return
+	}
+	normalizeRegistrySkills(featured.Skills)
+	v.checkSkills(featuredPath, featured.Skills)
+}
+
+// checkSkills reports records that cannot be installed or carry malformed
+// integrity fields, and names listed more than once.
+func (v *validator) checkSkills(artifact string, skills []Skill) {
+	byName := make(map[string]string, len(skills))
+	byInstall := make(map[string]bool, len(skills))
+	for i, s := range skills {
+		label := s.Name
+		if label == "" {
+			label = fmt.Sprintf("#%d", i)
+			v.errorf(artifact, "skill %s has no name", label)
+		}
+
+		switch {
+		case s.Install == "" && s.Repo == "":
+			v.errorf(artifact, "skill %s has neither install nor repo", label)
+		case !isInstallableSkillRef(s.Install):
+			v.errorf(artifact, "skill %s install ref %q points at a markdown file other than SKILL.md; clients skip it", label, s.Install)
+		}
+		if s.Digest != "" && !digestPattern.MatchString(s.Digest) {
+			v.errorf(artifact, "skill %s digest %q is not sha256:<64 hex>", label, s.Digest)
+		}
+		if s.Commit != "" && !commitPattern.MatchString(s.Commit) {
+			v.errorf(artifact, "skill %s commit %q is not a 40-character SHA", label, s.Commit)
+		}
+
+		if s.Install != "" {
+			if byInstall[s.Install] {
+				v.warnf(artifact, "install ref %s is listed more than once", s.Install)
+			}
+			byInstall[s.Install] = true
+		}
+		if s.Name != "" {
+			if first, ok := byName[s.Name]; ok && first != s.Install {
+				v.warnf(artifact, "name %s is used by %s and %s; sk install %s resolves to the first", s.Name, first, s.Install, s.Name)
+			} else if !ok {
+				byName[s.Name] = s.Install
+			}
+		}
+	}
+}
+
+func (v *validator) checkEntries(artifact string, entries []SearchIndexEntry) {
+	skills := make([]Skill, len(entries))
+	for i, e := range entries {
+		skills[i] = entryToSkill(e)
+	}
+	v.checkSkills(artifact, skills)
+}
+
+// checkSearchCategoryCodes reports search entries whose category code is
+// neither a known short code nor a published category name.
+func (v *validator) checkSearchCategoryCodes(entries []SearchIndexEntry, categories map[string]bool) {
+	unknown := make(map[string]int)
+	for _, e := range entries {
+		if e.Category == "" {
+			continue
+		}
+		if _, ok := categoryCodeToName[e.Category]; ok || categories[e.Category] {
+			continue
+		}
+		unknown[e.Category]++
+	}
+	codes := make([]string, 0, len(unknown))
+	for code := range unknown {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		v.warnf("docs/search-index.json", "%d entries use category code %q, which matches no known code or published category", unknown[code], code)
+	}
+}
@@ -0,0 +1,105 @@
+package registry
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func buildTestRegistry(t *testing.T, key ed25519.PrivateKey) string {
+	t.Helper()
+	skills, err := ScanSkills(BuildSource{Repo: "corp/skills", Dir: writeSkillTree(t)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := t.TempDir()
+	if _, err := Build(skills, BuildOptions{OutDir: out, ShardSize: 1, Gzip: true, SigningKey: key}); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestValidateBuiltRegistryIsClean(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := Validate(buildTestRegistry(t, priv), pub)
+	if len(report.Issues) != 0 {
+		t.Fatalf("unexpected issues: %#v", report.Issues)
+	}
+	if report.Skills != 2 || report.SearchEntries != 2 || report.Categories != 2 {
+		t.Fatalf("unexpected counts: %#v", report)
+	}
+}
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := buildTestRegistry(t, priv)
+
+	// Corrupt a gzip shard, drop a category part and break a signature.
+	if err := os.WriteFile(filepath.Join(root, "registry-shards", "part-001.json.gz"), []byte("not gzip"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(root, "docs", "categories", "other", "part-000.json")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "docs", "search-index-manifest.json.sig"), []byte("AAAA"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report := Validate(root, pub)
+	want := []string{
+		"registry-shards/part-001.json.gz: is not valid gzipped JSON",
+		"docs/categories/other/part-000.json: cannot be fetched",
+		"docs/search-index-manifest.json.sig: is not a base64 ed25519 signature",
+	}
+	var got []string
+	for _, issue := range report.Issues {
+		got = append(got, issue.Artifact+": "+issue.Message)
+	}
+	joined := strings.Join(got, "\n")
+	for _, w := range want {
+		if !strings.Contains(joined, w) {
+			t.Fatalf("missing %q in:\n%s", w, joined)
+		}
+	}
+	if report.Errors() < len(want) {
+		t.Fatalf("expected at least %d errors, got %d", len(want), report.Errors())
+	}
+}
+
+func TestValidateChecksRecords(t *testing.T) {
+	root := writeLocalRegistry(t)
+	shard := `{"count":3,"skills":[` +
+		`{"name":"pdf","repo":"corp/skills","path":"pdf","digest":"sha256:nothex"},` +
+		`{"name":"pdf","install":"other/repo/pdf"},` +
+		`{"name":"notes","install":"corp/skills/docs/notes.md"}]}`
+	if err := os.WriteFile(filepath.Join(root, "registry-shards", "part-000.json"), []byte(shard), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report := Validate(root, nil)
+	var messages []string
+	for _, issue := range report.Issues {
+		messages = append(messages, issue.Level+" "+issue.Artifact+": "+issue.Message)
+	}
+	joined := strings.Join(messages, "\n")
+	for _, w := range []string{
+		"error registry-manifest.json: total_count is 1 but shards hold 3 skills",
+		`skill pdf digest "sha256:nothex" is not sha256:<64 hex>`,
+		"warning registry-manifest.json shards: name pdf is used by corp/skills/pdf and other/repo/pdf",
+		`skill notes install ref "corp/skills/docs/notes.md" points at a markdown file`,
+		"error docs/categories/index.json: cannot be fetched",
+	} {
+		if !strings.Contains(joined, w) {
+			t.Fatalf("missing %q in:\n%s", w, joined)
+		}
+	}
+}
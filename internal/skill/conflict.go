@@ -0,0 +1,116 @@
+package skill
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// AmbiguousNameError is returned by Get when a name is no skill's directory
+// but the front-matter name of several.
+type AmbiguousNameError struct {
+	Name   string
+	Skills []Skill
+}
+
+func (e *AmbiguousNameError) Error() string {
+	dirs := make([]string, len(e.Skills))
+	for i, s := range e.Skills {
+		dirs[i] = filepath.Base(s.Path)
+	}
+	return fmt.Sprintf("%d skills are named '%s' (%s); use the directory name instead",
+		len(e.Skills), e.Name, strings.Join(dirs, ", "))
+}
+
+// Kinds of Conflict.
+const (
+	// ConflictDirectory means the skill would install into the directory of
+	// an installed one.
+	ConflictDirectory = "directory"
+	// ConflictName means the skill would share a name with an installed one,
+	// so neither Claude Code nor sk could tell them apart by name.
+	ConflictName = "name"
+)
+
+// Conflict is an installed skill that clashes with a skill about to be
+// installed.
+type Conflict struct {
+	Skill Skill
+	Kind  string // ConflictDirectory or ConflictName
+}
+
+// Describe says what clashes, for messages.
+func (c Conflict) Describe() string {
+	where := filepath.Base(c.Skill.Path)
+	if c.Skill.Scope == ScopeProject {
+		where = "project skill " + where
+	}
+	if c.Kind == ConflictDirectory {
+		return fmt.Sprintf("%s is already installed (%s)", where, c.Skill.Path)
+	}
+	return fmt.Sprintf("%s is also named '%s' (%s)", where, c.Skill.Name, c.Skill.Path)
+}
+
+// FindConflicts returns the installed skills, in every scope, that clash with
+// a skill about to be installed into directory dir with front-matter name
+// name ("" when SKILL.md sets none). A skill clashes when either of its names
+// matches either of the new skill's. The skill at exclude, the path of an
+// install being replaced, is left out.
+func FindConflicts(dir, name, exclude string) ([]Conflict, error) {
+	skills, err := ListAll()
+	if err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = dir
+	}
+
+	var conflicts []Conflict
+	for _, s := range skills {
+		if exclude != "" && filepath.Clean(s.Path) == filepath.Clean(exclude) {
+			continue
+		}
+		base := filepath.Base(s.Path)
+		switch {
+		case s.Scope == ScopeUser && base == dir:
+			conflicts = append(conflicts, Conflict{Skill: s, Kind: ConflictDirectory})
+		case sameName(s.Name, name) || sameName(s.Name, dir) || sameName(base, name) || sameName(base, dir):
+			conflicts = append(conflicts, Conflict{Skill: s, Kind: ConflictName})
+		}
+	}
+	return conflicts, nil
+}
+
+// DuplicateNames returns the groups of installed skills, across every scope,
+// that share a front-matter name, sorted by name. Claude Code cannot tell the
+// skills of a group apart.
+func DuplicateNames() ([][]Skill, error) {
+	skills, err := ListAll()
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string][]Skill)
+	for _, s := range skills {
+		key := strings.ToLower(s.Name)
+		groups[key] = append(groups[key], s)
+	}
+	var names []string
+	for key, group := range groups {
+		if len(group) > 1 {
+			names = append(names, key)
+		}
+	}
+	sort.Strings(names)
+
+	duplicates := make([][]Skill, len(names))
+	for i, key := range names {
+		duplicates[i] = groups[key]
+	}
+	return duplicates, nil
+}
+
+func sameName(a, b string) bool {
+	return strings.EqualFold(a, b)
+}
@@ -0,0 +1,101 @@
+package skill
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeSkill creates a skill directory under root with the given
+// front-matter name.
+func writeSkill(t *testing.T, root, dir, name string) {
+	t.Helper()
+	path := filepath.Join(root, dir)
+	if err := os.MkdirAll(path, 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := "---\nname: " + name + "\ndescription: test\n---\n"
+	if err := os.WriteFile(filepath.Join(path, "SKILL.md"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// setupScopes points the user scope and the project scope at empty
+// directories and returns them.
+func setupScopes(t *testing.T) (user, project string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	work := t.TempDir()
+	t.Chdir(work)
+	return filepath.Join(home, ".claude", "skills"), filepath.Join(work, ".claude", "skills")
+}
+
+func TestGetPrefersDirectoryOverFrontMatterName(t *testing.T) {
+	user, _ := setupScopes(t)
+	writeSkill(t, user, "pdf", "pdf")
+	writeSkill(t, user, "pdf-tools", "pdf")
+
+	s, err := Get("pdf")
+	if err != nil || s == nil || filepath.Base(s.Path) != "pdf" {
+		t.Fatalf("expected the pdf directory, got %v, %v", s, err)
+	}
+}
+
+func TestGetReportsAmbiguousFrontMatterName(t *testing.T) {
+	user, _ := setupScopes(t)
+	writeSkill(t, user, "pdf-anthropics", "pdf")
+	writeSkill(t, user, "pdf-other", "pdf")
+
+	_, err := Get("pdf")
+	var ambiguous *AmbiguousNameError
+	if !errors.As(err, &ambiguous) || len(ambiguous.Skills) != 2 {
+		t.Fatalf("expected an ambiguous name error, got %v", err)
+	}
+	if !Exists("pdf") {
+		t.Fatal("expected an ambiguous name to exist")
+	}
+}
+
+func TestFindConflictsAcrossScopes(t *testing.T) {
+	user, project := setupScopes(t)
+	writeSkill(t, user, "pdf", "pdf")
+	writeSkill(t, user, "docs", "document-pdf")
+	writeSkill(t, project, "reader", "PDF")
+	writeSkill(t, user, "xlsx", "xlsx")
+
+	conflicts, err := FindConflicts("pdf", "pdf", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) != 2 {
+		t.Fatalf("expected 2 conflicts, got %+v", conflicts)
+	}
+	if conflicts[0].Kind != ConflictDirectory || filepath.Base(conflicts[0].Skill.Path) != "pdf" {
+		t.Fatalf("expected the pdf directory first, got %+v", conflicts[0])
+	}
+	if conflicts[1].Kind != ConflictName || conflicts[1].Skill.Scope != ScopeProject {
+		t.Fatalf("expected the project skill named PDF, got %+v", conflicts[1])
+	}
+
+	conflicts, err = FindConflicts("pdf", "pdf", filepath.Join(user, "pdf"))
+	if err != nil || len(conflicts) != 1 {
+		t.Fatalf("expected the excluded install to be left out, got %+v, %v", conflicts, err)
+	}
+}
+
+func TestDuplicateNames(t *testing.T) {
+	user, project := setupScopes(t)
+	writeSkill(t, user, "pdf", "pdf")
+	writeSkill(t, project, "pdf", "pdf")
+	writeSkill(t, user, "xlsx", "xlsx")
+
+	duplicates, err := DuplicateNames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(duplicates) != 1 || len(duplicates[0]) != 2 || duplicates[0][0].Name != "pdf" {
+		t.Fatalf("expected one group of two pdf skills, got %+v", duplicates)
+	}
+}
@@ -0,0 +1,147 @@
+package skill
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FrontMatterField is one top-level key from SKILL.md front matter.
+// List values are joined with ", ".
+type FrontMatterField struct {
+	Key   string
+	Value string
+}
+
+// Document is a parsed SKILL.md file.
+type Document struct {
+	Meta        SkillMeta
+	FrontMatter []FrontMatterField
+	Body        string
+}
+
+// Field returns the front matter value for key, if present.
+func (d *Document) Field(key string) (string, bool) {
+	for _, f := range d.FrontMatter {
+		if f.Key == key {
+			return f.Value, true
+		}
+	}
+	return "", false
+}
+
+// ParseDocument splits SKILL.md content into front matter and body.
+// Only the simple YAML subset used by skills is understood: top-level
+// "key: value" pairs and "- item" lists under a key.
+func ParseDocument(content []byte) *Document {
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	doc := &Document{Body: text}
+
+	lines := strings.Split(text, "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return doc
+	}
+
+	end := -1
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "---" {
+			end = i
+			break
+		}
+	}
+	if end == -1 {
+		return doc
+	}
+
+	for _, line := range lines[1:end] {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		indented := line != strings.TrimLeft(line, " \t")
+		if indented {
+			if len(doc.FrontMatter) == 0 {
+				continue
+			}
+			last := &doc.FrontMatter[len(doc.FrontMatter)-1]
+			item := trimmed
+			if strings.HasPrefix(item, "- ") || item == "-" {
+				item = unquote(strings.TrimSpace(strings.TrimPrefix(item, "-")))
+				if last.Value == "" {
+					last.Value = item
+				} else {
+					last.Value += ", " + item
+				}
+			} else {
+				// Continuation of a folded or multi-line scalar.
+				if last.Value == "" || last.Value == ">" || last.Value == "|" {
+					last.Value = trimmed
+				} else {
+					last.Value += " " + trimmed
+				}
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = unquote(strings.TrimSpace(value))
+		if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+			items := strings.Split(strings.Trim(value, "[]"), ",")
+			for i := range items {
+				items[i] = unquote(strings.TrimSpace(items[i]))
+			}
+			value = strings.Join(items, ", ")
+		}
+		doc.FrontMatter = append(doc.FrontMatter, FrontMatterField{Key: key, Value: value})
+	}
+
+	doc.Body = strings.TrimLeft(strings.Join(lines[end+1:], "\n"), "\n")
+	doc.Meta.Name, _ = doc.Field("name")
+	doc.Meta.Description, _ = doc.Field("description")
+	return doc
+}
+
+func unquote(value string) string {
+	return strings.Trim(value, "\"'")
+}
+
+// SetName rewrites the front-matter name of the SKILL.md in dir, adding
+// front matter when the file has none. The rest of the file is kept as is.
+func SetName(dir, name string) error {
+	path := filepath.Join(dir, "SKILL.md")
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(setFrontMatterName(string(content), name)), 0644)
+}
+
+func setFrontMatterName(content, name string) string {
+	field := "name: " + name
+	lines := strings.SplitAfter(content, "\n")
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return "---\n" + field + "\n---\n\n" + content
+	}
+
+	newline := "\n"
+	if strings.HasSuffix(lines[0], "\r\n") {
+		newline = "\r\n"
+	}
+	for i := 1; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "---" {
+			break
+		}
+		if strings.HasPrefix(line, "name:") {
+			lines[i] = field + newline
+			return strings.Join(lines, "")
+		}
+	}
+	// No name key: add one at the top of the front matter.
+	return lines[0] + field + newline + strings.Join(lines[1:], "")
+}
@@ -0,0 +1,52 @@
+package skill
+
+import "testing"
+
+func TestParseDocumentReadsFrontMatterAndBody(t *testing.T) {
+	content := "---\nname: \"pdf\"\ndescription: PDF tools\nallowed-tools: [Read, \"Bash(python:*)\"]\ntags:\n  - docs\n  - pdf\n---\n\n# PDF\n\nUse this skill.\n"
+
+	doc := ParseDocument([]byte(content))
+
+	if doc.Meta.Name != "pdf" || doc.Meta.Description != "PDF tools" {
+		t.Fatalf("unexpected meta: %#v", doc.Meta)
+	}
+	if got, _ := doc.Field("allowed-tools"); got != "Read, Bash(python:*)" {
+		t.Fatalf("unexpected allowed-tools: %q", got)
+	}
+	if got, _ := doc.Field("tags"); got != "docs, pdf" {
+		t.Fatalf("unexpected tags: %q", got)
+	}
+	if doc.Body != "# PDF\n\nUse this skill.\n" {
+		t.Fatalf("unexpected body: %q", doc.Body)
+	}
+}
+
+func TestParseDocumentWithoutFrontMatter(t *testing.T) {
+	doc := ParseDocument([]byte("# Title\n---\nnot front matter\n"))
+
+	if len(doc.FrontMatter) != 0 || doc.Meta.Name != "" {
+		t.Fatalf("expected no front matter, got %#v", doc.FrontMatter)
+	}
+	if doc.Body != "# Title\n---\nnot front matter\n" {
+		t.Fatalf("unexpected body: %q", doc.Body)
+	}
+}
+
+func TestSetFrontMatterName(t *testing.T) {
+	tests := []struct {
+		content, want string
+	}{
+		{"---\nname: pdf\ndescription: PDF tools\n---\n# PDF\n", "---\nname: pdf-acme\ndescription: PDF tools\n---\n# PDF\n"},
+		{"---\r\ndescription: PDF tools\r\n---\r\n", "---\r\nname: pdf-acme\r\ndescription: PDF tools\r\n---\r\n"},
+		{"# PDF\n", "---\nname: pdf-acme\n---\n\n# PDF\n"},
+	}
+	for _, tt := range tests {
+		got := setFrontMatterName(tt.content, "pdf-acme")
+		if got != tt.want {
+			t.Errorf("setFrontMatterName(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+		if name := ParseDocument([]byte(got)).Meta.Name; name != "pdf-acme" {
+			t.Errorf("parsed name %q from %q", name, got)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package skill
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// MetaFile is the install record sk writes into each skill directory.
+// It is excluded from content digests.
+const MetaFile = ".sk-meta.json"
+
+// InstallMeta records where a skill came from and what was installed.
+type InstallMeta struct {
+	Source         string    `json:"source"`                    // argument passed to sk install
+	URL            string    `json:"url"`                       // resolved GitHub URL
+	Registry       string    `json:"registry,omitempty"`        // configured registry name for name installs
+	Commit         string    `json:"commit,omitempty"`          // commit pinned by the registry
+	Digest         string    `json:"digest"`                    // digest of the tree as installed
+	RegistryDigest string    `json:"registry_digest,omitempty"` // digest the registry advertised
+	InstalledAt    time.Time `json:"installed_at"`
+}
+
+// WriteInstallMeta writes the install record into a skill directory.
+func WriteInstallMeta(dir string, meta *InstallMeta) error {
+	data, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, MetaFile), append(data, '\n'), 0644)
+}
+
+// ReadInstallMeta reads the install record from a skill directory. Skills
+// installed before sk wrote records return os.ErrNotExist.
+func ReadInstallMeta(dir string) (*InstallMeta, error) {
+	data, err := os.ReadFile(filepath.Join(dir, MetaFile))
+	if err != nil {
+		return nil, err
+	}
+	var meta InstallMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil, err
+	}
+	return &meta, nil
+}
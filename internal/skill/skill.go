@@ -1,6 +1,8 @@
 package skill
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -17,8 +19,15 @@ type Skill struct {
 	Source      string    `json:"source"` // github url or local
 	Version     string    `json:"version"`
 	InstalledAt time.Time `json:"installed_at"`
+	Scope       string    `json:"scope"` // ScopeUser or ScopeProject
 }
 
+// Scopes Claude Code loads skills from.
+const (
+	ScopeUser    = "user"    // the skills directory sk installs into
+	ScopeProject = "project" // .claude/skills in the current directory
+)
+
 // SkillMeta represents metadata from SKILL.md front matter
 type SkillMeta struct {
 	Name        string `yaml:"name"`
@@ -27,8 +36,27 @@ type SkillMeta struct {
 
 // List returns all installed skills
 func List() ([]Skill, error) {
-	skillsDir := config.GetSkillsDir()
+	return listDir(config.GetSkillsDir(), ScopeUser)
+}
 
+// ListAll returns the skills of every scope: those List returns, then the
+// project skills of the current directory.
+func ListAll() ([]Skill, error) {
+	skills, err := List()
+	if err != nil {
+		return nil, err
+	}
+	if dir := config.ProjectSkillsDir(); dir != "" {
+		project, err := listDir(dir, ScopeProject)
+		if err != nil {
+			return nil, err
+		}
+		skills = append(skills, project...)
+	}
+	return skills, nil
+}
+
+func listDir(skillsDir, scope string) ([]Skill, error) {
 	entries, err := os.ReadDir(skillsDir)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -39,7 +67,8 @@ func List() ([]Skill, error) {
 
 	var skills []Skill
 	for _, entry := range entries {
-		if !entry.IsDir() {
+		// Hidden directories hold in-progress installs, not skills.
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
 			continue
 		}
 
@@ -52,8 +81,9 @@ func List() ([]Skill, error) {
 		}
 
 		skill := Skill{
-			Name: entry.Name(),
-			Path: skillPath,
+			Name:  entry.Name(),
+			Path:  skillPath,
+			Scope: scope,
 		}
 
 		// Parse SKILL.md for metadata
@@ -64,8 +94,11 @@ func List() ([]Skill, error) {
 			skill.Description = meta.Description
 		}
 
-		// Get modification time as install time approximation
-		if info, err := entry.Info(); err == nil {
+		if record, err := ReadInstallMeta(skillPath); err == nil {
+			skill.Source = record.URL
+			skill.InstalledAt = record.InstalledAt
+		} else if info, err := entry.Info(); err == nil {
+			// Get modification time as install time approximation
 			skill.InstalledAt = info.ModTime()
 		}
 
@@ -75,26 +108,39 @@ func List() ([]Skill, error) {
 	return skills, nil
 }
 
-// Get returns a specific skill by name
+// Get returns the installed skill in directory name or, failing that, the
+// one whose front-matter name is name. It returns nil when there is none,
+// and an *AmbiguousNameError when several skills share the front-matter
+// name.
 func Get(name string) (*Skill, error) {
 	skills, err := List()
 	if err != nil {
 		return nil, err
 	}
 
+	var named []Skill
 	for _, s := range skills {
-		if s.Name == name || filepath.Base(s.Path) == name {
+		if filepath.Base(s.Path) == name {
 			return &s, nil
 		}
+		if s.Name == name {
+			named = append(named, s)
+		}
 	}
-
-	return nil, nil
+	switch len(named) {
+	case 0:
+		return nil, nil
+	case 1:
+		return &named[0], nil
+	}
+	return nil, &AmbiguousNameError{Name: name, Skills: named}
 }
 
 // Exists checks if a skill is installed
 func Exists(name string) bool {
-	skill, _ := Get(name)
-	return skill != nil
+	skill, err := Get(name)
+	var ambiguous *AmbiguousNameError
+	return skill != nil || errors.As(err, &ambiguous)
 }
 
 // Remove uninstalls a skill
@@ -122,32 +168,79 @@ func parseSkillMd(path string) (*SkillMeta, error) {
 		return nil, err
 	}
 
-	meta := &SkillMeta{}
-	text := string(content)
-
-	// Simple front matter parsing (between ---)
-	if strings.HasPrefix(text, "---") {
-		parts := strings.SplitN(text, "---", 3)
-		if len(parts) >= 3 {
-			frontMatter := parts[1]
-			lines := strings.Split(frontMatter, "\n")
-			for _, line := range lines {
-				line = strings.TrimSpace(line)
-				if strings.HasPrefix(line, "name:") {
-					meta.Name = strings.TrimSpace(strings.TrimPrefix(line, "name:"))
-					meta.Name = strings.Trim(meta.Name, "\"'")
-				} else if strings.HasPrefix(line, "description:") {
-					meta.Description = strings.TrimSpace(strings.TrimPrefix(line, "description:"))
-					meta.Description = strings.Trim(meta.Description, "\"'")
-				}
-			}
-		}
-	}
-
-	return meta, nil
+	doc := ParseDocument(content)
+	return &doc.Meta, nil
 }
 
 // GetSkillDir returns the full path for a skill
 func GetSkillDir(name string) string {
 	return filepath.Join(config.GetSkillsDir(), name)
 }
+
+// NewStagingDir creates a hidden directory inside the skills directory where
+// a skill can be extracted and checked before it is moved into place.
+func NewStagingDir() (string, error) {
+	if err := config.EnsureSkillsDir(); err != nil {
+		return "", err
+	}
+	dir, err := os.MkdirTemp(config.GetSkillsDir(), ".sk-staging-")
+	if err != nil {
+		return "", err
+	}
+	// MkdirTemp creates 0700 directories; skills are normally world-readable.
+	if err := os.Chmod(dir, 0755); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	return dir, nil
+}
+
+// CommitStaged moves a staged skill directory into place under name.
+func CommitStaged(stagingDir, name string) error {
+	target := GetSkillDir(name)
+	if _, err := os.Stat(target); err == nil {
+		return fmt.Errorf("skill directory already exists: %s", target)
+	}
+	return os.Rename(stagingDir, target)
+}
+
+// CommitReplacing moves a staged skill into place under name, replacing the
+// skill directories in replace. They are first renamed to hidden backups in
+// the skills directory and only deleted once the staged skill is in place; if
+// that fails they are restored, so a failed install never loses a skill.
+func CommitReplacing(stagingDir, name string, replace []string) error {
+	type backup struct{ path, backup string }
+	var backups []backup
+	restore := func() {
+		for i := len(backups) - 1; i >= 0; i-- {
+			_ = os.Rename(backups[i].backup, backups[i].path)
+		}
+	}
+
+	for _, path := range replace {
+		dir, err := os.MkdirTemp(config.GetSkillsDir(), ".sk-backup-")
+		if err != nil {
+			restore()
+			return err
+		}
+		// Rename cannot replace a directory everywhere; free the name first.
+		if err := os.Remove(dir); err != nil {
+			restore()
+			return err
+		}
+		if err := os.Rename(path, dir); err != nil {
+			restore()
+			return fmt.Errorf("failed to move %s aside: %w", path, err)
+		}
+		backups = append(backups, backup{path, dir})
+	}
+
+	if err := CommitStaged(stagingDir, name); err != nil {
+		restore()
+		return err
+	}
+	for _, b := range backups {
+		_ = os.RemoveAll(b.backup)
+	}
+	return nil
+}
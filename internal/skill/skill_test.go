@@ -0,0 +1,70 @@
+package skill
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func stageSkill(t *testing.T, name string) string {
+	t.Helper()
+	dir, err := NewStagingDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte("---\nname: "+name+"\n---\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// hiddenEntries returns the names of hidden entries left in dir.
+func hiddenEntries(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hidden []string
+	for _, e := range entries {
+		if e.Name()[0] == '.' {
+			hidden = append(hidden, e.Name())
+		}
+	}
+	return hidden
+}
+
+func TestCommitReplacingReplacesAndCleansUp(t *testing.T) {
+	user, _ := setupScopes(t)
+	writeSkill(t, user, "pdf", "pdf")
+	staging := stageSkill(t, "pdf")
+
+	if err := CommitReplacing(staging, "pdf", []string{filepath.Join(user, "pdf")}); err != nil {
+		t.Fatal(err)
+	}
+	content, err := os.ReadFile(filepath.Join(user, "pdf", "SKILL.md"))
+	if err != nil || string(content) != "---\nname: pdf\n---\n" {
+		t.Fatalf("expected the staged skill in place, got %q, %v", content, err)
+	}
+	if hidden := hiddenEntries(t, user); len(hidden) != 0 {
+		t.Fatalf("expected no backups left, got %v", hidden)
+	}
+}
+
+func TestCommitReplacingRestoresOnFailure(t *testing.T) {
+	user, _ := setupScopes(t)
+	writeSkill(t, user, "pdf-tools", "pdf")
+	writeSkill(t, user, "pdf", "other")
+	staging := stageSkill(t, "pdf")
+
+	// pdf is not being replaced, so the commit fails.
+	if err := CommitReplacing(staging, "pdf", []string{filepath.Join(user, "pdf-tools")}); err == nil {
+		t.Fatal("expected the commit to fail")
+	}
+	if _, err := os.Stat(filepath.Join(user, "pdf-tools", "SKILL.md")); err != nil {
+		t.Fatalf("expected pdf-tools to be restored: %v", err)
+	}
+	if hidden := hiddenEntries(t, user); len(hidden) != 1 || filepath.Join(user, hidden[0]) != staging {
+		t.Fatalf("expected only the staging directory left, got %v", hidden)
+	}
+}
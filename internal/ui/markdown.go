@@ -0,0 +1,165 @@
+package ui
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/majiayu000/caude-skill-manager/pkg/styles"
+)
+
+var (
+	mdHeading1Style = lipgloss.NewStyle().Bold(true).Foreground(styles.Primary)
+	mdHeading2Style = lipgloss.NewStyle().Bold(true).Foreground(styles.Secondary)
+	mdHeading3Style = lipgloss.NewStyle().Bold(true).Foreground(styles.Text)
+	mdCodeStyle     = lipgloss.NewStyle().Foreground(styles.Secondary)
+	mdQuoteStyle    = lipgloss.NewStyle().Foreground(styles.TextDim).Italic(true)
+	mdBoldStyle     = lipgloss.NewStyle().Bold(true)
+	mdItalicStyle   = lipgloss.NewStyle().Italic(true)
+
+	mdInlineCode = regexp.MustCompile("`([^`]+)`")
+	mdBold       = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	mdItalic     = regexp.MustCompile(`(^|[^*\w])\*([^*\s][^*]*)\*|(^|[^_\w])_([^_\s][^_]*)_`)
+	mdLink       = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	mdOrdered    = regexp.MustCompile(`^(\d+)[.)]\s+(.*)$`)
+)
+
+// RenderMarkdown renders markdown as styled terminal text using the sk
+// palette. Lines are wrapped to width when width is positive.
+func RenderMarkdown(src string, width int) string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	lines := strings.Split(strings.TrimRight(src, "\n"), "\n")
+
+	var b strings.Builder
+	inCode := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inCode = !inCode
+			if inCode {
+				if lang := strings.Trim(trimmed, "`~ "); lang != "" {
+					b.WriteString("  " + styles.MutedStyle.Render(lang) + "\n")
+				}
+			}
+			continue
+		}
+		if inCode {
+			b.WriteString("  " + mdCodeStyle.Render("│ "+line) + "\n")
+			continue
+		}
+
+		switch {
+		case trimmed == "":
+			b.WriteString("\n")
+		case strings.HasPrefix(trimmed, "# "):
+			b.WriteString(mdHeading1Style.Render(strings.ToUpper(strings.TrimPrefix(trimmed, "# "))) + "\n")
+		case strings.HasPrefix(trimmed, "## "):
+			b.WriteString(mdHeading2Style.Render(strings.TrimPrefix(trimmed, "## ")) + "\n")
+		case strings.HasPrefix(trimmed, "#"):
+			b.WriteString(mdHeading3Style.Render(strings.TrimLeft(trimmed, "# ")) + "\n")
+		case isHorizontalRule(trimmed):
+			b.WriteString(styles.MutedStyle.Render(strings.Repeat("─", ruleWidth(width))) + "\n")
+		case strings.HasPrefix(trimmed, ">"):
+			text := strings.TrimSpace(strings.TrimPrefix(trimmed, ">"))
+			b.WriteString(wrap(styles.MutedStyle.Render("│ ")+mdQuoteStyle.Render(text), width) + "\n")
+		case strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* ") || strings.HasPrefix(trimmed, "+ "):
+			indent := strings.Repeat(" ", leadingSpaces(line))
+			b.WriteString(wrap(indent+"  "+styles.SuccessStyle.Render(styles.IconBullet)+" "+renderInline(trimmed[2:]), width) + "\n")
+		case mdOrdered.MatchString(trimmed):
+			m := mdOrdered.FindStringSubmatch(trimmed)
+			indent := strings.Repeat(" ", leadingSpaces(line))
+			b.WriteString(wrap(indent+"  "+styles.SuccessStyle.Render(m[1]+".")+" "+renderInline(m[2]), width) + "\n")
+		case strings.HasPrefix(trimmed, "|"):
+			b.WriteString(styles.SkillDescStyle.Render(line) + "\n")
+		default:
+			b.WriteString(wrap(renderInline(line), width) + "\n")
+		}
+	}
+
+	return b.String()
+}
+
+// MarkdownLinks returns the relative link targets in src that point at
+// markdown files, in order of first appearance.
+func MarkdownLinks(src string) []string {
+	seen := make(map[string]bool)
+	var links []string
+	for _, m := range mdLink.FindAllStringSubmatch(src, -1) {
+		target := m[2]
+		if idx := strings.IndexAny(target, "#?"); idx != -1 {
+			target = target[:idx]
+		}
+		if target == "" || strings.Contains(target, "://") || strings.HasPrefix(target, "/") {
+			continue
+		}
+		if !strings.HasSuffix(strings.ToLower(target), ".md") {
+			continue
+		}
+		target = strings.TrimPrefix(target, "./")
+		if !seen[target] {
+			seen[target] = true
+			links = append(links, target)
+		}
+	}
+	return links
+}
+
+func renderInline(text string) string {
+	// Protect code spans from the other inline rules.
+	var spans []string
+	text = mdInlineCode.ReplaceAllStringFunc(text, func(s string) string {
+		spans = append(spans, mdCodeStyle.Render(strings.Trim(s, "`")))
+		return "\x00" + string(rune('A'+len(spans)-1)) + "\x00"
+	})
+
+	text = mdLink.ReplaceAllStringFunc(text, func(s string) string {
+		m := mdLink.FindStringSubmatch(s)
+		return lipgloss.NewStyle().Underline(true).Render(m[1]) + " " + styles.MutedStyle.Render("("+m[2]+")")
+	})
+	text = mdBold.ReplaceAllStringFunc(text, func(s string) string {
+		return mdBoldStyle.Render(s[2 : len(s)-2])
+	})
+	text = mdItalic.ReplaceAllStringFunc(text, func(s string) string {
+		m := mdItalic.FindStringSubmatch(s)
+		if m[2] != "" {
+			return m[1] + mdItalicStyle.Render(m[2])
+		}
+		return m[3] + mdItalicStyle.Render(m[4])
+	})
+
+	for i, span := range spans {
+		text = strings.Replace(text, "\x00"+string(rune('A'+i))+"\x00", span, 1)
+	}
+	return text
+}
+
+func wrap(text string, width int) string {
+	if width <= 0 {
+		return text
+	}
+	return lipgloss.NewStyle().Width(width).Render(text)
+}
+
+func isHorizontalRule(line string) bool {
+	if len(line) < 3 {
+		return false
+	}
+	for _, ch := range []string{"-", "*", "_"} {
+		if strings.Trim(strings.ReplaceAll(line, " ", ""), ch) == "" {
+			return true
+		}
+	}
+	return false
+}
+
+func ruleWidth(width int) int {
+	if width <= 0 || width > 60 {
+		return 60
+	}
+	return width
+}
+
+func leadingSpaces(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
@@ -0,0 +1,32 @@
+package ui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMarkdownLinksReturnsRelativeMarkdownTargets(t *testing.T) {
+	src := "See [reference](reference.md), [forms](./docs/forms.md#fill) and [site](https://example.com/a.md).\n" +
+		"Also [script](scripts/run.py) and [reference again](reference.md)."
+
+	got := MarkdownLinks(src)
+	want := []string{"reference.md", "docs/forms.md"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("links = %v, want %v", got, want)
+	}
+}
+
+func TestRenderMarkdownKeepsTextContent(t *testing.T) {
+	src := "# Title\n\nUse `pdftotext` with **care**.\n\n- first item\n1. step one\n\n```bash\necho hi\n```\n"
+
+	got := RenderMarkdown(src, 0)
+	for _, want := range []string{"TITLE", "pdftotext", "care", "first item", "step one", "echo hi", "bash"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("rendered output missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "```") || strings.Contains(got, "**") {
+		t.Fatalf("rendered output still contains markdown syntax:\n%s", got)
+	}
+}
@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/majiayu000/caude-skill-manager/pkg/styles"
+	"golang.org/x/term"
+)
+
+// IsTerminal reports whether stdout is an interactive terminal.
+func IsTerminal() bool {
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// CanPrompt reports whether both stdin and stdout are terminals, so the user
+// can answer a prompt.
+func CanPrompt() bool {
+	return IsTerminal() && term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// TerminalWidth returns the terminal width, or 0 when stdout is not a TTY.
+func TerminalWidth() int {
+	width, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// PagerModel shows long content in a scrollable viewport.
+type PagerModel struct {
+	title    string
+	content  string
+	viewport viewport.Model
+	ready    bool
+}
+
+// NewPager creates a pager for content with a title line.
+func NewPager(title, content string) PagerModel {
+	return PagerModel{title: title, content: content}
+}
+
+func (m PagerModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m PagerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		}
+
+	case tea.WindowSizeMsg:
+		height := msg.Height - 2 // title and footer lines
+		if !m.ready {
+			m.viewport = viewport.New(msg.Width, height)
+			m.viewport.SetContent(m.content)
+			m.ready = true
+		} else {
+			m.viewport.Width = msg.Width
+			m.viewport.Height = height
+		}
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+func (m PagerModel) View() string {
+	if !m.ready {
+		return ""
+	}
+	footer := styles.MutedStyle.Render(fmt.Sprintf("%3.f%%  ↑/↓ scroll • space/b page • q quit", m.viewport.ScrollPercent()*100))
+	return styles.SpinnerStyle.Render(m.title) + "\n" + m.viewport.View() + "\n" + footer
+}
+
+// Page prints content, opening a pager when stdout is a terminal and the
+// content is taller than the screen.
+func Page(title, content string) error {
+	fd := int(os.Stdout.Fd())
+	if !term.IsTerminal(fd) {
+		fmt.Print(content)
+		return nil
+	}
+	_, height, err := term.GetSize(fd)
+	if err != nil || strings.Count(content, "\n") < height-1 {
+		fmt.Print(content)
+		return nil
+	}
+
+	_, err = tea.NewProgram(NewPager(title, content), tea.WithAltScreen()).Run()
+	return err
+}
@@ -0,0 +1,263 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/majiayu000/caude-skill-manager/pkg/styles"
+	"golang.org/x/term"
+)
+
+// ProgressLine redraws a single status line in place. It writes nothing
+// unless w is a terminal, so piped output stays clean.
+type ProgressLine struct {
+	w       io.Writer
+	enabled bool
+	shown   bool
+}
+
+// NewProgressLine returns a progress line writing to w.
+func NewProgressLine(w io.Writer) *ProgressLine {
+	f, ok := w.(*os.File)
+	return &ProgressLine{w: w, enabled: ok && term.IsTerminal(int(f.Fd()))}
+}
+
+// Update replaces the line with message.
+func (p *ProgressLine) Update(message string) {
+	if !p.enabled {
+		return
+	}
+	fmt.Fprintf(p.w, "\r\033[K  %s %s", styles.SpinnerStyle.Render("⠋"), message)
+	p.shown = true
+}
+
+// Clear erases the line if anything was drawn.
+func (p *ProgressLine) Clear() {
+	if !p.enabled || !p.shown {
+		return
+	}
+	fmt.Fprint(p.w, "\r\033[K")
+	p.shown = false
+}
+
+const (
+	// progressRedraw limits how often a terminal line is redrawn.
+	progressRedraw = 100 * time.Millisecond
+	// progressLogInterval is how often a log line is written when the
+	// output is not a terminal.
+	progressLogInterval = 5 * time.Second
+)
+
+// Progress reports a transfer: bytes received, the total when known,
+// throughput and time remaining, and optionally a count of items such as
+// shards. While RunWithSpinner is showing a spinner the report follows its
+// message; otherwise it is redrawn on one line of a terminal, or written as
+// a log line every few seconds when w is not a terminal. Methods are safe
+// for concurrent use.
+type Progress struct {
+	mu     sync.Mutex
+	line   *ProgressLine
+	label  string
+	now    func() time.Time
+	start  time.Time
+	drawn  time.Time
+	logged bool
+
+	bytes, total int64
+	done, items  int
+	noun         string
+}
+
+// NewProgress returns a progress report labelled label writing to w.
+func NewProgress(w io.Writer, label string) *Progress {
+	p := &Progress{line: NewProgressLine(w), label: label, now: time.Now}
+	p.start = p.now()
+	return p
+}
+
+// SetBytes records n bytes transferred out of total, or of an unknown total
+// when total is zero.
+func (p *Progress) SetBytes(n, total int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.bytes, p.total = n, total
+	p.render()
+}
+
+// SetItems records done of total items, named noun (e.g. "shards").
+func (p *Progress) SetItems(done, total int, noun string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done, p.items, p.noun = done, total, noun
+	p.render()
+}
+
+// Finish removes the report. When earlier log lines were written it logs a
+// final line, so logs show the transfer completed.
+func (p *Progress) Finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	setSpinnerStatus("")
+	p.line.Clear()
+	if p.logged {
+		fmt.Fprintf(p.line.w, "  %s: %s\n", p.label, p.summary())
+	}
+}
+
+func (p *Progress) render() {
+	now := p.now()
+	interval := progressRedraw
+	if !p.line.enabled && !spinnerRunning() {
+		interval = progressLogInterval
+		if now.Sub(p.start) < interval {
+			return
+		}
+	}
+	if now.Sub(p.drawn) < interval {
+		return
+	}
+	p.drawn = now
+
+	switch {
+	case setSpinnerStatus(p.summary()):
+	case p.line.enabled:
+		p.line.Update(p.label + ": " + p.summary())
+	default:
+		fmt.Fprintf(p.line.w, "  %s: %s\n", p.label, p.summary())
+		p.logged = true
+	}
+}
+
+// summary renders the counts, throughput and time remaining.
+func (p *Progress) summary() string {
+	elapsed := p.now().Sub(p.start)
+	var parts []string
+	if p.items > 0 {
+		parts = append(parts, fmt.Sprintf("%d/%d %s", p.done, p.items, p.noun))
+	}
+	if p.total > 0 {
+		parts = append(parts, fmt.Sprintf("%s / %s", FormatBytes(p.bytes), FormatBytes(p.total)))
+	} else {
+		parts = append(parts, FormatBytes(p.bytes))
+	}
+	if elapsed < time.Second {
+		return strings.Join(parts, ", ")
+	}
+
+	rate := float64(p.bytes) / elapsed.Seconds()
+	parts = append(parts, FormatBytes(int64(rate))+"/s")
+	var remaining time.Duration
+	switch {
+	case p.total > 0 && rate > 0 && p.bytes < p.total:
+		remaining = time.Duration(float64(p.total-p.bytes) / rate * float64(time.Second))
+	case p.total == 0 && p.items > 0 && p.done > 0 && p.done < p.items:
+		remaining = elapsed * time.Duration(p.items-p.done) / time.Duration(p.done)
+	}
+	if remaining > 0 {
+		parts = append(parts, "ETA "+formatRemaining(remaining))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatRemaining renders d as 45s, 2m05s or 1h02m.
+func formatRemaining(d time.Duration) string {
+	d = d.Round(time.Second)
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm%02ds", int(d.Minutes()), int(d.Seconds())%60)
+	default:
+		return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+}
+
+// MultiProgress shows one status line per task, such as each skill of a
+// batch install. On a terminal the lines are redrawn in place. Otherwise each
+// status passed to Set is printed as a line, and Update, meant for frequent
+// detail such as byte counts, prints nothing. Methods are safe for
+// concurrent use.
+type MultiProgress struct {
+	mu      sync.Mutex
+	w       io.Writer
+	enabled bool
+	width   int
+	labels  []string
+	status  []string
+	drawn   int
+	last    time.Time
+}
+
+// NewMultiProgress returns a view with one line per label writing to w.
+func NewMultiProgress(w io.Writer, labels []string) *MultiProgress {
+	m := &MultiProgress{w: w, labels: labels, status: make([]string, len(labels))}
+	if f, ok := w.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		m.enabled = true
+		if width, _, err := term.GetSize(int(f.Fd())); err == nil {
+			m.width = width
+		}
+	}
+	return m
+}
+
+// Set replaces the status of line i and redraws.
+func (m *MultiProgress) Set(i int, status string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.status[i] == status {
+		return
+	}
+	m.status[i] = status
+	if !m.enabled {
+		fmt.Fprintf(m.w, "  %s: %s\n", m.labels[i], status)
+		return
+	}
+	m.draw()
+}
+
+// Update replaces the status of line i, redrawing at most every 100ms.
+func (m *MultiProgress) Update(i int, status string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.enabled {
+		return
+	}
+	m.status[i] = status
+	if time.Since(m.last) >= progressRedraw {
+		m.draw()
+	}
+}
+
+// Stop draws the final statuses. Later output appears below the view.
+func (m *MultiProgress) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.enabled {
+		m.draw()
+	}
+}
+
+func (m *MultiProgress) draw() {
+	labelWidth := 0
+	for _, label := range m.labels {
+		labelWidth = max(labelWidth, lipgloss.Width(label))
+	}
+	if m.drawn > 0 {
+		fmt.Fprintf(m.w, "\033[%dA", m.drawn)
+	}
+	line := lipgloss.NewStyle()
+	if m.width > 0 {
+		line = line.MaxWidth(m.width - 1)
+	}
+	for i, label := range m.labels {
+		text := "  " + label + strings.Repeat(" ", labelWidth-lipgloss.Width(label)) + "  " + m.status[i]
+		fmt.Fprintf(m.w, "\r\033[K%s\n", line.Render(text))
+	}
+	m.drawn = len(m.labels)
+	m.last = time.Now()
+}
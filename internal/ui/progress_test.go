@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testProgress returns a progress report on a buffer, which is not a
+// terminal, with a clock the test advances.
+func testProgress() (*Progress, *bytes.Buffer, *time.Time) {
+	var buf bytes.Buffer
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := NewProgress(&buf, "Downloading owner/repo")
+	p.now = func() time.Time { return clock }
+	p.start = clock
+	return p, &buf, &clock
+}
+
+func TestProgressSummary(t *testing.T) {
+	p, _, clock := testProgress()
+	p.SetBytes(512<<10, 0)
+	if got := p.summary(); got != "512.0 KB" {
+		t.Fatalf("unexpected summary before a second has passed: %q", got)
+	}
+
+	*clock = clock.Add(4 * time.Second)
+	p.SetBytes(4<<20, 10<<20)
+	if got := p.summary(); got != "4.0 MB / 10.0 MB, 1.0 MB/s, ETA 6s" {
+		t.Fatalf("unexpected summary: %q", got)
+	}
+
+	p.SetBytes(4<<20, 0)
+	p.SetItems(2, 62, "shards")
+	if got := p.summary(); got != "2/62 shards, 4.0 MB, 1.0 MB/s, ETA 2m00s" {
+		t.Fatalf("unexpected shard summary: %q", got)
+	}
+}
+
+func TestProgressLogsPeriodicallyWhenNotATerminal(t *testing.T) {
+	p, buf, clock := testProgress()
+	for i := 1; i <= 12; i++ {
+		*clock = clock.Add(time.Second)
+		p.SetBytes(int64(i)<<20, 12<<20)
+	}
+	p.Finish()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected log lines at 5s and 10s and a final line, got %q", buf.String())
+	}
+	if !strings.Contains(lines[0], "Downloading owner/repo: 5.0 MB / 12.0 MB") || !strings.Contains(lines[2], "12.0 MB / 12.0 MB") {
+		t.Fatalf("unexpected log lines %q", lines)
+	}
+}
+
+func TestProgressFinishIsQuietForShortTransfers(t *testing.T) {
+	p, buf, clock := testProgress()
+	*clock = clock.Add(2 * time.Second)
+	p.SetBytes(1<<20, 1<<20)
+	p.Finish()
+	if buf.Len() != 0 {
+		t.Fatalf("expected no output, got %q", buf.String())
+	}
+}
+
+func TestFormatRemaining(t *testing.T) {
+	for d, want := range map[time.Duration]string{
+		45 * time.Second:              "45s",
+		125 * time.Second:             "2m05s",
+		time.Hour + 2*time.Minute + 9: "1h02m",
+	} {
+		if got := formatRemaining(d); got != want {
+			t.Fatalf("formatRemaining(%s) = %q, want %q", d, got, want)
+		}
+	}
+}
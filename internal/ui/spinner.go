@@ -3,6 +3,7 @@ package ui
 import (
 	"fmt"
 	"os"
+	"sync"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
@@ -15,6 +16,7 @@ import (
 type SpinnerModel struct {
 	spinner  spinner.Model
 	message  string
+	status   string // detail shown after the message, such as progress
 	done     bool
 	err      error
 	result   string
@@ -51,6 +53,10 @@ func (m SpinnerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.spinner, cmd = m.spinner.Update(msg)
 		return m, cmd
 
+	case statusMsg:
+		m.status = string(msg)
+		return m, nil
+
 	case DoneMsg:
 		m.done = true
 		m.result = msg.Result
@@ -73,16 +79,46 @@ func (m SpinnerModel) View() string {
 		return m.result + "\n"
 	}
 
+	if m.status != "" {
+		return fmt.Sprintf("%s %s %s\n", m.spinner.View(), m.message, styles.MutedStyle.Render(m.status))
+	}
 	return fmt.Sprintf("%s %s\n", m.spinner.View(), m.message)
 }
 
+// statusMsg replaces the spinner's status detail.
+type statusMsg string
+
+var (
+	activeSpinnerMu sync.Mutex
+	activeSpinner   *tea.Program
+)
+
+func spinnerRunning() bool {
+	activeSpinnerMu.Lock()
+	defer activeSpinnerMu.Unlock()
+	return activeSpinner != nil
+}
+
+// setSpinnerStatus shows status after the message of the running spinner,
+// reporting false when no spinner is running.
+func setSpinnerStatus(status string) bool {
+	activeSpinnerMu.Lock()
+	defer activeSpinnerMu.Unlock()
+	if activeSpinner == nil {
+		return false
+	}
+	activeSpinner.Send(statusMsg(status))
+	return true
+}
+
 // DoneMsg signals the spinner to stop
 type DoneMsg struct {
 	Result string
 	Err    error
 }
 
-// RunWithSpinner runs a function with a spinner
+// RunWithSpinner runs a function with a spinner. Any error from fn is
+// printed before it is returned.
 func RunWithSpinner(message string, fn func() (string, error)) error {
 	// Check if we're in a TTY
 	if !term.IsTerminal(int(os.Stdout.Fd())) {
@@ -99,12 +135,32 @@ func RunWithSpinner(message string, fn func() (string, error)) error {
 
 	m := NewSpinner(message)
 	p := tea.NewProgram(m)
+	activeSpinnerMu.Lock()
+	activeSpinner = p
+	activeSpinnerMu.Unlock()
+	defer func() {
+		activeSpinnerMu.Lock()
+		activeSpinner = nil
+		activeSpinnerMu.Unlock()
+	}()
 
 	go func() {
 		result, err := fn()
 		p.Send(DoneMsg{Result: result, Err: err})
 	}()
 
-	_, err := p.Run()
-	return err
+	final, err := p.Run()
+	if err != nil {
+		return err
+	}
+	// The view has already rendered the error; return it so callers can stop.
+	if m, ok := final.(SpinnerModel); ok {
+		if m.quitting {
+			return fmt.Errorf("cancelled")
+		}
+		if m.err != nil {
+			return m.err
+		}
+	}
+	return nil
 }